@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"my_lambda_app/auth"
+	"my_lambda_app/store"
+)
+
+const (
+	sessionCookie    = "sumtube_session"
+	oauthStateCookie = "sumtube_oauth_state"
+	returnToCookie   = "sumtube_return_to"
+	sessionTTL       = 30 * 24 * time.Hour
+)
+
+var googleUserInfoURL = "https://openidconnect.googleapis.com/v1/userinfo"
+
+var sessionSigner = auth.NewSigner(sessionSecret())
+
+type GoogleUser struct {
+	Sub     string `json:"sub"`
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+}
+
+// sessionSecret reads SESSION_SECRET. Without it sessions are signed with a random key
+// and do not survive a restart.
+func sessionSecret() []byte {
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		return []byte(secret)
+	}
+	log.Println("⚠️ SESSION_SECRET is not set, sessions will be lost on restart")
+	token, err := auth.RandomToken()
+	if err != nil {
+		log.Fatalf("unable to generate a session secret, %v\n", err)
+	}
+	return []byte(token)
+}
+
+// corsAllowedOrigins reads the comma separated CORS_ALLOWED_ORIGINS. Browsers only send the
+// session cookie cross-origin to explicitly allowed origins, "*" is kept as the default.
+func corsAllowedOrigins() []string {
+	origins := []string{}
+	for _, origin := range strings.Split(os.Getenv("CORS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return []string{"*"}
+	}
+	return origins
+}
+
+// fetchGoogleUser exchanges the authorization code and reads the profile of the user.
+// It is swapped in tests.
+var fetchGoogleUser = func(ctx context.Context, code string) (*GoogleUser, error) {
+	token, err := googleOAuthConfig.Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange token: %w", err)
+	}
+	resp, err := googleOAuthConfig.Client(ctx, token).Get(googleUserInfoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user info: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user info returned status %d", resp.StatusCode)
+	}
+
+	var user GoogleUser
+	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
+		return nil, fmt.Errorf("failed to parse user info: %w", err)
+	}
+	if user.Sub == "" {
+		return nil, fmt.Errorf("user info without subject")
+	}
+	return &user, nil
+}
+
+func setCookie(w http.ResponseWriter, r *http.Request, name string, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   os.Getenv("SESSION_COOKIE_DOMAIN"), // e.g. ".sumtube.io" to share it with the renderer
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func clearCookie(w http.ResponseWriter, r *http.Request, name string) {
+	setCookie(w, r, name, "", -time.Second)
+}
+
+// safeReturnTo only accepts paths on this site or URLs under BASE_URL, so the login
+// cannot be used as an open redirect.
+func safeReturnTo(returnTo string) string {
+	baseURL := os.Getenv("BASE_URL")
+	if strings.HasPrefix(returnTo, "/") && !strings.HasPrefix(returnTo, "//") && !strings.HasPrefix(returnTo, "/\\") {
+		return returnTo
+	}
+	if baseURL != "" && (returnTo == baseURL || strings.HasPrefix(returnTo, strings.TrimSuffix(baseURL, "/")+"/")) {
+		return returnTo
+	}
+	if baseURL != "" {
+		return baseURL
+	}
+	return "/"
+}
+
+// currentUser returns the signed in user of a request, or nil.
+func currentUser(r *http.Request) (*store.User, error) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, nil
+	}
+	session, err := sessionSigner.Decode(cookie.Value)
+	if err != nil {
+		return nil, nil
+	}
+	return dataStore.GetUser(session.UserID)
+}
+
+// handleGoogleLogin starts the Google OAuth2 flow with a random state kept in a cookie.
+// GET /login?return_to=/en/...
+func handleGoogleLogin(w http.ResponseWriter, r *http.Request) {
+	state, err := auth.RandomToken()
+	if err != nil {
+		http.Error(w, "Failed to start login", http.StatusInternalServerError)
+		return
+	}
+	setCookie(w, r, oauthStateCookie, state, 10*time.Minute)
+	setCookie(w, r, returnToCookie, safeReturnTo(r.URL.Query().Get("return_to")), 10*time.Minute)
+
+	http.Redirect(w, r, googleOAuthConfig.AuthCodeURL(state), http.StatusTemporaryRedirect)
+}
+
+// handleGoogleRedirect completes the OAuth2 flow: it checks the state, creates or updates
+// the account and opens a session.
+// GET /redirects?code=...&state=...
+func handleGoogleRedirect(w http.ResponseWriter, r *http.Request) {
+	stateCookie, err := r.Cookie(oauthStateCookie)
+	state := r.URL.Query().Get("state")
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(stateCookie.Value)) != 1 {
+		http.Error(w, "Invalid OAuth state", http.StatusBadRequest)
+		return
+	}
+	clearCookie(w, r, oauthStateCookie)
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		http.Error(w, "Authorization code not found", http.StatusBadRequest)
+		return
+	}
+
+	googleUser, err := fetchGoogleUser(r.Context(), code)
+	if err != nil {
+		log.Printf("❌ Google sign-in failed: %v", err)
+		http.Error(w, "Failed to sign in with Google", http.StatusBadGateway)
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	user, err := dataStore.GetUser(googleUser.Sub)
+	if err != nil {
+		log.Printf("❌ Failed to load user %s: %v", googleUser.Sub, err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		user = &store.User{ID: googleUser.Sub, Name: googleUser.Name, CreatedAt: now}
+		log.Printf("👤 New user %s", googleUser.Sub)
+	}
+	user.Email = googleUser.Email
+	user.Picture = googleUser.Picture
+	user.LastLoginAt = now
+	if err := dataStore.PutUser(*user); err != nil {
+		log.Printf("❌ Failed to save user %s: %v", user.ID, err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+
+	value, err := sessionSigner.Encode(user.ID, sessionTTL)
+	if err != nil {
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+	setCookie(w, r, sessionCookie, value, sessionTTL)
+
+	returnTo := "/"
+	if cookie, err := r.Cookie(returnToCookie); err == nil {
+		returnTo = safeReturnTo(cookie.Value)
+	}
+	clearCookie(w, r, returnToCookie)
+	http.Redirect(w, r, returnTo, http.StatusFound)
+}
+
+// handleLogout closes the session.
+// GET|POST /logout?return_to=/en
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+	clearCookie(w, r, sessionCookie)
+	if r.Method == http.MethodGet {
+		http.Redirect(w, r, safeReturnTo(r.URL.Query().Get("return_to")), http.StatusFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetMe returns the account of the signed in user.
+// GET /me
+func handleGetMe(w http.ResponseWriter, r *http.Request) {
+	user, err := currentUser(r)
+	if err != nil {
+		log.Printf("❌ Failed to load the current user: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "Not signed in", http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// handleUpdateMe changes the display name or the preferred language of the signed in user.
+// PATCH /me {"name": "...", "language": "pt"}
+func handleUpdateMe(w http.ResponseWriter, r *http.Request) {
+	user, err := currentUser(r)
+	if err != nil {
+		log.Printf("❌ Failed to load the current user: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if user == nil {
+		http.Error(w, "Not signed in", http.StatusUnauthorized)
+		return
+	}
+
+	var requestBody struct {
+		Name     *string `json:"name"`
+		Language *string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if requestBody.Name != nil {
+		name := strings.TrimSpace(*requestBody.Name)
+		if name == "" || len(name) > 100 {
+			http.Error(w, "Invalid name", http.StatusBadRequest)
+			return
+		}
+		user.Name = name
+	}
+	if requestBody.Language != nil {
+		if len(*requestBody.Language) > 10 {
+			http.Error(w, "Invalid language", http.StatusBadRequest)
+			return
+		}
+		user.Language = *requestBody.Language
+	}
+	if err := dataStore.PutUser(*user); err != nil {
+		log.Printf("❌ Failed to save user %s: %v", user.ID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"my_lambda_app/store"
+)
+
+func cookieFrom(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestGoogleLogin_CreatesAccountAndSession(t *testing.T) {
+	previous := fetchGoogleUser
+	fetchGoogleUser = func(ctx context.Context, code string) (*GoogleUser, error) {
+		if code != "good-code" {
+			t.Errorf("Unexpected code %q", code)
+		}
+		return &GoogleUser{Sub: "google-42", Email: "ana@example.com", Name: "Ana"}, nil
+	}
+	defer func() { fetchGoogleUser = previous }()
+	router := newRouter()
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/login?return_to=/pt/search", nil))
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("Expected a redirect to Google, got %d", rec.Code)
+	}
+	state := cookieFrom(rec, oauthStateCookie)
+	returnTo := cookieFrom(rec, returnToCookie)
+	location, _ := url.Parse(rec.Header().Get("Location"))
+	if state == nil || returnTo == nil || location.Query().Get("state") != state.Value {
+		t.Fatalf("Expected the state cookie to match the redirect, got %v and %q", state, location)
+	}
+
+	// A forged state is rejected
+	req := httptest.NewRequest("GET", "/redirects?code=good-code&state=forged", nil)
+	req.AddCookie(state)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for a forged state, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/redirects?code=good-code&state="+url.QueryEscape(state.Value), nil)
+	req.AddCookie(state)
+	req.AddCookie(returnTo)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/pt/search" {
+		t.Fatalf("Expected a redirect back to /pt/search, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	session := cookieFrom(rec, sessionCookie)
+	if session == nil || !session.HttpOnly {
+		t.Fatalf("Expected an HttpOnly session cookie, got %v", session)
+	}
+
+	user, err := dataStore.GetUser("google-42")
+	if err != nil || user == nil || user.Email != "ana@example.com" || user.CreatedAt == "" {
+		t.Fatalf("Expected the account to be created, got %+v, %v", user, err)
+	}
+
+	req = httptest.NewRequest("GET", "/me", nil)
+	req.AddCookie(session)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var me store.User
+	json.NewDecoder(rec.Body).Decode(&me)
+	if rec.Code != http.StatusOK || me.ID != "google-42" {
+		t.Fatalf("Expected /me to return the user, got %d %+v", rec.Code, me)
+	}
+
+	req = httptest.NewRequest("PATCH", "/me", strings.NewReader(`{"name": "Ana Maria", "language": "pt"}`))
+	req.AddCookie(session)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected PATCH /me to succeed, got %d", rec.Code)
+	}
+	if user, _ := dataStore.GetUser("google-42"); user.Name != "Ana Maria" || user.Language != "pt" {
+		t.Errorf("Expected the account to be updated, got %+v", user)
+	}
+}
+
+func TestGetMe_RequiresValidSession(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/me", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a session, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest("GET", "/me", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: "forged.value"})
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a forged session, got %d", rec.Code)
+	}
+}
+
+func TestLogout_ClearsSession(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/logout?return_to=https://evil.example.com", nil))
+	if cookie := cookieFrom(rec, sessionCookie); cookie == nil || cookie.MaxAge >= 0 {
+		t.Errorf("Expected the session cookie to be cleared, got %v", cookie)
+	}
+	if location := rec.Header().Get("Location"); location != "/" {
+		t.Errorf("Expected an external return_to to be ignored, got %q", location)
+	}
+}
+
+func TestSafeReturnTo(t *testing.T) {
+	t.Setenv("BASE_URL", "https://sumtube.io")
+	for input, want := range map[string]string{
+		"/en/search":                "/en/search",
+		"//evil.com":                "https://sumtube.io",
+		"https://sumtube.io/pt":     "https://sumtube.io/pt",
+		"https://sumtube.io.evil":   "https://sumtube.io",
+		"https://evil.com/phishing": "https://sumtube.io",
+		"":                          "https://sumtube.io",
+	} {
+		if got := safeReturnTo(input); got != want {
+			t.Errorf("safeReturnTo(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"my_lambda_app/apikeys"
+	"my_lambda_app/ratelimit"
+	"my_lambda_app/webhooks"
+)
+
+const apiKeyHeader = "X-API-Key"
+const apiKeysObject = "apikeys.json"
+
+// apiKeys is loaded in main() so tests can point it at their own store.
+var apiKeys *apikeys.Manager
+
+var requestLimiter = ratelimit.NewLimiter()
+
+// Anonymous clients share a bucket per IP, API keys get their own (overridable per key).
+var ipRateLimit = envRateLimit("RATE_LIMIT_IP", ratelimit.Limit{Rate: 1, Burst: 30})
+var keyRateLimit = envRateLimit("RATE_LIMIT_KEY", ratelimit.Limit{Rate: 5, Burst: 100})
+
+type apiKeyContextKey struct{}
+
+type APIKeyResponse struct {
+	apikeys.Key
+	// Hash shadows the hash of the secret, which never leaves the store
+	Hash   string        `json:"hash,omitempty"`
+	Usage  apikeys.Usage `json:"usage"`
+	Secret string        `json:"secret,omitempty"`
+}
+
+// envRateLimit reads {prefix}_RPS and {prefix}_BURST, keeping the defaults for missing values.
+func envRateLimit(prefix string, limit ratelimit.Limit) ratelimit.Limit {
+	if rate, err := strconv.ParseFloat(os.Getenv(prefix+"_RPS"), 64); err == nil && rate > 0 {
+		limit.Rate = rate
+	}
+	if burst, err := strconv.Atoi(os.Getenv(prefix + "_BURST")); err == nil && burst > 0 {
+		limit.Burst = burst
+	}
+	return limit
+}
+
+// trustedProxies are the addresses allowed to tell the client IP in X-Real-IP and
+// X-Forwarded-For, the nginx in front of the API. Nobody is trusted by default.
+var trustedProxies = parseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+
+// parseTrustedProxies reads a comma separated list of IPs and CIDRs, e.g. "172.16.0.0/12,10.0.0.5".
+func parseTrustedProxies(value string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(entry); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			log.Printf("⚠️ Ignoring invalid TRUSTED_PROXIES entry %q", entry)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+func isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP is the address of the connection, or the one the trusted proxy it came from
+// forwarded. Headers sent by anyone else are ignored so they cannot pick their bucket.
+func clientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if !isTrustedProxy(remote) {
+		return remote
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		return ip
+	}
+	// the proxies append to X-Forwarded-For, the client is the last hop that is not one of them
+	hops := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		if hop := strings.TrimSpace(hops[i]); hop != "" && !isTrustedProxy(hop) {
+			return hop
+		}
+	}
+	return remote
+}
+
+// apiKeyFromContext returns the key of a request authenticated by withAPIAccess.
+func apiKeyFromContext(ctx context.Context) (apikeys.Key, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(apikeys.Key)
+	return key, ok
+}
+
+// withAPIAccess authenticates the X-API-Key header when present and applies the
+// token bucket of the key, or of the client IP for anonymous requests.
+func withAPIAccess(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bucket := "ip:" + clientIP(r)
+		limit := ipRateLimit
+		var key apikeys.Key
+		authenticated := false
+
+		if secret := r.Header.Get(apiKeyHeader); secret != "" {
+			var err error
+			key, err = apiKeys.Lookup(secret)
+			if err != nil {
+				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				return
+			}
+			authenticated = true
+			bucket = "key:" + key.ID
+			limit = keyRateLimit
+			if key.Rate > 0 {
+				limit.Rate = key.Rate
+			}
+			if key.Burst > 0 {
+				limit.Burst = key.Burst
+			}
+		}
+
+		allowed, remaining, retryAfter := requestLimiter.Allow(bucket, limit)
+		if authenticated {
+			apiKeys.RecordUsage(key.ID, !allowed)
+		}
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit.Burst))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		if authenticated {
+			r = r.WithContext(context.WithValue(r.Context(), apiKeyContextKey{}, key))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAdmin guards the key management endpoints with the API_ADMIN_TOKEN bearer token.
+// They are disabled when the token is not set.
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		adminToken := os.Getenv("API_ADMIN_TOKEN")
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleCreateAPIKey issues a key. The secret is only part of this response.
+// POST /apikeys {"name": "partner", "rate": 2, "burst": 50, "callback_url": "https://..."}
+func handleCreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var requestBody struct {
+		Name        string  `json:"name"`
+		Rate        float64 `json:"rate"`
+		Burst       int     `json:"burst"`
+		CallbackURL string  `json:"callback_url"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if requestBody.Name == "" || requestBody.Rate < 0 || requestBody.Burst < 0 {
+		http.Error(w, "Missing 'name' or invalid limits", http.StatusBadRequest)
+		return
+	}
+	if requestBody.CallbackURL != "" {
+		if !summaryWebhooks.Enabled() {
+			http.Error(w, "Webhooks are disabled", http.StatusServiceUnavailable)
+			return
+		}
+		if err := webhooks.ValidateURL(requestBody.CallbackURL); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	key, secret, err := apiKeys.Issue(apikeys.Key{
+		Name:        requestBody.Name,
+		Rate:        requestBody.Rate,
+		Burst:       requestBody.Burst,
+		CallbackURL: requestBody.CallbackURL,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to issue API key: %v", err)
+		http.Error(w, "Failed to issue API key", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("🔑 Issued API key %s (%s)", key.ID, key.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(APIKeyResponse{Key: key, Secret: secret})
+}
+
+// handleListAPIKeys lists the keys with their usage counters.
+// GET /apikeys
+func handleListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys := apiKeys.List()
+	response := make([]APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		response = append(response, APIKeyResponse{Key: key, Usage: apiKeys.Usage(key.ID)})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleRevokeAPIKey revokes a key.
+// DELETE /apikeys/{keyId}
+func handleRevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	err := apiKeys.Revoke(r.PathValue("keyId"))
+	if errors.Is(err, apikeys.ErrUnknownKey) {
+		http.Error(w, "API key not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to revoke API key: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to revoke API key: %v", err), http.StatusInternalServerError)
+		return
+	}
+	log.Printf("🔒 Revoked API key %s", r.PathValue("keyId"))
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"my_lambda_app/ratelimit"
+)
+
+func TestWithAPIAccess_RateLimitsByIP(t *testing.T) {
+	requestLimiter = ratelimit.NewLimiter()
+	previous := ipRateLimit
+	previousProxies := trustedProxies
+	ipRateLimit = ratelimit.Limit{Rate: 0.5, Burst: 2}
+	// httptest requests come from 192.0.2.1, the nginx of these tests
+	trustedProxies = parseTrustedProxies("192.0.2.1")
+	defer func() { ipRateLimit, trustedProxies = previous, previousProxies }()
+
+	handler := withAPIAccess(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/summary", nil)
+		req.Header.Set("X-Real-IP", ip)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request("10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("Expected request %d to pass, got %d", i, rec.Code)
+		}
+	}
+	rec := request("10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected Retry-After: 2, got %q", rec.Header().Get("Retry-After"))
+	}
+	if rec := request("10.0.0.2"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another IP to have its own bucket, got %d", rec.Code)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	previous := trustedProxies
+	trustedProxies = parseTrustedProxies("172.16.0.0/12, 10.0.0.5")
+	defer func() { trustedProxies = previous }()
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		forwarded  string
+		want       string
+	}{
+		{"no headers", "203.0.113.7:4321", "", "", "203.0.113.7"},
+		{"spoofed X-Real-IP from a client", "203.0.113.7:4321", "198.51.100.1", "", "203.0.113.7"},
+		{"spoofed X-Forwarded-For from a client", "203.0.113.7:4321", "", "198.51.100.1", "203.0.113.7"},
+		{"X-Real-IP from the proxy", "172.18.0.3:4321", "198.51.100.1", "", "198.51.100.1"},
+		{"single proxy IP", "10.0.0.5:4321", "198.51.100.1", "", "198.51.100.1"},
+		{"X-Forwarded-For skips the hops the client wrote", "172.18.0.3:4321", "", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"X-Forwarded-For skips trusted hops", "172.18.0.3:4321", "", "198.51.100.1, 10.0.0.5", "198.51.100.1"},
+		{"proxy without headers", "172.18.0.3:4321", "", "", "172.18.0.3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/summary", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := clientIP(req); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAPIAccess_IgnoresSpoofedIPs(t *testing.T) {
+	requestLimiter = ratelimit.NewLimiter()
+	previous, previousProxies := ipRateLimit, trustedProxies
+	ipRateLimit = ratelimit.Limit{Rate: 0.5, Burst: 2}
+	trustedProxies = nil
+	defer func() { ipRateLimit, trustedProxies = previous, previousProxies }()
+
+	handler := withAPIAccess(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	var codes []int
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		req := httptest.NewRequest("POST", "/summary", nil)
+		req.Header.Set("X-Real-IP", ip)
+		req.Header.Set("X-Forwarded-For", ip)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	if codes[2] != http.StatusTooManyRequests {
+		t.Errorf("Expected a new forged IP not to get a new bucket, got %v", codes)
+	}
+}
+
+func TestAPIKeys_IssueUseRevoke(t *testing.T) {
+	t.Setenv("API_ADMIN_TOKEN", "admin-token")
+	requestLimiter = ratelimit.NewLimiter()
+	router := newRouter()
+
+	admin := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer admin-token")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/apikeys", strings.NewReader(`{"name": "partner"}`)))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403 without the admin token, got %d", rec.Code)
+	}
+
+	rec = admin("POST", "/apikeys", `{"name": "partner", "rate": 1, "burst": 1}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var issued APIKeyResponse
+	json.NewDecoder(rec.Body).Decode(&issued)
+	if issued.Secret == "" || issued.Prefix == "" {
+		t.Fatalf("Unexpected key %+v", issued)
+	}
+
+	handler := withAPIAccess(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key, ok := apiKeyFromContext(r.Context()); !ok || key.ID != issued.ID {
+			t.Errorf("Expected the key in the request context")
+		}
+	}))
+	request := func(secret string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/summary", nil)
+		req.Header.Set(apiKeyHeader, secret)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request(issued.Secret); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the key to be accepted, got %d", rec.Code)
+	}
+	if rec := request(issued.Secret); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected the per key burst to apply, got %d", rec.Code)
+	}
+	if rec := request("sk_unknown"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown key, got %d", rec.Code)
+	}
+
+	body := admin("GET", "/apikeys", "").Body.String()
+	if strings.Contains(body, `"hash"`) {
+		t.Errorf("The hash of the secrets must not be listed: %s", body)
+	}
+	var listed []APIKeyResponse
+	json.Unmarshal([]byte(body), &listed)
+	found := false
+	for _, key := range listed {
+		if key.ID == issued.ID {
+			found = true
+			if key.Secret != "" || key.Usage.Requests != 2 || key.Usage.RateLimited != 1 {
+				t.Errorf("Unexpected listed key %+v", key)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected the key to be listed")
+	}
+
+	if rec := admin("DELETE", "/apikeys/"+issued.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	if rec := request(issued.Secret); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a revoked key, got %d", rec.Code)
+	}
+	if rec := admin("DELETE", "/apikeys/missing", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
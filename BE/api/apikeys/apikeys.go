@@ -0,0 +1,225 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const keyPrefix = "sk_"
+
+var (
+	ErrUnknownKey = errors.New("unknown API key")
+	ErrRevokedKey = errors.New("revoked API key")
+)
+
+// ObjectStore is the part of store.Store used to persist the keys. GetObject returns
+// an error matching fs.ErrNotExist when the object does not exist.
+type ObjectStore interface {
+	PutObject(key string, content string) error
+	GetObject(key string) (string, error)
+}
+
+// Key is an issued API key. Only the SHA-256 hash of the secret is kept, the secret
+// itself is returned once by Issue.
+type Key struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Hash        string    `json:"hash"`
+	Prefix      string    `json:"prefix"`
+	CreatedAt   time.Time `json:"created_at"`
+	RevokedAt   time.Time `json:"revoked_at,omitzero"`
+	Rate        float64   `json:"rate,omitempty"`         // requests per second, 0 for the default
+	Burst       int       `json:"burst,omitempty"`        // 0 for the default
+	CallbackURL string    `json:"callback_url,omitempty"` // default webhook of the summaries requested with this key
+}
+
+func (k Key) Revoked() bool {
+	return !k.RevokedAt.IsZero()
+}
+
+// Usage counts the requests made with a key since the process started.
+type Usage struct {
+	Requests    int64     `json:"requests"`
+	RateLimited int64     `json:"rate_limited"`
+	LastUsedAt  time.Time `json:"last_used_at,omitzero"`
+}
+
+// Manager issues, looks up and revokes API keys, persisting them as one object of the store.
+type Manager struct {
+	store     ObjectStore
+	objectKey string
+
+	mu     sync.Mutex
+	keys   map[string]*Key // by ID
+	byHash map[string]*Key
+	usage  map[string]*Usage
+}
+
+func NewManager(store ObjectStore, objectKey string) (*Manager, error) {
+	m := &Manager{
+		store:     store,
+		objectKey: objectKey,
+		keys:      make(map[string]*Key),
+		byHash:    make(map[string]*Key),
+		usage:     make(map[string]*Usage),
+	}
+
+	content, err := store.GetObject(objectKey)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && content == "") {
+		// nothing issued yet
+		return m, nil
+	}
+	if err != nil {
+		// starting empty would drop every key on the next Issue
+		return nil, fmt.Errorf("failed to load API keys: %w", err)
+	}
+	var keys []*Key
+	if err := json.Unmarshal([]byte(content), &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys: %w", err)
+	}
+	for _, k := range keys {
+		m.keys[k.ID] = k
+		m.byHash[k.Hash] = k
+	}
+	return m, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// save must be called with m.mu held.
+func (m *Manager) save() error {
+	keys := make([]*Key, 0, len(m.keys))
+	for _, k := range m.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	data, err := json.Marshal(keys)
+	if err != nil {
+		return fmt.Errorf("failed to encode API keys: %w", err)
+	}
+	return m.store.PutObject(m.objectKey, string(data))
+}
+
+// Issue creates a key and returns it along with its secret, which cannot be recovered later.
+func (m *Manager) Issue(template Key) (Key, string, error) {
+	id, err := randomHex(6)
+	if err != nil {
+		return Key{}, "", err
+	}
+	random, err := randomHex(24)
+	if err != nil {
+		return Key{}, "", err
+	}
+	secret := keyPrefix + random
+
+	k := template
+	k.ID = id
+	k.Hash = hashSecret(secret)
+	k.Prefix = secret[:len(keyPrefix)+6]
+	k.CreatedAt = time.Now().UTC()
+	k.RevokedAt = time.Time{}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[k.ID] = &k
+	m.byHash[k.Hash] = &k
+	if err := m.save(); err != nil {
+		delete(m.keys, k.ID)
+		delete(m.byHash, k.Hash)
+		return Key{}, "", err
+	}
+	return k, secret, nil
+}
+
+// Lookup finds the key of a secret, failing for unknown and revoked keys.
+func (m *Manager) Lookup(secret string) (Key, error) {
+	if !strings.HasPrefix(secret, keyPrefix) {
+		return Key{}, ErrUnknownKey
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.byHash[hashSecret(secret)]
+	if !ok {
+		return Key{}, ErrUnknownKey
+	}
+	if k.Revoked() {
+		return Key{}, ErrRevokedKey
+	}
+	return *k, nil
+}
+
+// Revoke disables a key, it stays listed with its revocation date.
+func (m *Manager) Revoke(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	k, ok := m.keys[id]
+	if !ok {
+		return ErrUnknownKey
+	}
+	if k.Revoked() {
+		return nil
+	}
+	k.RevokedAt = time.Now().UTC()
+	if err := m.save(); err != nil {
+		k.RevokedAt = time.Time{}
+		return err
+	}
+	return nil
+}
+
+// List returns every key, oldest first.
+func (m *Manager) List() []Key {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]Key, 0, len(m.keys))
+	for _, k := range m.keys {
+		keys = append(keys, *k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return keys
+}
+
+// RecordUsage counts a request made with the key id.
+func (m *Manager) RecordUsage(id string, rateLimited bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	u, ok := m.usage[id]
+	if !ok {
+		u = &Usage{}
+		m.usage[id] = u
+	}
+	u.Requests++
+	if rateLimited {
+		u.RateLimited++
+	}
+	u.LastUsedAt = time.Now().UTC()
+}
+
+func (m *Manager) Usage(id string) Usage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if u, ok := m.usage[id]; ok {
+		return *u
+	}
+	return Usage{}
+}
@@ -0,0 +1,102 @@
+package apikeys
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+type memoryStore map[string]string
+
+func (s memoryStore) PutObject(key string, content string) error {
+	s[key] = content
+	return nil
+}
+
+func (s memoryStore) GetObject(key string) (string, error) {
+	content, ok := s[key]
+	if !ok {
+		return "", fmt.Errorf("object %s: %w", key, fs.ErrNotExist)
+	}
+	return content, nil
+}
+
+func TestManager_IssueLookupRevoke(t *testing.T) {
+	objects := memoryStore{}
+	m, err := NewManager(objects, "apikeys.json")
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	key, secret, err := m.Issue(Key{Name: "renderer", Rate: 10, Burst: 100})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	if !strings.HasPrefix(secret, "sk_") || !strings.HasPrefix(secret, key.Prefix) {
+		t.Errorf("Unexpected secret %q for prefix %q", secret, key.Prefix)
+	}
+	if strings.Contains(objects["apikeys.json"], secret) {
+		t.Errorf("The secret must not be persisted")
+	}
+
+	found, err := m.Lookup(secret)
+	if err != nil || found.ID != key.ID || found.Rate != 10 {
+		t.Fatalf("Lookup = %+v, %v", found, err)
+	}
+	if _, err := m.Lookup("sk_wrong"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+
+	// Keys survive a restart
+	reloaded, err := NewManager(objects, "apikeys.json")
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if _, err := reloaded.Lookup(secret); err != nil {
+		t.Errorf("Expected the key to be reloaded, got %v", err)
+	}
+
+	if err := reloaded.Revoke(key.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if _, err := reloaded.Lookup(secret); !errors.Is(err, ErrRevokedKey) {
+		t.Errorf("Expected ErrRevokedKey, got %v", err)
+	}
+	if err := reloaded.Revoke("missing"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey, got %v", err)
+	}
+	if keys := reloaded.List(); len(keys) != 1 || !keys[0].Revoked() {
+		t.Errorf("Expected the revoked key to stay listed, got %+v", keys)
+	}
+}
+
+func TestManager_Usage(t *testing.T) {
+	m, _ := NewManager(memoryStore{}, "apikeys.json")
+	m.RecordUsage("id", false)
+	m.RecordUsage("id", true)
+
+	usage := m.Usage("id")
+	if usage.Requests != 2 || usage.RateLimited != 1 || usage.LastUsedAt.IsZero() {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+	if usage := m.Usage("other"); usage.Requests != 0 {
+		t.Errorf("Unexpected usage %+v", usage)
+	}
+}
+
+type failingStore struct{ memoryStore }
+
+func (failingStore) GetObject(key string) (string, error) {
+	return "", errors.New("access denied")
+}
+
+func TestNewManager_LoadErrors(t *testing.T) {
+	if _, err := NewManager(memoryStore{}, "apikeys.json"); err != nil {
+		t.Errorf("Expected a missing object to start without keys, got %v", err)
+	}
+	if _, err := NewManager(failingStore{memoryStore{}}, "apikeys.json"); err == nil {
+		t.Errorf("Expected a read error to be returned instead of starting without keys")
+	}
+}
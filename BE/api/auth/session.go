@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSession = errors.New("invalid session")
+
+// Session is the content of the signed session cookie.
+type Session struct {
+	UserID    string `json:"uid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Signer signs and verifies session cookies with HMAC-SHA256. The cookie value is
+// base64(payload) + "." + base64(signature), readable but not forgeable by the client.
+type Signer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret, now: time.Now}
+}
+
+func (s *Signer) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Encode returns the cookie value of a session of userID valid for ttl.
+func (s *Signer) Encode(userID string, ttl time.Duration) (string, error) {
+	data, err := json.Marshal(Session{UserID: userID, ExpiresAt: s.now().Add(ttl).Unix()})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode session: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + s.sign(payload), nil
+}
+
+// Decode verifies the signature and the expiration of a cookie value.
+func (s *Signer) Decode(value string) (Session, error) {
+	payload, signature, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return Session{}, ErrInvalidSession
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Session{}, ErrInvalidSession
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil || session.UserID == "" {
+		return Session{}, ErrInvalidSession
+	}
+	if s.now().Unix() >= session.ExpiresAt {
+		return Session{}, ErrInvalidSession
+	}
+	return session, nil
+}
+
+// RandomToken returns a URL safe random string, used for the OAuth state.
+func RandomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSigner_RoundTrip(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewSigner([]byte("secret"))
+	s.now = func() time.Time { return now }
+
+	value, err := s.Encode("user-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	session, err := s.Decode(value)
+	if err != nil || session.UserID != "user-1" {
+		t.Fatalf("Decode = %+v, %v", session, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := s.Decode(value); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Expected an expired session to be rejected, got %v", err)
+	}
+}
+
+func TestSigner_RejectsTampering(t *testing.T) {
+	s := NewSigner([]byte("secret"))
+	value, _ := s.Encode("user-1", time.Hour)
+	forged, _ := NewSigner([]byte("other")).Encode("user-1", time.Hour)
+
+	for _, bad := range []string{"", "no-dot", value + "x", "x" + value, forged} {
+		if _, err := s.Decode(bad); !errors.Is(err, ErrInvalidSession) {
+			t.Errorf("Decode(%q) = %v, want ErrInvalidSession", bad, err)
+		}
+	}
+}
+
+func TestRandomToken(t *testing.T) {
+	a, _ := RandomToken()
+	b, _ := RandomToken()
+	if len(a) < 40 || a == b {
+		t.Errorf("Expected long distinct tokens, got %q and %q", a, b)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"my_lambda_app/subtitle"
+)
+
+const (
+	// a provider failing this many times in a row is skipped during the cooldown
+	captionFailureThreshold = 3
+	captionProviderCooldown = 5 * time.Minute
+)
+
+var (
+	// errCaptionNotOffered means the provider has nothing to try for this video, e.g.
+	// youtube-metadata found no DownSub link. It does not count against its health.
+	errCaptionNotOffered = errors.New("caption not offered by this provider")
+	// errCaptionNotFound means the provider answered but the video has no caption in
+	// the requested languages, which is not a failure of the provider either.
+	errCaptionNotFound = errors.New("caption not found")
+)
+
+// CaptionRequest is what the providers know about the caption to fetch
+type CaptionRequest struct {
+	VideoID    string
+	Languages  []string // in order of preference
+	DownSubURL string
+}
+
+// CaptionProvider downloads the caption of a video as SRT or WebVTT
+type CaptionProvider interface {
+	Name() string
+	Fetch(request CaptionRequest) (string, error)
+}
+
+// captionLanguages is the video language first, then the summary language and English
+func captionLanguages(videoLang string, lang string) []string {
+	var languages []string
+	for _, l := range []string{videoLang, lang, "en"} {
+		if l != "" && !slices.Contains(languages, l) {
+			languages = append(languages, l)
+		}
+	}
+	return languages
+}
+
+// downSubProvider downloads the SRT link youtube-metadata got from DownSub
+type downSubProvider struct{}
+
+func (downSubProvider) Name() string { return "downsub" }
+
+func (downSubProvider) Fetch(request CaptionRequest) (string, error) {
+	if request.DownSubURL == "" {
+		return "", errCaptionNotOffered
+	}
+	return downloadSubtitleByDownSub(request.DownSubURL)
+}
+
+// transcriptPyProvider asks youtube-transcript-py-server for each language in turn
+type transcriptPyProvider struct{}
+
+func (transcriptPyProvider) Name() string { return "transcript-py" }
+
+func (transcriptPyProvider) Fetch(request CaptionRequest) (string, error) {
+	videoURL := "https://www.youtube.com/watch?v=" + request.VideoID
+	err := errCaptionNotFound
+	for _, lang := range request.Languages {
+		var caption string
+		caption, err = tryDownloadSubtitle(videoURL, request.VideoID, filepath.Join(tempDir, "%s.srt"), lang)
+		if err == nil {
+			return caption, nil
+		}
+		if !errors.Is(err, errCaptionNotFound) {
+			return "", err
+		}
+	}
+	return "", err
+}
+
+// ytDlpProvider runs yt-dlp for the automatic captions of each language in turn
+type ytDlpProvider struct{}
+
+func (ytDlpProvider) Name() string { return "yt-dlp" }
+
+func (ytDlpProvider) Fetch(request CaptionRequest) (string, error) {
+	videoURL := "https://www.youtube.com/watch?v=" + request.VideoID
+	err := errCaptionNotFound
+	for _, lang := range request.Languages {
+		var caption string
+		caption, err = tryDownloadSubtitle_yt_dlp(videoURL, request.VideoID, filepath.Join(tempDir, request.VideoID+".%(ext)s"), lang)
+		if err == nil {
+			return caption, nil
+		}
+	}
+	return "", err
+}
+
+// ProviderHealth is the track record of a caption provider since the server started
+type ProviderHealth struct {
+	Name                string  `json:"name"`
+	Successes           int     `json:"successes"`
+	Failures            int     `json:"failures"`
+	NotFound            int     `json:"not_found"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
+	SuccessRate         float64 `json:"success_rate"`
+	LatencyMs           int64   `json:"latency_ms"` // moving average of the answered calls
+	LastError           string  `json:"last_error,omitempty"`
+	SkippedUntil        string  `json:"skipped_until,omitempty"`
+
+	skippedUntil time.Time
+}
+
+// CaptionChain tries its providers in order until one returns a caption, skipping the
+// ones that keep failing until their cooldown is over.
+type CaptionChain struct {
+	mu        sync.Mutex
+	providers []CaptionProvider
+	health    map[string]*ProviderHealth
+	now       func() time.Time
+}
+
+func NewCaptionChain(providers ...CaptionProvider) *CaptionChain {
+	chain := &CaptionChain{providers: providers, health: map[string]*ProviderHealth{}, now: time.Now}
+	for _, provider := range providers {
+		chain.health[provider.Name()] = &ProviderHealth{Name: provider.Name()}
+	}
+	return chain
+}
+
+// captionProvidersFromEnv orders the providers with CAPTION_PROVIDERS
+// (e.g. "transcript-py,downsub"), all of them in the default order when unset
+func captionProvidersFromEnv() *CaptionChain {
+	available := []CaptionProvider{downSubProvider{}, transcriptPyProvider{}, ytDlpProvider{}}
+	names := strings.Split(os.Getenv("CAPTION_PROVIDERS"), ",")
+
+	var providers []CaptionProvider
+	for _, name := range names {
+		for _, provider := range available {
+			if provider.Name() == strings.TrimSpace(name) {
+				providers = append(providers, provider)
+			}
+		}
+	}
+	if len(providers) == 0 {
+		providers = available
+	}
+	return NewCaptionChain(providers...)
+}
+
+var captionProviders = captionProvidersFromEnv()
+
+func (c *CaptionChain) skipped(name string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now().Before(c.health[name].skippedUntil)
+}
+
+func (c *CaptionChain) record(name string, latency time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health := c.health[name]
+
+	switch {
+	case errors.Is(err, errCaptionNotOffered):
+		return
+	case err == nil:
+		health.Successes++
+		health.ConsecutiveFailures = 0
+	case errors.Is(err, errCaptionNotFound):
+		health.NotFound++
+		health.ConsecutiveFailures = 0
+	default:
+		health.Failures++
+		health.ConsecutiveFailures++
+		health.LastError = err.Error()
+		if health.ConsecutiveFailures >= captionFailureThreshold {
+			health.skippedUntil = c.now().Add(captionProviderCooldown)
+		}
+	}
+	if err == nil || errors.Is(err, errCaptionNotFound) {
+		if health.LatencyMs == 0 {
+			health.LatencyMs = latency.Milliseconds()
+		} else {
+			health.LatencyMs = (4*health.LatencyMs + latency.Milliseconds()) / 5
+		}
+	}
+	if attempts := health.Successes + health.Failures; attempts > 0 {
+		health.SuccessRate = float64(health.Successes) / float64(attempts)
+	}
+}
+
+// Fetch returns the first caption with at least one cue, along with the provider it
+// came from. A provider answering something unparseable (e.g. an out of credits page)
+// counts as failing.
+func (c *CaptionChain) Fetch(request CaptionRequest) (string, string, error) {
+	var errs []error
+	for _, provider := range c.providers {
+		name := provider.Name()
+		if c.skipped(name) {
+			errs = append(errs, fmt.Errorf("%s: skipped after %d failures", name, captionFailureThreshold))
+			continue
+		}
+
+		started := c.now()
+		caption, err := provider.Fetch(request)
+		if err == nil {
+			if _, parseErr := subtitle.Parse(caption); parseErr != nil {
+				err = fmt.Errorf("unusable caption: %w", parseErr)
+			}
+		}
+		c.record(name, c.now().Sub(started), err)
+		if err == nil {
+			return caption, name, nil
+		}
+		if !errors.Is(err, errCaptionNotOffered) {
+			log.Printf("⚠️ Caption provider %s failed for %s: %v", name, request.VideoID, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+	return "", "", fmt.Errorf("no caption provider succeeded: %w", errors.Join(errs...))
+}
+
+// Health lists the providers in the order they are tried
+func (c *CaptionChain) Health() []ProviderHealth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	health := make([]ProviderHealth, 0, len(c.providers))
+	for _, provider := range c.providers {
+		h := *c.health[provider.Name()]
+		if c.now().Before(h.skippedUntil) {
+			h.SkippedUntil = h.skippedUntil.UTC().Format(time.RFC3339)
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// handleCaptionProviders reports the health of the caption providers.
+// GET /captions/providers
+func handleCaptionProviders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(captionProviders.Health())
+}
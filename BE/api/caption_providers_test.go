@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const providerCaption = "1\n00:00:01,000 --> 00:00:02,000\nhello\n"
+
+type fakeCaptionProvider struct {
+	name    string
+	caption string
+	err     error
+	calls   int
+}
+
+func (p *fakeCaptionProvider) Name() string { return p.name }
+
+func (p *fakeCaptionProvider) Fetch(request CaptionRequest) (string, error) {
+	p.calls++
+	return p.caption, p.err
+}
+
+func TestCaptionChainFailover(t *testing.T) {
+	downsub := &fakeCaptionProvider{name: "downsub", err: errors.New("out of credits")}
+	transcript := &fakeCaptionProvider{name: "transcript-py", caption: providerCaption}
+	ytdlp := &fakeCaptionProvider{name: "yt-dlp", caption: providerCaption}
+	chain := NewCaptionChain(downsub, transcript, ytdlp)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	chain.now = func() time.Time { return now }
+
+	for i := 0; i < captionFailureThreshold+1; i++ {
+		caption, provider, err := chain.Fetch(CaptionRequest{VideoID: "dQw4w9WgXcQ"})
+		if err != nil || provider != "transcript-py" || caption != providerCaption {
+			t.Fatalf("Fetch() = %q, %q, %v", caption, provider, err)
+		}
+	}
+	if downsub.calls != captionFailureThreshold || ytdlp.calls != 0 {
+		t.Errorf("downsub called %d times, yt-dlp %d times", downsub.calls, ytdlp.calls)
+	}
+
+	health := chain.Health()
+	if health[0].Failures != captionFailureThreshold || health[0].SkippedUntil == "" || health[0].LastError != "out of credits" {
+		t.Errorf("downsub health = %+v", health[0])
+	}
+	if health[1].Successes != captionFailureThreshold+1 || health[1].SuccessRate != 1 {
+		t.Errorf("transcript-py health = %+v", health[1])
+	}
+
+	// after the cooldown the provider gets another chance
+	now = now.Add(captionProviderCooldown)
+	downsub.err, downsub.caption = nil, providerCaption
+	if _, provider, err := chain.Fetch(CaptionRequest{VideoID: "dQw4w9WgXcQ"}); err != nil || provider != "downsub" {
+		t.Errorf("Fetch() after cooldown = %q, %v", provider, err)
+	}
+	if health := chain.Health()[0]; health.ConsecutiveFailures != 0 || health.SkippedUntil != "" || health.SuccessRate != 0.25 {
+		t.Errorf("downsub health after recovery = %+v", health)
+	}
+}
+
+func TestCaptionChainNotFoundAndUnusable(t *testing.T) {
+	downsub := &fakeCaptionProvider{name: "downsub", err: errCaptionNotOffered}
+	transcript := &fakeCaptionProvider{name: "transcript-py", err: errCaptionNotFound}
+	ytdlp := &fakeCaptionProvider{name: "yt-dlp", caption: "<html>quota exceeded</html>"}
+	chain := NewCaptionChain(downsub, transcript, ytdlp)
+
+	_, _, err := chain.Fetch(CaptionRequest{VideoID: "dQw4w9WgXcQ"})
+	if err == nil || !strings.Contains(err.Error(), "unusable caption") {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	health := chain.Health()
+	if health[0] != (ProviderHealth{Name: "downsub"}) {
+		t.Errorf("a provider without a link is not failing: %+v", health[0])
+	}
+	if health[1].NotFound != 1 || health[1].Failures != 0 {
+		t.Errorf("a missing transcript is not a failure: %+v", health[1])
+	}
+	if health[2].Failures != 1 {
+		t.Errorf("an unparseable caption is a failure: %+v", health[2])
+	}
+}
+
+func TestCaptionLanguages(t *testing.T) {
+	if got := captionLanguages("pt", "en"); !reflect.DeepEqual(got, []string{"pt", "en"}) {
+		t.Errorf("captionLanguages(pt, en) = %v", got)
+	}
+	if got := captionLanguages("", "fr"); !reflect.DeepEqual(got, []string{"fr", "en"}) {
+		t.Errorf("captionLanguages(, fr) = %v", got)
+	}
+}
+
+func TestHandleCaptionProviders(t *testing.T) {
+	t.Setenv("API_ADMIN_TOKEN", "admin-secret")
+	request := httptest.NewRequest("GET", "/captions/providers", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, request)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("without token = %d, want 403", rec.Code)
+	}
+
+	request.Header.Set("Authorization", "Bearer admin-secret")
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, request)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"name":"downsub"`) {
+		t.Errorf("GET /captions/providers = %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"sync"
+)
+
+// Event is a single message pushed to the subscribers of a topic.
+type Event struct {
+	Type string
+	Data interface{}
+}
+
+// Broker is an in-process pub/sub keyed by topic (e.g. "{videoId}#{lang}").
+// Slow subscribers lose their oldest events instead of blocking the publisher, so the
+// latest one (e.g. the final result of a job) always reaches them.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+	bufferSize  int
+}
+
+func NewBroker(bufferSize int) *Broker {
+	return &Broker{
+		subscribers: make(map[string]map[chan Event]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe returns a channel receiving the events of topic and a function that unsubscribes it.
+func (b *Broker) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, b.bufferSize)
+
+	b.mu.Lock()
+	if b.subscribers[topic] == nil {
+		b.subscribers[topic] = make(map[chan Event]struct{})
+	}
+	b.subscribers[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers[topic], ch)
+			if len(b.subscribers[topic]) == 0 {
+				delete(b.subscribers, topic)
+			}
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+func (b *Broker) Publish(topic string, event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[topic] {
+		select {
+		case ch <- event:
+			continue
+		default:
+		}
+		// the buffer is full: make room by dropping the oldest event. Publishers hold the
+		// lock and subscribers only receive, so the second send cannot fail.
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers returns how many listeners a topic currently has.
+func (b *Broker) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers[topic])
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestBroker_PublishSubscribe(t *testing.T) {
+	b := NewBroker(4)
+
+	ch, cancel := b.Subscribe("vid#en")
+	other, cancelOther := b.Subscribe("vid#pt")
+	defer cancelOther()
+
+	b.Publish("vid#en", Event{Type: "status", Data: "metadata-processed"})
+
+	select {
+	case e := <-ch:
+		if e.Type != "status" || e.Data != "metadata-processed" {
+			t.Errorf("Unexpected event %+v", e)
+		}
+	default:
+		t.Fatal("Expected an event on the subscribed topic")
+	}
+
+	select {
+	case e := <-other:
+		t.Errorf("Expected no event on another topic, got %+v", e)
+	default:
+	}
+
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Errorf("Expected channel to be closed after cancel")
+	}
+	if n := b.Subscribers("vid#en"); n != 0 {
+		t.Errorf("Expected 0 subscribers, got %d", n)
+	}
+
+	// Cancelling twice must not panic
+	cancel()
+}
+
+func TestBroker_SlowSubscriberDoesNotBlock(t *testing.T) {
+	b := NewBroker(1)
+	ch, cancel := b.Subscribe("topic")
+	defer cancel()
+
+	b.Publish("topic", Event{Type: "a"})
+	b.Publish("topic", Event{Type: "b"})
+	b.Publish("topic", Event{Type: "result"})
+
+	if e := <-ch; e.Type != "result" {
+		t.Errorf("Expected the latest event to be kept, got %+v", e)
+	}
+	select {
+	case e := <-ch:
+		t.Errorf("Expected the older events to be dropped, got %+v", e)
+	default:
+	}
+}
@@ -3,8 +3,8 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -19,19 +19,15 @@ import (
 	"time"
 	"unicode"
 
-	"context"
-
 	_ "embed"
 
+	"my_lambda_app/apikeys"
+	"my_lambda_app/slug"
+	"my_lambda_app/store"
+	"my_lambda_app/subtitle"
 	"my_lambda_app/videostate"
+	"my_lambda_app/webhooks"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/rs/cors"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
@@ -46,48 +42,58 @@ var system2PromptTxt string
 //go:embed prompts/user1.prompt.txt
 var user1PromptTxt string
 
-var videoQueue = videostate.NewProcessor()
+var videoQueue *videostate.Processor
 
 var processingVideosOld = make(map[string]bool)
 var processingMu sync.Mutex
 
-// OAuth2 configuration for Google
+// OAuth2 configuration for Google sign-in, see accounts.go
 var googleOAuthConfig = &oauth2.Config{
 	ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),     // Set your Google OAuth Client ID
 	ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"), // Set your Google OAuth Client Secret
-	RedirectURL:  "https://b0fe-2804-14c-cc92-94de-a04e-df67-2d58-c638.ngrok-free.app/redirects",  // Redirect URL must match the one configured in Google Console
-	Scopes:       []string  { 
-                                "https://www.googleapis.com/auth/userinfo.email", 
-                                "https://www.googleapis.com/auth/youtube.download",
-                                "https://www.googleapis.com/auth/youtube.force-ssl",
-                                "https://www.googleapis.com/auth/youtubepartner",
-                            },
+	RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URL"),  // Redirect URL must match the one configured in Google Console, e.g. https://api.sumtube.io/redirects
+	Scopes:       []string{"openid", "email", "profile"},
 	Endpoint:     google.Endpoint,
 }
 
 const tempDir = "/tmp"
-const bucketName = "sumtube"
-var dynamoDBTableName = os.Getenv("DYNAMODB_TABLE_NAME")
-
-
 
-var s3Client *s3.Client
-var dynamoDBClient *dynamodb.Client
+var dataStore store.Store
 
 func init() {
-	fmt.Println("Initializing AWS SDK...")
-	if dynamoDBTableName == "" {
-		log.Println("Please add DYNAMODB_TABLE_NAME to .env file")
+	fmt.Println("Initializing storage backend...")
+	var err error
+	dataStore, err = store.NewFromEnv()
+	if err != nil {
+		log.Fatalf("unable to initialize storage backend, %v\n", err)
+	}
+	fmt.Println("Storage backend initialized successfully.")
+}
+
+// openVideoQueue restores the processing queue from its journal, QUEUE_JOURNAL_PATH.
+// Completed and failed jobs stay QUEUE_FINAL_TTL_SECONDS in it (40 by default).
+// It runs from main so the tests never open the journal of the working directory.
+func openVideoQueue() (*videostate.Processor, error) {
+	journalPath := os.Getenv("QUEUE_JOURNAL_PATH")
+	if journalPath == "" {
+		journalPath = "./data/queue.json"
+	}
+	journal, err := videostate.NewFileJournal(journalPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open queue journal: %w", err)
+	}
+	ttl := videostate.DefaultTTL
+	if seconds, err := strconv.Atoi(os.Getenv("QUEUE_FINAL_TTL_SECONDS")); err == nil && seconds > 0 {
+		ttl = time.Duration(seconds) * time.Second
 	}
-	cfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion("us-east-1"),
-	)
+	queue, err := videostate.NewDurableProcessor(journal, ttl)
 	if err != nil {
-		log.Fatalf("unable to load AWS SDK config, %v\n", err)
+		return nil, fmt.Errorf("unable to restore processing queue: %w", err)
 	}
-	s3Client = s3.NewFromConfig(cfg)
-	dynamoDBClient = dynamodb.NewFromConfig(cfg)
-	fmt.Println("AWS SDK initialized successfully.")
+	queue.OnStatusChange(publishSummaryStatus)
+	queue.OnStatusChange(notifySummaryWebhooks)
+	queue.OnStatusChange(recordSummaryFailure)
+	return queue, nil
 }
 
 func extractVideoID(url string) (string, error) {
@@ -107,6 +113,7 @@ type Caption struct {
 type VideoMetadata struct {
 	Title        string    `json:"title"`
 	ViewCount    string    `json:"view_count"`
+	LikeCount    string    `json:"like_count"`
 	LengthSeconds string   `json:"length_seconds"`
 	ChannelId    string    `json:"channel_id"`
 	ChannelName  string    `json:"channel_name"`
@@ -117,6 +124,7 @@ type VideoMetadata struct {
 }
 
 
+var videoMetadataURL = "http://youtube-metadata-server:6060/metadata"
 
 func getVideoMetadata(videoURL string) (*VideoMetadata, error) {
 	// proxy := os.Getenv("YDT_PROXY_SERVER")
@@ -126,7 +134,7 @@ func getVideoMetadata(videoURL string) (*VideoMetadata, error) {
 		return nil, fmt.Errorf("failed to extract video ID: %w", err)
 	}
 
-	baseURL := "http://youtube-metadata-server:6060/metadata"
+	baseURL := videoMetadataURL
 	query := url.Values{}
 	query.Set("vid", videoID)
 
@@ -225,17 +233,20 @@ func sanitizeTitle(title string) string {
 }
 
 
-func convertTitleToURL(title string) string {
-	lowercaseTitle := strings.ToLower(title)
-	urlFriendly := strings.ReplaceAll(lowercaseTitle, " ", "-")
-	urlFriendly = removeDuplicateHyphens(urlFriendly)
-	var result strings.Builder
-	for _, char := range urlFriendly {
-		if unicode.IsLetter(char) || unicode.IsDigit(char) || char == '-' {
-			result.WriteRune(char)
+// localizedPath is the slug of the title in one language, suffixed with the language
+// when another language of the video already uses it, so every language has its own path.
+// Titles without any letter to keep fall back to "video-{videoId}".
+func localizedPath(paths map[string]string, videoID string, language string, title string) string {
+	path := slug.ForLanguage(title, language)
+	if path == "" {
+		path = "video-" + videoID
+	}
+	for lang, other := range paths {
+		if lang != language && other == path {
+			return path + "-" + language
 		}
 	}
-	return result.String()
+	return path
 }
 
 func downloadSubtitleByDownSub(downSubUrl string) (string, error) {
@@ -264,34 +275,6 @@ func downloadSubtitleByDownSub(downSubUrl string) (string, error) {
 
 }
 
-func downloadSubtitle(videoURL string, lang string) (string, error) {
-	videoID, err := extractVideoID(videoURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to extract video ID: %w", err)
-	}
-
-	outputTemplate := filepath.Join(tempDir, "%s.srt") // Template for saving file
-
-	// Try to download in the requested language
-	fmt.Printf("Attempting to download subtitles in language '%s'...\n", lang)
-	subtitle, err := tryDownloadSubtitle(videoURL, videoID, outputTemplate, lang)
-	if err == nil {
-		return subtitle, nil
-	}
-
-	// If failed, try with English
-	if lang != "en" {
-		fmt.Printf("Failed to download subtitles in language '%s', trying English instead...\n", lang)
-		subtitle, err = tryDownloadSubtitle(videoURL, videoID, outputTemplate, "en")
-		if err == nil {
-			return subtitle, nil
-		}
-	}
-
-	// If both attempts fail, return error
-	return "", fmt.Errorf("failed to download subtitles in either '%s' or 'en': %w", lang, err)
-}
-
 // tryDownloadSubtitle requests subtitles from the internal transcript server and saves them to disk.
 
 func tryDownloadSubtitle(videoURL, videoID, outputTemplate, lang string) (string, error) {
@@ -316,7 +299,10 @@ func tryDownloadSubtitle(videoURL, videoID, outputTemplate, lang string) (string
 	}
 	defer resp.Body.Close()
 
-	// Check status code
+	// Check status code, 403/404 mean the video has no transcript in this language
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return "", fmt.Errorf("%w: subtitle server returned status %d", errCaptionNotFound, resp.StatusCode)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("subtitle server returned status %d", resp.StatusCode)
 	}
@@ -361,7 +347,7 @@ func tryDownloadSubtitle_yt_dlp(videoURL, videoID, outputTemplate, lang string)
 		return "", fmt.Errorf("failed to run yt-dlp: %w", err)
 	}
 
-	searchQuery := fmt.Sprintf("%s*.vtt", videoID)
+	searchQuery := fmt.Sprintf("%s.%s*.vtt", videoID, lang)
 	subtitleFilePath := filepath.Join(tempDir, searchQuery)
 	files, err := filepath.Glob(subtitleFilePath)
 	if err != nil {
@@ -369,7 +355,7 @@ func tryDownloadSubtitle_yt_dlp(videoURL, videoID, outputTemplate, lang string)
 	}
 
 	if len(files) == 0 {
-		return "", fmt.Errorf("no subtitle files found")
+		return "", fmt.Errorf("%w: no subtitle files found", errCaptionNotFound)
 	}
 
 	subtitleFilePath = files[0]
@@ -381,180 +367,6 @@ func tryDownloadSubtitle_yt_dlp(videoURL, videoID, outputTemplate, lang string)
 	return string(data), nil
 }
 
-func uploadToS3(content string, key string) error {
-	fmt.Println("Uploading subtitle to S3...")
-	_, err := s3Client.PutObject(context.Background(), &s3.PutObjectInput{
-		Bucket:      aws.String(bucketName),
-		Key:         aws.String(key),
-		Body:        strings.NewReader(content),
-		ContentType: aws.String("text/plain"),
-		ACL:         types.ObjectCannedACLPrivate,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to upload to S3: %w", err)
-	}
-	fmt.Println("Subtitle uploaded to S3 successfully.")
-	return nil
-}
-
-func fetchS3(key string) (string, error) {
-    fmt.Println("Fetching content from S3...")
-    
-    // Get the object from S3
-    output, err := s3Client.GetObject(context.Background(), &s3.GetObjectInput{
-        Bucket: aws.String(bucketName),
-        Key:    aws.String(key),
-    })
-    if err != nil {
-        return "", fmt.Errorf("failed to fetch from S3: %w", err)
-    }
-    defer output.Body.Close()
-
-    // Read the content from the response body
-    content, err := io.ReadAll(output.Body)
-    if err != nil {
-        return "", fmt.Errorf("failed to read S3 content: %w", err)
-    }
-
-    fmt.Println("Content fetched from S3 successfully.")
-    return string(content), nil
-}
-
-
-
-
-func stringMapToAttributeValueMap(m map[string]string) map[string]dynamodbtypes.AttributeValue {
-    avMap := make(map[string]dynamodbtypes.AttributeValue)
-    for k, v := range m {
-        avMap[k] = &dynamodbtypes.AttributeValueMemberS{Value: v}
-    }
-    return avMap
-}
-
-func deleteVideoFromDynamoDB(videoId string) error {
-	key := map[string]dynamodbtypes.AttributeValue{
-		"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("VIDEO#%s", videoId)},
-		"SK": &dynamodbtypes.AttributeValueMemberS{Value: "METADATA"},
-	}
-
-	_, err := dynamoDBClient.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
-		TableName: aws.String(dynamoDBTableName),
-		Key:       key,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to delete video %s from DynamoDB: %w", videoId, err)
-	}
-
-	fmt.Printf("Video %s deleted from DynamoDB successfully.\n", videoId)
-	return nil
-}
-
-func pushMetadataToDynamoDB(data videostate.Metadata) error {
-    // Pad LikeCount to 8 digits for correct lexicographic sort
-    // paddedLikeCount := fmt.Sprintf("%08d", data.LikeCount)
-    dateTimeNow := time.Now().Format("2006-01-02 15:04")
-
-    // Build maps for multilingual fields
-	if data.Vid == "" {
-		return fmt.Errorf("Vid not found")
-	}
-
-    item := map[string]dynamodbtypes.AttributeValue{
-        "PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("VIDEO#%s", data.Vid)},
-        "SK": &dynamodbtypes.AttributeValueMemberS{Value: "METADATA"},
-
-        // GSI for querying by video asc/desc
-        "GSI1PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("VIDS#")},
-        "GSI1SK": &dynamodbtypes.AttributeValueMemberS{
-            Value: fmt.Sprintf("MOD#%s", dateTimeNow), //  Article creation
-        },
-
-		// GSI for quering by CHAN#{Channel Name}
-        "GSI2PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("CHAN#%s", data.ChannelId)},
-        "GSI2SK": &dynamodbtypes.AttributeValueMemberS{
-            Value: fmt.Sprintf("UPL#%s", data.UploadDate), //  Video Upload
-        },
-
-        // Main data fields
-        "vid":                    &dynamodbtypes.AttributeValueMemberS{Value: data.Vid},
-
-        "lang":                   &dynamodbtypes.AttributeValueMemberS{Value: data.Lang},
-
-        "channel_id":            &dynamodbtypes.AttributeValueMemberS{Value: data.ChannelId},
-        "video_upload_date":      &dynamodbtypes.AttributeValueMemberS{Value: data.UploadDate}, // yt publish date
-        "duration":               &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%.2f", float64(data.Duration))},
-        "channel_name":             &dynamodbtypes.AttributeValueMemberS{Value: data.ChannelName},
-        "category":               &dynamodbtypes.AttributeValueMemberS{Value: data.Category},
-        "video_lang":             &dynamodbtypes.AttributeValueMemberS{Value: data.VideoLang},
-
-        // Now stored as Maps
-		"title":   &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Title)},
-        "summary": &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Summary)},
-        "answer":  &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Answer)},
-        "path":    &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Path)},
-		"status":  &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Status)},
-
-
-        "article_update_datetime": &dynamodbtypes.AttributeValueMemberS{Value: time.Now().Format("2006-01-02T15:04:05")},
-        "like_count":              &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", data.LikeCount)},
-        "downsub_download_cap":    &dynamodbtypes.AttributeValueMemberS{Value: data.DownSubDownloadCap},
-    }
-
-    _, err := dynamoDBClient.PutItem(context.Background(), &dynamodb.PutItemInput{
-        TableName: aws.String(dynamoDBTableName),
-        Item:      item,
-    })
-    if err != nil {
-        return fmt.Errorf("failed to push to DynamoDB: %w", err)
-    }
-
-    fmt.Println("Data pushed to DynamoDB successfully.")
-    return nil
-}
-
-
-func pushCategoryStatsToDynamoDB(data videostate.Metadata, lang string) error {
-    // Pad LikeCount to 8 digits for correct lexicographic sort
-    paddedLikeCount := fmt.Sprintf("%08d", data.LikeCount)
-    dateTimeNow := time.Now().Format("2006-01-02 15:04")
-
-    item := map[string]dynamodbtypes.AttributeValue{
-        "PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("LANG#%s", lang)},
-        "SK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("CAT#%s#CREATED#%s", data.Category, dateTimeNow)},
-
-        // GSI for querying by category and LikeCount
-        "GSI1PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("CAT#%s", data.Category)},
-        "GSI1SK": &dynamodbtypes.AttributeValueMemberS{
-            Value: fmt.Sprintf("LANG#%s#LIKES#%s", lang, paddedLikeCount),
-        },
-
-        // Main data fields
-        "vid":                    &dynamodbtypes.AttributeValueMemberS{Value: data.Vid},
-        "lang":                   &dynamodbtypes.AttributeValueMemberS{Value: lang},
-        "uploader_name":            &dynamodbtypes.AttributeValueMemberS{Value: data.ChannelName},
-        "video_upload_date":      &dynamodbtypes.AttributeValueMemberS{Value: data.UploadDate}, // yt publish date
-        "category":               &dynamodbtypes.AttributeValueMemberS{Value: data.Category},
-		"video_lang":             &dynamodbtypes.AttributeValueMemberS{Value: data.VideoLang},
-
-        // Now stored as Maps
-		"title":   &dynamodbtypes.AttributeValueMemberS{Value: data.Title[lang]},
-        "path":    &dynamodbtypes.AttributeValueMemberS{Value: data.Path[lang]},
-
-        "like_count":              &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", data.LikeCount)},
-    }
-
-    _, err := dynamoDBClient.PutItem(context.Background(), &dynamodb.PutItemInput{
-        TableName: aws.String(dynamoDBTableName),
-        Item:      item,
-    })
-    if err != nil {
-        return fmt.Errorf("failed to push to DynamoDB: %w", err)
-    }
-
-    fmt.Println("Data pushed to DynamoDB successfully.")
-    return nil
-}
-
 func parseJSONContent(jsonString string) (map[string]string, error) {
 	log.Printf("Parsing JSON: %s", jsonString)
     if strings.TrimSpace(jsonString) == "" {
@@ -669,105 +481,89 @@ func listPromptsDir(promptsDir string) error {
 }
 
 
-// ExtractLastTimestamp extracts the last end timestamp from SRT content
-func ExtractLastTimestamp(content string) (string, error) {
-	re := regexp.MustCompile(`(\d{1,2}:\d{2}:\d{2}),\d{3}\s*-->\s*(\d{1,2}:\d{2}:\d{2}),\d{3}`)
-	matches := re.FindAllStringSubmatch(content, -1)
-
-	if len(matches) == 0 {
-		return "", fmt.Errorf("no timestamp found")
+// captionDuration is the end of the last cue of a caption
+func captionDuration(caption string) (time.Duration, error) {
+	cues, err := subtitle.Parse(caption)
+	if err != nil {
+		return 0, err
 	}
-
-	lastTimestamp := matches[len(matches)-1][2]
-	return lastTimestamp, nil
+	return subtitle.Duration(cues), nil
 }
 
-// TestCase holds input and expected output
-type TestCase struct {
-	name     string
-	input    string
-	expected string
-}
-func ExtractLastTimestamp_yt_dlp(content string) (string, error) {
-    re := regexp.MustCompile(`\d{2}:\d{2}:\d{2}\.\d{3} --> (\d{2}:\d{2}:\d{2})\.\d{3}`)
-    matches := re.FindAllStringSubmatch(content, -1)
-
-    if len(matches) == 0 {
-        return "", fmt.Errorf("nenhum timestamp encontrado")
-    }
-
-    // Pega o último timestamp de saída (apenas hh:mm:ss)
-    lastTimestamp := matches[len(matches)-1][1]
-
-    return lastTimestamp, nil
-}
-
-
-// Extra: Se quiser converter para time.Duration
-func ParseTimestampToDuration(ts string) (int, error) {
-    parsed, err := time.Parse("15:04:05", ts)
-    if err != nil {
-        return 0, err
-    }
-    
-    // Calculate total seconds
-    totalSeconds := parsed.Hour()*3600 + parsed.Minute()*60 + parsed.Second()
-    return totalSeconds, nil
+// normalizeCaption rewrites a downloaded caption (SRT, WebVTT) as SRT without the markup
+// and the rolling lines of automatic captions, it is kept as is when it cannot be parsed
+func normalizeCaption(caption string) string {
+	cues, err := subtitle.Normalize(caption)
+	if err != nil {
+		log.Printf("⚠️ Caption kept as downloaded: %v", err)
+		return caption
+	}
+	return subtitle.FormatSRT(cues)
 }
 
 
 func summarizeText(caption string, lang string, title string) (string, error) {
 
-	ts, errExtraction := ExtractLastTimestamp(caption)
-	if errExtraction != nil {
-		return "", fmt.Errorf("failed to extract last timestamp: %w", errExtraction)
-	}
-	tsToDuration, errDuration := ParseTimestampToDuration(ts)
-	if errDuration != nil {
-		return "", fmt.Errorf("failed to parse timestamp to duration: %w", errDuration)
+	duration, err := captionDuration(caption)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the caption duration: %w", err)
 	}
+	println("caption duration: ", duration.String())
 
-	println("ts",ts)
-	println("tsToDuration: ", tsToDuration)
-	println("seconds",(20*60))
+	if window := chunkWindow(llmModelName); duration > window {
+		return summarizeLongCaption(caption, lang, title, llmModelName, window)
+	}
 	
 	summary, err := llmModelSummarize(title, lang, caption )
 	if (err != nil) {
 		return "", fmt.Errorf("failed to summarize text: %w", err)
 	}
-	return string(summary.Result), nil
+	return llmResponseText(summary)
 }
 
 // Struct to send request
 type SummarizeRequest struct {
 	Model          string `json:"model"`
 	PromptTemplate string `json:"prompt_template"`
-	Input          struct {
-		Language string `json:"language"`
-		Title    string `json:"title"`
-		Captions string `json:"captions"`
-	} `json:"input"`
+	Output         string `json:"output"`
+	Input          LLMInput `json:"input"`
+}
+
+// LLMInput fills the $$language$$, $$title$$, $$captions$$ and $$question$$ placeholders of the templates
+type LLMInput struct {
+	Language string `json:"language"`
+	Title    string `json:"title"`
+	Captions string `json:"captions"`
+	Question string `json:"question,omitempty"`
 }
 
 // Struct for response
 type SummarizeResponse struct {
-	Result          string `json:"result"`
+	Result          json.RawMessage `json:"result"`
 	Error           string      `json:"error,omitempty"`
 	RequestDuration string      `json:"request_duration"`
 }
 
-// Calls llm-model service
+const llmModelName = "gemini-2.0-flash" // deepseekr1 or "gemini-2.0-flash"
+
+// Calls llm-model service with the summary template
 func llmModelSummarize(title string, lang string, caption string) (*SummarizeResponse, error) {
+	return callLLMModel(llmModelName, "prompt1", llmOutputJSON, LLMInput{Language: lang, Title: title, Captions: caption})
+}
+
+// callLLMModel is a variable so tests can run the summary pipeline without llm-model
+var callLLMModel = llmModelRequest
+
+func llmModelRequest(model string, promptTemplate string, output string, input LLMInput) (*SummarizeResponse, error) {
 	url := "http://llm-model:3030/summarize" // service name from docker-compose
 
 	// Build request payload
 	payload := SummarizeRequest{
-		Model:          "gemini-2.0-flash",       // deepseekr1 or "gemini-2.0-flash"
-		PromptTemplate: "prompt1",          // choose which template
+		Model:          model,
+		PromptTemplate: promptTemplate,          // choose which template
+		Output:         output,
+		Input:          input,
 	}
-	payload.Input.Language = lang
-	payload.Input.Title = title
-	payload.Input.Captions = caption
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -796,56 +592,6 @@ func llmModelSummarize(title string, lang string, caption string) (*SummarizeRes
 }
 
 
-func sanitizeSubtitle(subtitle string) string {
-	re := regexp.MustCompile(`\s*\n\n[\s\S]*?</c>\n`)
-	summarizedSrt := re.ReplaceAllString(subtitle, "\n")
-	summarizedSanitized := strings.ReplaceAll(summarizedSrt, "align:start position:0%", "")
-	return summarizedSanitized
-}
-
-
-func getSummaryFromDynamoDB(vid string, lang string) (map[string]dynamodbtypes.AttributeValue, error) {
-    key := map[string]dynamodbtypes.AttributeValue{
-        "PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("VIDEO#%s", vid)},
-        "SK": &dynamodbtypes.AttributeValueMemberS{Value: "METADATA"},
-    }
-
-    result, err := dynamoDBClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
-        TableName: aws.String(dynamoDBTableName),
-        Key:       key,
-    })
-    if err != nil {
-        return nil, fmt.Errorf("failed to get item from DynamoDB: %w", err)
-    }
-
-    if result.Item == nil {
-        return nil, nil
-    }
-
-    // Helper to extract the correct lang value
-    // extractLangValue := func(attr dynamodbtypes.AttributeValue) dynamodbtypes.AttributeValue {
-    //     if m, ok := attr.(*dynamodbtypes.AttributeValueMemberM); ok {
-    //         if val, exists := m.Value[lang]; exists {
-    //             return val
-    //         }
-    //     }
-    //     return &dynamodbtypes.AttributeValueMemberS{Value: ""}
-    // }
-
-    // // Replace the maps with just the single-language values
-    // if val, ok := result.Item["summary"]; ok {
-    //     result.Item["summary"] = extractLangValue(val)
-    // }
-    // if val, ok := result.Item["answer"]; ok {
-    //     result.Item["answer"] = extractLangValue(val)
-    // }
-    // if val, ok := result.Item["path"]; ok {
-    //     result.Item["path"] = extractLangValue(val)
-    // }
-
-    return result.Item, nil
-}
-
 func firstNonEmptyFromMap(m map[string]string) string {
     for _, v := range m {
         if v != "" {
@@ -856,91 +602,6 @@ func firstNonEmptyFromMap(m map[string]string) string {
 }
 
 
-func getLatestVideosByCategoryFromDynamoDB(lang string, category string, minLikes int, limit int) ([]videostate.Metadata, error) {
-    input := &dynamodb.QueryInput{
-        TableName:              aws.String(dynamoDBTableName),
-        KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
-        ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
-            ":pk": &dynamodbtypes.AttributeValueMemberS{
-                Value: fmt.Sprintf("LANG#%s", lang),
-            },
-            ":sk": &dynamodbtypes.AttributeValueMemberS{
-                Value: fmt.Sprintf("CAT#%s", category),
-            },
-        },
-        ScanIndexForward: aws.Bool(false),             // newest first
-        Limit:            aws.Int32(int32(limit * 2)), // fetch extra to filter manually
-    }
-
-    result, err := dynamoDBClient.Query(context.TODO(), input)
-    if err != nil {
-        return nil, fmt.Errorf("failed to query videos by category: %w", err)
-    }
-
-    var filtered []videostate.Metadata
-
-    for _, item := range result.Items {
-        // Extract like_count
-        likeAttr, ok := item["like_count"].(*dynamodbtypes.AttributeValueMemberN)
-        if !ok {
-            continue
-        }
-        likeCount, err := strconv.Atoi(likeAttr.Value)
-        if err != nil || likeCount < minLikes {
-            continue
-        }
-
-        var meta videostate.Metadata
-
-        // Extract safe values
-        if v, ok := item["vid"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.Vid = v.Value
-        }
-        if v, ok := item["lang"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.Lang = v.Value
-        }
-        if v, ok := item["category"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.Category = v.Value
-        }
-        if v, ok := item["video_upload_date"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.UploadDate = v.Value
-        }
-        if v, ok := item["uploader_id"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.ChannelId = v.Value
-        }
-        if v, ok := item["channel_name"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.ChannelName = v.Value
-        }
-
-        // Handle multilingual fields (string → map)
-        if v, ok := item["title"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.Title = map[string]string{lang: v.Value}
-        }
-        if v, ok := item["path"].(*dynamodbtypes.AttributeValueMemberS); ok {
-            meta.Path = map[string]string{lang: v.Value}
-        }
-
-        // Set like count
-        meta.LikeCount = likeCount
-
-        filtered = append(filtered, meta)
-        if len(filtered) >= limit {
-            break
-        }
-    }
-
-    return filtered, nil
-}
-
-
-
-
-
-
-
-
-
-
 type HandleSummaryRequestResponse struct {
 	VideoID      string  			`json:"videoId"`
 	Title       map[string]string  	`json:"title"`
@@ -957,6 +618,7 @@ type HandleSummaryRequestResponse struct {
 	Category    string  			`json:"category"`
 	VideoLang   string  			`json:"video_lang"`
 	LikeCount 	int					`json:"like_count"`
+	ViewCount 	int					`json:"view_count"`
 	CanBeRetried map[string]bool	`json:"can_be_retried"`
 }
 
@@ -977,6 +639,7 @@ type HandleSummarySingleLanguageRequestResponse struct {
 	Category    	string  `json:"category"`
 	VideoLang   	string  `json:"video_lang"`
 	LikeCount 		int		`json:"like_count"`
+	ViewCount 		int		`json:"view_count"`
 	CanBeRetried 	bool  	`json:"can_be_retried"`
 	
 }
@@ -992,25 +655,6 @@ type VideoGPTSummary struct {
     PublishDate     *time.Time `json:"publish_date,omitempty"`
 }
 
-type DynamoDbResponseToJson struct {
-	Vid        			string `dynamodbav:"vid" json:"vid"`
-    Title      			map[string]string `dynamodbav:"title" json:"title"`
-    Summary     		map[string]string `dynamodbav:"summary" json:"content"`
-	Answer     			map[string]string `dynamodbav:"answer" json:"answer"`
-	Path       			map[string]string `dynamodbav:"path" json:"path"`
-    Status     			map[string]string `dynamodbav:"status" json:"status"`
-	Category   			string `dynamodbav:"category" json:"category"`
-	LikeCount  			int    `dynamodbav:"like_count" json:"like_count"`
-    Lang       			string `dynamodbav:"lang" json:"lang"`
-	VideoLang  			string `dynamodbav:"video_lang" json:"videoLang"`
-    ChannelId 			string `dynamodbav:"channel_id" json:"channel_id"`
-    UploadDate 			string `dynamodbav:"video_upload_date" json:"uploadDate"`
-	ArticleUploadDateTime string `dynamodbav:"article_update_datetime" json:"articleUploadDateTime"`
-    Duration   			int `dynamodbav:"duration" json:"duration"` // or float64 if needed
-	ChannelName   		string `dynamodbav:"channel_name" json:"channel_name"`
-	DownsubDownloadCap 	string `dynamodbav:"downsub_download_cap" json:"downsubDownloadCap"`
-}
-
 func isThisStatusProcessing(currentStatus string) bool {
 	if strings.HasPrefix(currentStatus, "processing-") || strings.HasPrefix(currentStatus, "download-") || strings.HasPrefix(currentStatus, "metadata-"){
 		return true
@@ -1047,42 +691,24 @@ func loadContentWhenItsCached(videoID string, lang string) (videostate.Metadata,
 		Answer  string `json:"answer"`
 	}
 
-	cachedData, err := getSummaryFromDynamoDB(videoID, lang)
+	cachedData, err := dataStore.GetMetadata(videoID)
 	if err != nil {
-		log.Printf("❌ Error fetching from DynamoDB: %v", err)
+		log.Printf("❌ Error fetching from store: %v", err)
 		return metadata, err
 	}
-
-	var dynamoDbResponse DynamoDbResponseToJson
-	if err := attributevalue.UnmarshalMap(cachedData, &dynamoDbResponse); err != nil {
-		log.Printf("❌ Failed to unmarshal DynamoDB item: %v", err)
-		return metadata, err
+	if cachedData == nil {
+		log.Println("ℹ️ No valid cached data found.")
+		return metadata, nil
 	}
 
-	log.Printf("✅ Loaded DynamoDB content: status=%s, title=%s", dynamoDbResponse.Status, dynamoDbResponse.Title)
+	log.Printf("✅ Loaded stored content: status=%s, title=%s", cachedData.Status, cachedData.Title)
 
 	
-	// Check if it's processing in DynamoDb
-	if dynamoDbResponse.Status != nil{
-		
-		return videostate.Metadata{
-			Title:                 dynamoDbResponse.Title,
-			Vid:                   videoID,
-			Summary:               dynamoDbResponse.Summary,
-			Category:              dynamoDbResponse.Category,
-			Lang:                  lang,
-			Answer:                dynamoDbResponse.Answer,
-			Path:                  dynamoDbResponse.Path,
-			Status:                dynamoDbResponse.Status,
-			ChannelId:             dynamoDbResponse.ChannelId,
-			UploadDate:            dynamoDbResponse.UploadDate,
-			ArticleUploadDateTime: dynamoDbResponse.ArticleUploadDateTime,
-			Duration:              dynamoDbResponse.Duration,
-			LikeCount: 			   dynamoDbResponse.LikeCount,
-			ChannelName: 			   dynamoDbResponse.ChannelName,
-			DownSubDownloadCap:    dynamoDbResponse.DownsubDownloadCap,
-			VideoLang: 		       dynamoDbResponse.VideoLang,
-		}, nil
+	// Check if it's processing in the store
+	if cachedData.Status != nil{
+		cachedData.Vid = videoID
+		cachedData.Lang = lang
+		return *cachedData, nil
 	}
 
 	log.Println("ℹ️ No valid cached data found.")
@@ -1115,10 +741,10 @@ func processingQueueVideoGetMetadata(params MetadataParams) (
 			var metadata = videoQueue.GetVideoMeta(params.VideoID, params.Language)
 			metadata.Vid = params.VideoID
 			metadata.Lang = params.Language
-			if err := pushMetadataToDynamoDB(*metadata); err != nil {
-				log.Printf("❌ Failed to push metadata to DynamoDB: %v", err)
+			if err := dataStore.PutMetadata(*metadata); err != nil {
+				log.Printf("❌ Failed to push metadata to store: %v", err)
 			} else {
-				log.Printf("✅ Pushed expired metadata from %s to DynamoDB", params.VideoID)
+				log.Printf("✅ Pushed expired metadata from %s to store", params.VideoID)
 			}
 		}()
 		return nil, nil, fmt.Errorf("ttlMetadata < 1")
@@ -1148,19 +774,17 @@ func processingQueueVideoGetMetadata(params MetadataParams) (
 
 	videoProcessingMetadataDTO.Metadata = videoMetadata
 
+	// without a DownSub link the other caption providers are tried
 	if len(fetchMetadataResponse.Captions) == 0 {
-		log.Printf("❌ No captions found for video %s", params.VideoID)
-		time.Sleep(1 * time.Second)
-		return nil, nil, fmt.Errorf("no captions found")
+		log.Printf("⚠️ No DownSub caption found for video %s", params.VideoID)
+	} else {
+		videoProcessingMetadataDTO.Metadata.DownSubDownloadCap = fetchMetadataResponse.Captions[0].BaseURL
 	}
 
-	downSubUrl := fetchMetadataResponse.Captions[0].BaseURL
-	videoProcessingMetadataDTO.Metadata.DownSubDownloadCap = downSubUrl
-
-	path := convertTitleToURL(videoMetadata.Title[params.Language])
 	if videoProcessingMetadataDTO.Metadata.Path == nil {
 		videoProcessingMetadataDTO.Metadata.Path = make(map[string]string)
 	}
+	path := localizedPath(videoProcessingMetadataDTO.Metadata.Path, params.VideoID, params.Language, videoMetadata.Title[params.Language])
 	videoProcessingMetadataDTO.Metadata.Path[params.Language] = path
 
 	log.Println("🔄 Update metadata on videoProcessing:", params.VideoID)
@@ -1178,8 +802,8 @@ func processingQueueVideoGetMetadata(params MetadataParams) (
 			println("🔄 [2] printing category:", videoMetadata.Category)
 		}
 
-		if err := pushMetadataToDynamoDB(*metadata); err != nil {
-			log.Printf("❌ Failed to push metadata to DynamoDB: %v", err)
+		if err := dataStore.PutMetadata(*metadata); err != nil {
+			log.Printf("❌ Failed to push metadata to store: %v", err)
 		}
 	}()
 
@@ -1192,13 +816,18 @@ func processingQueueVideoDownloadCaps(videoId string,language string, videoProce
 			
 	// Download Caps
 	log.Println("⏳ => Download Caps", videoId)
-	downloadUrl := metadata.DownSubDownloadCap
-	subtitle, err := downloadSubtitleByDownSub(downloadUrl)
+	subtitle, provider, err := captionProviders.Fetch(CaptionRequest{
+		VideoID:    videoId,
+		Languages:  captionLanguages(metadata.VideoLang, language),
+		DownSubURL: metadata.DownSubDownloadCap,
+	})
 	if (err != nil) {
 		log.Printf("❌ Failed to download subtitle: %v", err)
 		time.Sleep(1 * time.Second)
 		return videoProcessingMetadataDTO
 	}
+	log.Printf("✅ Caption of %s downloaded from %s", videoId, provider)
+	subtitle = normalizeCaption(subtitle)
 	
 	videoProcessingMetadataDTO = videostate.ProcessingVideo{
 		VideoID: videoId,
@@ -1214,8 +843,8 @@ func processingQueueVideoDownloadCaps(videoId string,language string, videoProce
 
 	go func(){
 		subtitleKey := videoId + "-caption.txt"
-		if err := uploadToS3(subtitle, subtitleKey); err != nil {
-			log.Printf("Error uploading subtitle to S3: %v\n", err)
+		if err := dataStore.PutObject(subtitleKey, subtitle); err != nil {
+			log.Printf("Error uploading subtitle to store: %v\n", err)
 		}
 	}()
 
@@ -1229,22 +858,17 @@ func processingQueueVideoSummarize(videoId string, language string, videoProcess
 	if (subtitle == "") {
 		log.Println("NO SUbtitle found")
 		subtitleKey := videoId + "-caption.txt"
-		subtitle, _ = fetchS3(subtitleKey)
+		subtitle, _ = dataStore.GetObject(subtitleKey)
 	}
 
-	// set the same title for other languages
-	// here is where we can translate the title so the path would be translated as well.
-	title := firstNonEmptyFromMap(metadata.Title)
-	title = sanitizeTitle(title)
-	metadata.Title[language] = title
-
-	var path = convertTitleToURL(metadata.Title[language])
-	if metadata.Path == nil {
-		metadata.Path = make(map[string]string)
+	// the video title is sent to the model, which answers with the title in the language
+	title := metadata.Title[language]
+	if title == "" {
+		title = firstNonEmptyFromMap(metadata.Title)
 	}
-	metadata.Path[language] = path
+	title = sanitizeTitle(title)
 
-	prompt, err := summarizeText(subtitle, language, metadata.Title[language])
+	prompt, err := summarizeText(subtitle, language, title)
 	println("summarizeText prompt; ", prompt)
 	if err != nil {
 		log.Printf("error summarizing caption: %v", err)
@@ -1257,6 +881,19 @@ func processingQueueVideoSummarize(videoId string, language string, videoProcess
 		time.Sleep(1 * time.Second)
 		return videoProcessingMetadataDTO
 	}
+	summaryJson.Content = checkSummaryTimestamps(videoId, language, summaryJson.Content, subtitle, metadata.Duration)
+	if localizedTitle := sanitizeTitle(summaryJson.Title); localizedTitle != "" {
+		title = localizedTitle
+	}
+	if metadata.Title == nil {
+		metadata.Title = make(map[string]string)
+	}
+	metadata.Title[language] = title
+	if metadata.Path == nil {
+		metadata.Path = make(map[string]string)
+	}
+	metadata.Path[language] = localizedPath(metadata.Path, videoId, language, title)
+
 	if metadata.Answer== nil {
 		metadata.Answer = make(map[string]string)
 	}
@@ -1275,20 +912,24 @@ func processingQueueVideoSummarize(videoId string, language string, videoProcess
 	
 	// videoQueue.SetRetrySummaryStatus(videoId, language, false) // this prevent looping when it's on retrying
 
-	go func(){
-		var metadata = videoQueue.GetVideoMeta(videoId, language)
-		if err := pushMetadataToDynamoDB(*metadata); err != nil {
-			log.Printf("❌ Failed to push metadata to DynamoDB: %v", err)
-		}else{
-			if err := pushCategoryStatsToDynamoDB(*metadata, language); err != nil{
-				log.Printf("❌ Failed to push category to DynamoDB: %v", err)
-			}
-		}
-	}()
+	go storeCompletedSummary(videoId, language)
 
 	return videoProcessingMetadataDTO
 }
 
+// storeCompletedSummary indexes the summary of the queue and pushes it to the store
+func storeCompletedSummary(videoId string, language string) {
+	var metadata = videoQueue.GetVideoMeta(videoId, language)
+	indexSummary(videoId, language, *metadata)
+	if err := dataStore.PutMetadata(*metadata); err != nil {
+		log.Printf("❌ Failed to push metadata to store: %v", err)
+	}else{
+		if err := dataStore.PutCategoryStats(*metadata, language); err != nil{
+			log.Printf("❌ Failed to push category to store: %v", err)
+		}
+	}
+}
+
 
 func processingVideoQueue(videoId string, language string) {
 	println("processingVideoQueue")
@@ -1312,6 +953,12 @@ func processingVideoQueue(videoId string, language string) {
 			break
 		}
 
+		// running jobs are never evicted, a job that keeps failing must end in an error status
+		if ttl < 1 {
+			failProcessing(videoId, language)
+			break
+		}
+
 		//Error Handler
 		if strings.Contains(strings.ToLower(string(videoQueue.GetStatus(videoId, language))), "error") {
 			var metadata = videoQueue.GetVideoMeta(videoId, language)
@@ -1354,6 +1001,7 @@ func processingVideoQueue(videoId string, language string) {
 			metadataDynamoResponse, fetchMetadataResponse, err = processingQueueVideoGetMetadata(params)
 
 			if err != nil {
+				failProcessing(videoId, language)
 				break;
 			}
 		}
@@ -1370,6 +1018,7 @@ func processingVideoQueue(videoId string, language string) {
 		println(">>>>>>>> BEFORE Summarize")
 		if (videoQueue.GetStatus(videoId, language) == videostate.StatusDownloadProcessed){
 			processingQueueVideoSummarize(videoId, language, videoProcessingMetadataDTO )
+			failProcessing(videoId, language)
 			break
 		}
 		// Check the status
@@ -1380,6 +1029,26 @@ func processingVideoQueue(videoId string, language string) {
 	}
 }
 
+// failProcessing gives up on a job that did not complete, unless it already failed with a
+// more precise error. Its TTL then starts and the next request after it runs the job again.
+func failProcessing(videoId string, language string) {
+	status := videoQueue.GetStatus(videoId, language)
+	if status == "" || status.IsFinal() {
+		return
+	}
+	log.Printf("❌ Processing of %s (%s) failed in status %s", videoId, language, status)
+	videoQueue.SetStatus(videoId, language, videostate.StatusProcessingFailed)
+}
+
+// resumeInterruptedJobs restarts the jobs that were mid-pipeline when the server stopped.
+// processingVideoQueue picks each one up from its last completed stage.
+func resumeInterruptedJobs() {
+	for _, video := range videoQueue.Resumable() {
+		log.Printf("🔁 Resuming %s (%s) from status %s", video.VideoID, video.Language, video.Status)
+		go processingVideoQueue(video.VideoID, video.Language)
+	}
+}
+
 func dump_print_all_videos(){
 	println("⌛ [1] Printing all videos from videoQueue.Videos()")
 	for _, video := range videoQueue.Videos() {
@@ -1413,6 +1082,7 @@ func convertMultilingualToSingleLingual(multilingual *HandleSummaryRequestRespon
 		Category:           multilingual.Category,
 		VideoLang:          multilingual.VideoLang,
 		LikeCount:          multilingual.LikeCount,
+		ViewCount:          multilingual.ViewCount,
 		CanBeRetried:		multilingual.CanBeRetried[language],
 	}
 }
@@ -1448,13 +1118,24 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
 	println("dump_print_all_videos 1")
 	
     var requestBody struct {
-        VideoID  string `json:"videoId"`
-        Language string `json:"language"`
+        VideoID     string `json:"videoId"`
+        Language    string `json:"language"`
+        CallbackURL string `json:"callback_url"`
     }
     if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
         http.Error(w, "Invalid request body", http.StatusBadRequest)
         return
     }
+    if requestBody.CallbackURL != "" {
+        if !summaryWebhooks.Enabled() {
+            http.Error(w, "Webhooks are disabled", http.StatusServiceUnavailable)
+            return
+        }
+        if err := webhooks.ValidateURL(requestBody.CallbackURL); err != nil {
+            http.Error(w, err.Error(), http.StatusBadRequest)
+            return
+        }
+    }
 
 	lang := requestBody.Language
     videoURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", requestBody.VideoID)
@@ -1465,12 +1146,71 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
         return
     }
 
+	// Clients poll this endpoint until the summary is ready, only the request that
+	// enqueues the job counts as a visit
+	enqueued := !videoQueue.Exists(videoID, lang)
+	canBeRetried := enqueueSummary(videoID, lang, fragmentType, retrySummaryUrlQuery)
+	callbackURL := requestBody.CallbackURL
+	if key, ok := apiKeyFromContext(r.Context()); ok && callbackURL == "" {
+		callbackURL = key.CallbackURL
+	}
+	if callbackURL != "" {
+		registerSummaryWebhook(videoID, lang, callbackURL)
+	}
+	if user, _ := currentUser(r); user != nil && enqueued {
+		recordLibraryHistory(user.ID, videoID, lang)
+	}
+	
+	currentMetadata := videoQueue.GetVideoMeta(videoID, lang)
+	singleLangResponse := buildSummaryResponse(videoID, lang, currentMetadata, canBeRetried)
+
+	log.Printf("Processing videoID=%s,", videoID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(singleLangResponse)
+	return
+    
+}
+
+// handleGetSummary returns the summary of a video in a language as it is, from the queue
+// or the store, without enqueueing anything. 404 when the video was never summarized.
+// GET /summary/{videoId}/{lang}
+func handleGetSummary(w http.ResponseWriter, r *http.Request) {
+	videoID, err := extractVideoID("https://www.youtube.com/watch?v=" + r.PathValue("videoId"))
+	if err != nil {
+		http.Error(w, "Invalid videoId", http.StatusBadRequest)
+		return
+	}
+	lang := r.PathValue("lang")
+
+	var response *HandleSummarySingleLanguageRequestResponse
+	if videoQueue.Exists(videoID, lang) {
+		response = buildSummaryResponse(videoID, lang, videoQueue.GetVideoMeta(videoID, lang), videoQueue.CanBeRetried(videoID, lang))
+	} else {
+		metadata, err := loadContentWhenItsCached(videoID, lang)
+		if err != nil {
+			http.Error(w, "Failed to read the summary", http.StatusInternalServerError)
+			return
+		}
+		if metadata.Vid == "" || metadata.Status[lang] == "" {
+			http.Error(w, "Summary not found", http.StatusNotFound)
+			return
+		}
+		response = buildSummaryResponse(videoID, lang, &metadata, false)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// enqueueSummary adds a video to the processing queue (reusing the stored summary when there is one)
+// and starts processingVideoQueue for it. It returns whether the job can be retried.
+func enqueueSummary(videoID string, lang string, fragmentType string, retry bool) bool {
 	videoQueue.SetPipeline(videoID, lang, fragmentType)
 	
 	isVideoBeingProcessed := videoQueue.Exists(videoID, lang)
 
 	println("isVideoProcessing", isVideoBeingProcessed)
-	println("retrySummaryUrlQuery", retrySummaryUrlQuery)
+	println("retry", retry)
 
 	if ( !isVideoBeingProcessed ) {
 		//dump_print_all_videos()
@@ -1489,9 +1229,11 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
 		println("content.Vid and status = ",content.Vid, content.Status, content.Path)
 
 		// check if answer or summary does not exist in DynamoDb for this language
+		translateFrom := ""
 		if (content.Answer[lang] == "" && content.Summary[lang] == "" ) {
-			// deleteVideoFromDynamoDB(videoID)
-			println("❌ Answer or Summary missing in DynamoDB for language:", lang)
+			// dataStore.DeleteVideo(videoID)
+			println("❌ Answer or Summary missing in store for language:", lang)
+			translateFrom = translationSource(content, lang)
 			content.Vid = ""
 		}
 
@@ -1519,6 +1261,7 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
 				ArticleUploadDateTime: content.ArticleUploadDateTime,
 				Duration:              content.Duration, 
 				LikeCount: 			   content.LikeCount,
+				ViewCount: 			   content.ViewCount,
 				DownSubDownloadCap:    content.DownSubDownloadCap,
 			}
 
@@ -1531,8 +1274,8 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
 			videoQueue.SetStatus(videoID, lang, videostate.VideoStatus(metadata.Status[lang]))
 
 			canBeRetried := videoQueue.CanBeRetried(videoID, lang)
-			if retrySummaryUrlQuery  && canBeRetried  {
-				videoQueue.SetRetrySummaryStatus(videoID, lang, retrySummaryUrlQuery)
+			if retry  && canBeRetried  {
+				videoQueue.SetRetrySummaryStatus(videoID, lang, retry)
 				videoQueue.SetStatus(videoID, lang, videostate.StatusPending)
 			}
 			
@@ -1542,6 +1285,10 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
 				processingVideoQueue(videoID, lang)
 			}()
 			
+		} else if translateFrom != "" {
+			log.Printf("🌐 Translating %s summary of %s into %s", translateFrom, videoID, lang)
+			content.Vid = videoID
+			go processingQueueVideoTranslate(videoID, lang, translateFrom, content)
 		} else {
 			println("Processing video async 2")
 			go func(){
@@ -1553,20 +1300,26 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	canBeRetried := videoQueue.CanBeRetried(videoID, lang)
-	multilingualCanBeRetried := map[string]bool{
-		lang: canBeRetried,
-	}
 	println("canBeRetried", canBeRetried)
 	// Handle retry requests
-	if ( retrySummaryUrlQuery && canBeRetried ) {
+	if ( retry && canBeRetried ) {
 		println("PROCESS ON RETRY")
 		videoQueue.SetStatus(videoID, lang, videostate.StatusPending)
 		videoQueue.SetRetrySummaryStatus(videoID, lang, true)
 	}
-	
-	currentMetadata := videoQueue.GetVideoMeta(videoID, lang)
-	
-	// Return here
+
+	return canBeRetried
+}
+
+// buildSummaryResponse converts the queue metadata of a video into the single language API response.
+func buildSummaryResponse(videoID string, lang string, currentMetadata *videostate.Metadata, canBeRetried bool) *HandleSummarySingleLanguageRequestResponse {
+	if currentMetadata == nil {
+		currentMetadata = &videostate.Metadata{}
+	}
+	multilingualCanBeRetried := map[string]bool{
+		lang: canBeRetried,
+	}
+
 	response := HandleSummaryRequestResponse{
 		VideoID:     videoID,
 		Title:       currentMetadata.Title,
@@ -1581,18 +1334,13 @@ func handleSummaryRequest(w http.ResponseWriter, r *http.Request) {
 		Duration:    currentMetadata.Duration,
 		Category:    currentMetadata.Category,
 		LikeCount:   currentMetadata.LikeCount,
+		ViewCount:   currentMetadata.ViewCount,
 		Content:	 currentMetadata.Summary,
 		Answer:		 currentMetadata.Answer,
 		CanBeRetried: multilingualCanBeRetried,
 	}
 
-	singleLangResponse := convertMultilingualToSingleLingual(&response, lang)
-
-	log.Printf("Processing videoID=%s,", videoID)
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(singleLangResponse)
-	return
-    
+	return convertMultilingualToSingleLingual(&response, lang)
 }
 
 func blockingRetry(
@@ -1677,6 +1425,7 @@ func convertHandleSummaryRequestResponseToVideoStateMetadata(metadata *HandleSum
 		ArticleUploadDateTime: metadata.ArticleUploadDateTime,
 		Duration:              metadata.Duration,
 		LikeCount: 			   metadata.LikeCount,
+		ViewCount: 			   metadata.ViewCount,
 		VideoLang: 			   metadata.VideoLang,
 	}
 }
@@ -1710,10 +1459,20 @@ func runMetadataAndCapsFetcherAsync(videoURL string, lang string) (*HandleSummar
 	}
 
 
-	likeCountInt, err := strconv.Atoi(metadata.ViewCount)
+	viewCountInt, err := strconv.Atoi(metadata.ViewCount)
 	if err != nil {
-		log.Printf("⚠️ Failed to convert like count to int: %v", err)
-		likeCountInt = 0
+		log.Printf("⚠️ Failed to convert view count to int: %v", err)
+		viewCountInt = 0
+	}
+
+	// DownSub does not know the likes of a video
+	likeCountInt := 0
+	if metadata.LikeCount != "" {
+		likeCountInt, err = strconv.Atoi(metadata.LikeCount)
+		if err != nil {
+			log.Printf("⚠️ Failed to convert like count to int: %v", err)
+			likeCountInt = 0
+		}
 	}
 
 	var title map[string]string 
@@ -1734,32 +1493,36 @@ func runMetadataAndCapsFetcherAsync(videoURL string, lang string) (*HandleSummar
 		Category:    metadata.Category,
 		VideoLang:   captionLang,
 		LikeCount:   likeCountInt,
+		ViewCount:   viewCountInt,
 	}
 
 	// path := convertTitleToURL(metadata.Title)
 	// if err := pushSummaryToDynamoDB(*metadataResponse, "", path); err != nil {
-	// 	log.Printf("❌ Failed to push metadata to DynamoDB: %v", err)
+	// 	log.Printf("❌ Failed to push metadata to store: %v", err)
 	// 	return nil, nil, err
 	// }
 
 	return metadataResponse, metadata, nil
 }
 
-func summarizeSubtitle(prompt string) (*VideoGPTSummary, error) {
-	sanitizedSummary, err := parseFields(prompt)
-	if err != nil {
-		return nil, fmt.Errorf("error sanitizing summary JSON: %v", err)
-	}
 
-	return &sanitizedSummary, nil
-}
+const maxCategoryPageSize = 50
 
+type CategoryVideosResponse struct {
+	Videos     []HandleSummarySingleLanguageRequestResponse `json:"videos"`
+	NextCursor string                                       `json:"next_cursor,omitempty"`
+}
 
+// GET /summary/category?category=Music&lang=en&min_views=0&limit=10&cursor=...
+// min_likes is the former name of min_views, the listing always counted views
 func handleCategorySummaryRequest(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	category := r.URL.Query().Get("category")
 	lang := r.URL.Query().Get("lang")
-	minLikesStr := r.URL.Query().Get("min_likes")
+	minViewsStr := r.URL.Query().Get("min_views")
+	if minViewsStr == "" {
+		minViewsStr = r.URL.Query().Get("min_likes")
+	}
 	limitStr := r.URL.Query().Get("limit")
 
 	if category == "" || lang == "" {
@@ -1768,39 +1531,44 @@ func handleCategorySummaryRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Defaults
-	minLikes := 0
+	minViews := 0
 	limit := 10
 
-	if minLikesStr != "" {
-		if val, err := strconv.Atoi(minLikesStr); err == nil {
-			minLikes = val
+	if minViewsStr != "" {
+		if val, err := strconv.Atoi(minViewsStr); err == nil {
+			minViews = val
 		}
 	}
 	if limitStr != "" {
-		if val, err := strconv.Atoi(limitStr); err == nil {
-			limit = val
+		if val, err := strconv.Atoi(limitStr); err == nil && val > 0 {
+			limit = min(val, maxCategoryPageSize)
 		}
 	}
 
-	// Query DynamoDB
-	items, err := getLatestVideosByCategoryFromDynamoDB(lang, category, minLikes, limit)
+	// Query the store
+	items, nextCursor, err := dataStore.LatestVideosByCategory(lang, category, minViews, limit, r.URL.Query().Get("cursor"))
+	if errors.Is(err, store.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		log.Printf("Error fetching videos by category: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	// loop thought items
+
 	// convert items to HandleSummaryRequestResponse
-	
-	// Initialize the slice with the required length
-	var output = make([]HandleSummarySingleLanguageRequestResponse, len(items))
+	response := CategoryVideosResponse{
+		Videos:     make([]HandleSummarySingleLanguageRequestResponse, len(items)),
+		NextCursor: nextCursor,
+	}
 	for i, item := range items {
-		output[i] = convertMetadataToHandleSummaryRequestResponse(item, lang)
+		response.Videos[i] = convertMetadataToHandleSummaryRequestResponse(item, lang)
 	}
 
 	// Respond with JSON
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(output)
+	json.NewEncoder(w).Encode(response)
 }
 
 // Convert videostate.Metadata to HandleSummaryRequestResponse
@@ -1820,6 +1588,7 @@ func convertMetadataToHandleSummaryRequestResponse(metadata videostate.Metadata,
 		Duration:    metadata.Duration,
 		Category:    metadata.Category,
 		LikeCount:   metadata.LikeCount,
+		ViewCount:   metadata.ViewCount,
 		Content:     metadata.Summary[lang],
 		Answer:      metadata.Answer[lang],
 	}
@@ -1841,37 +1610,6 @@ func convertMetadataToHandleSummaryRequestResponse(metadata videostate.Metadata,
 // 	ArticleUploadDateTime string `json:"article_update_datetime,omitempty"`
 // 	Duration string `json:"duration,omitempty"`
 // }
-// Handle Google OAuth2 redirect
-func handleGoogleRedirect(w http.ResponseWriter, r *http.Request) {
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		http.Error(w, "Authorization code not found", http.StatusBadRequest)
-		return
-	}
-
-	// Exchange the authorization code for an access token
-	token, err := googleOAuthConfig.Exchange(context.Background(), code)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to exchange token: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	// Save the access token to a file
-	err = ioutil.WriteFile(".access-token", []byte(token.AccessToken), 0600)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to save access token: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	fmt.Fprintf(w, "Successfully authenticated and saved access token to .access-token")
-}
-
-// Start Google OAuth2 flow
-func handleGoogleLogin(w http.ResponseWriter, r *http.Request) {
-	url := googleOAuthConfig.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
-}
-
 func enableCORS(next http.HandlerFunc) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         // Set CORS headers
@@ -1892,26 +1630,71 @@ func enableCORS(next http.HandlerFunc) http.HandlerFunc {
     }
 }
 
+func newRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/summary/", handleSummaryRequest)
+	mux.HandleFunc("/summary", handleSummaryRequest)
+	mux.HandleFunc("GET /summary/{videoId}/{lang}", handleGetSummary)
+	mux.HandleFunc("GET /summary/{videoId}/{lang}/events", handleSummaryEvents)
+	mux.HandleFunc("GET /summary/{videoId}/{lang}/timestamps", handleTimestampReport)
+	mux.HandleFunc("POST /summary/batch", handleCreateSummaryBatch)
+	mux.HandleFunc("GET /summary/batch/{batchId}", handleGetSummaryBatch)
+	mux.HandleFunc("POST /summary/playlist", handleCreatePlaylistSummary)
+	mux.HandleFunc("GET /summary/playlist/{playlistId}", handleGetPlaylistSummary)
+	mux.HandleFunc("/redirects", handleGoogleRedirect)
+	mux.HandleFunc("/summary/category", handleCategorySummaryRequest) // New endpoint
+	mux.HandleFunc("GET /summary/channel/{channelId}", handleChannelSummaryRequest)
+	mux.HandleFunc("GET /search", handleSearch)
+	mux.HandleFunc("POST /video/{videoId}/ask", handleAskVideo)
+	mux.HandleFunc("GET /transcript/{videoId}", handleTranscript)
+	mux.HandleFunc("GET /webhooks/deliveries", requireAdmin(handleWebhookDeliveries))
+	mux.HandleFunc("POST /apikeys", requireAdmin(handleCreateAPIKey))
+	mux.HandleFunc("GET /apikeys", requireAdmin(handleListAPIKeys))
+	mux.HandleFunc("GET /captions/providers", requireAdmin(handleCaptionProviders))
+	mux.HandleFunc("DELETE /apikeys/{keyId}", requireAdmin(handleRevokeAPIKey))
+	mux.HandleFunc("/login", handleGoogleLogin)
+	mux.HandleFunc("/logout", handleLogout)
+	mux.HandleFunc("GET /me", handleGetMe)
+	mux.HandleFunc("PATCH /me", handleUpdateMe)
+	mux.HandleFunc("GET /library", handleListLibrary)
+	mux.HandleFunc("PUT /library/{videoId}/{lang}", handleUpdateLibraryItem)
+	mux.HandleFunc("DELETE /library/{videoId}/{lang}", handleDeleteLibraryItem)
+	return mux
+}
+
 func main() {
 	  // Create a new CORS handler
 	  c := cors.New(cors.Options{
-        AllowedOrigins:   []string{"*"}, // Adjust this to your frontend URL(s)
-        AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-        AllowedHeaders:   []string{"Content-Type", "Authorization"},
+        AllowedOrigins:   corsAllowedOrigins(), // CORS_ALLOWED_ORIGINS, sessions need explicit origins
+        AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+        AllowedHeaders:   []string{"Content-Type", "Authorization", apiKeyHeader},
+        ExposedHeaders:   []string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"},
         AllowCredentials: true,
         Debug:           true, // Set to false in production
     })
 
-    // Create your router
-    mux := http.NewServeMux()
-	mux.HandleFunc("/summary/", handleSummaryRequest)
-    mux.HandleFunc("/summary", handleSummaryRequest)
-    mux.HandleFunc("/redirects", handleGoogleRedirect)
-	mux.HandleFunc("/summary/category", handleCategorySummaryRequest) // New endpoint
-    mux.HandleFunc("/login", handleGoogleLogin)
+	var err error
+	videoQueue, err = openVideoQueue()
+	if err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	apiKeys, err = apikeys.NewManager(dataStore, apiKeysObject)
+	if err != nil {
+		log.Fatalf("unable to load API keys, %v\n", err)
+	}
+
+    // Wrap your router with the CORS handler, API keys and rate limits
+    handler := c.Handler(withAPIAccess(newRouter()))
 
-    // Wrap your router with the CORS handler
-    handler := c.Handler(mux)
+	if !summaryWebhooks.Enabled() {
+		log.Printf("⚠️ WEBHOOK_SIGNING_SECRET is not set, webhooks are disabled")
+	}
+	resumeInterruptedJobs()
+	go func() {
+		if err := rebuildSearchIndex(); err != nil {
+			log.Printf("❌ %v", err)
+		}
+	}()
 
     fmt.Println("Server started at :8080 test")
     log.Fatal(http.ListenAndServe(":8080", handler))
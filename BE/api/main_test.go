@@ -1,20 +1,60 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
+
+	"my_lambda_app/apikeys"
+	"my_lambda_app/store"
+	"my_lambda_app/videostate"
 )
 
-func TestExtractLastTimestamp(t *testing.T) {
+// Tests run against an in-memory queue and a throwaway local store so they never touch
+// the on-disk journal or AWS.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "sumtube-api-test")
+	if err != nil {
+		log.Fatal(err)
+	}
+	dataStore, err = store.NewLocalStore(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	apiKeys, err = apikeys.NewManager(dataStore, apiKeysObject)
+	if err != nil {
+		log.Fatal(err)
+	}
+	resetVideoQueue()
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func resetVideoQueue() {
+	videoQueue = videostate.NewProcessor()
+	videoQueue.OnStatusChange(publishSummaryStatus)
+	videoQueue.OnStatusChange(notifySummaryWebhooks)
+	videoQueue.OnStatusChange(recordSummaryFailure)
+}
+
+func TestCaptionDuration(t *testing.T) {
 	testCases := []struct {
 		name     string
 		input    string
-		expected string
+		expected time.Duration
 		wantErr  bool
 	}{
 		{
-			name: "Single timestamp line",
-			input: `0:18:32,000 --> 0:18:38,000`,
-			expected: "0:18:38",
+			name: "Single cue",
+			input: `0:18:32,000 --> 0:18:38,000
+Only line`,
+			expected: 18*time.Minute + 38*time.Second,
 			wantErr:  false,
 		},
 		{
@@ -34,7 +74,7 @@ Test
 4
 0:00:15,000 --> 0:00:20,000
 Final line`,
-			expected: "0:00:20",
+			expected: 20 * time.Second,
 			wantErr:  false,
 		},
 		{
@@ -54,20 +94,20 @@ Another text
 457
 0:18:32,000 --> 0:18:38,000
 End line`,
-			expected: "0:18:38",
+			expected: 18*time.Minute + 38*time.Second,
 			wantErr:  false,
 		},
 		{
 			name:    "No timestamps",
 			input:   `This is just text without timestamps.`,
-			expected: "",
+			expected: 0,
 			wantErr: true,
 		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := ExtractLastTimestamp(tc.input)
+			result, err := captionDuration(tc.input)
 
 			if (err != nil) != tc.wantErr {
 				t.Errorf("Expected error: %v, got error: %v", tc.wantErr, err)
@@ -75,7 +115,43 @@ End line`,
 			}
 
 			if result != tc.expected {
-				t.Errorf("Expected result: %s, got: %s", tc.expected, result)
+				t.Errorf("Expected result: %v, got: %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRunMetadataAndCapsFetcherAsyncCounts(t *testing.T) {
+	metadata := map[string]string{
+		"likedVideo1": `{"title":"Liked","view_count":"1000","like_count":"42","length_seconds":"60","category":"Music","captions":[{"base_url":"u","lang":"en"}]}`,
+		"downsubVid1": `{"title":"From DownSub","view_count":"1000","length_seconds":"60","category":"Music","captions":[]}`,
+	}
+	metadataServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, metadata[r.URL.Query().Get("vid")])
+	}))
+	defer metadataServer.Close()
+	defaultURL := videoMetadataURL
+	videoMetadataURL = metadataServer.URL
+	defer func() { videoMetadataURL = defaultURL }()
+
+	testCases := []struct {
+		videoID   string
+		likeCount int
+		viewCount int
+	}{
+		{"likedVideo1", 42, 1000},
+		// DownSub does not return likes, the views must not stand in for them
+		{"downsubVid1", 0, 1000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.videoID, func(t *testing.T) {
+			response, _, err := runMetadataAndCapsFetcherAsync("https://www.youtube.com/watch?v="+tc.videoID, "en")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if response.LikeCount != tc.likeCount || response.ViewCount != tc.viewCount {
+				t.Errorf("Expected %d likes and %d views, got %d and %d", tc.likeCount, tc.viewCount, response.LikeCount, response.ViewCount)
 			}
 		})
 	}
@@ -123,3 +199,66 @@ func TestExtractVideoID(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalizedPath(t *testing.T) {
+	paths := map[string]string{"en": "learn-go", "ru": "izuchit-go"}
+
+	if got := localizedPath(paths, "dQw4w9WgXcQ", "pt", "Aprenda Go"); got != "aprenda-go" {
+		t.Errorf("localizedPath(pt) = %q", got)
+	}
+	if got := localizedPath(paths, "dQw4w9WgXcQ", "ja", "ゴ言語入門"); got != "go-gengo-nyuumon" {
+		t.Errorf("localizedPath(ja) = %q", got)
+	}
+	if got := localizedPath(paths, "dQw4w9WgXcQ", "zh", "学习 Go"); got != "xue-xi-go" {
+		t.Errorf("localizedPath(zh) = %q", got)
+	}
+	// an untranslated title must not share the path of another language
+	if got := localizedPath(paths, "dQw4w9WgXcQ", "es", "Learn Go"); got != "learn-go-es" {
+		t.Errorf("localizedPath(es) = %q, want a suffixed slug", got)
+	}
+	if got := localizedPath(paths, "dQw4w9WgXcQ", "en", "Learn Go"); got != "learn-go" {
+		t.Errorf("localizedPath(en) = %q, want its own slug", got)
+	}
+	// a title without letters falls back to the video ID, still distinct per language
+	if got := localizedPath(paths, "dQw4w9WgXcQ", "fr", "?!"); got != "video-dQw4w9WgXcQ" {
+		t.Errorf("localizedPath(fr) = %q, want the video ID fallback", got)
+	}
+	paths["fr"] = "video-dQw4w9WgXcQ"
+	if got := localizedPath(paths, "dQw4w9WgXcQ", "de", ""); got != "video-dQw4w9WgXcQ-de" {
+		t.Errorf("localizedPath(de) = %q, want a suffixed fallback", got)
+	}
+}
+
+func TestHandleGetSummary(t *testing.T) {
+	resetVideoQueue()
+	dataStore.PutMetadata(videostate.Metadata{
+		Vid:     "getSumVid01",
+		Title:   map[string]string{"en": "Stored summary"},
+		Summary: map[string]string{"en": "Done"},
+		Path:    map[string]string{"en": "stored-summary"},
+		Status:  map[string]string{"en": string(videostate.StatusSummarizeProcessed)},
+	})
+
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/summary/getSumVid01/en", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response HandleSummarySingleLanguageRequestResponse
+	json.NewDecoder(rec.Body).Decode(&response)
+	if response.Content != "Done" || response.Status != string(videostate.StatusSummarizeProcessed) || response.Path != "stored-summary" {
+		t.Errorf("Unexpected summary %+v", response)
+	}
+
+	// Reading never enqueues, neither a summarized video nor an unknown one
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/summary/getSumVid02/en", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a video never summarized, got %d", rec.Code)
+	}
+	for _, videoID := range []string{"getSumVid01", "getSumVid02"} {
+		if videoQueue.Exists(videoID, "en") {
+			t.Errorf("Expected %s not to be enqueued", videoID)
+		}
+	}
+}
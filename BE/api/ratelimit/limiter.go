@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket refilled at Rate tokens per second, holding at most Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// refill is how long the bucket takes to fill up again under its own limit
+	refill time.Duration
+}
+
+// refillTime is how long an empty bucket takes to fill up, zero when it never refills.
+func (limit Limit) refillTime() time.Duration {
+	if limit.Rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+}
+
+// Limiter keeps one token bucket per client key (an API key ID or an IP address).
+type Limiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key. When the bucket is empty it reports how long
+// until the next token, along with the tokens left.
+func (l *Limiter) Allow(key string, limit Limit) (allowed bool, remaining int, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.last).Seconds()*limit.Rate)
+	b.last = now
+	b.refill = limit.refillTime()
+
+	if b.tokens < 1 {
+		if limit.Rate <= 0 {
+			return false, 0, time.Hour
+		}
+		wait := time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second))
+		return false, 0, wait
+	}
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+// sweep drops, at most once a minute, the buckets idle long enough to be full again under
+// their own limit, so the map does not grow with every IP seen. Buckets that never refill
+// are kept.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.refill > 0 && now.Sub(b.last) > b.refill {
+			delete(l.buckets, key)
+		}
+	}
+}
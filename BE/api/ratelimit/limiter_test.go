@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter()
+	l.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := l.Allow("client", limit); !ok {
+			t.Fatalf("Expected request %d to be allowed by the burst", i)
+		}
+	}
+	ok, remaining, retryAfter := l.Allow("client", limit)
+	if ok || remaining != 0 {
+		t.Fatalf("Expected the bucket to be empty")
+	}
+	if retryAfter != time.Second {
+		t.Errorf("Expected to retry after 1s, got %s", retryAfter)
+	}
+
+	if ok, _, _ := l.Allow("other", limit); !ok {
+		t.Errorf("Expected buckets to be independent per key")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if _, _, retryAfter := l.Allow("client", limit); retryAfter != 500*time.Millisecond {
+		t.Errorf("Expected to retry after 500ms, got %s", retryAfter)
+	}
+
+	now = now.Add(time.Second)
+	if ok, _, _ := l.Allow("client", limit); !ok {
+		t.Errorf("Expected a token to be refilled")
+	}
+}
+
+func TestLimiter_SweepsIdleBuckets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter()
+	l.now = func() time.Time { return now }
+	limit := Limit{Rate: 1, Burst: 5}
+
+	l.Allow("idle", limit)
+	now = now.Add(2 * time.Minute)
+	l.Allow("active", limit)
+
+	if _, ok := l.buckets["idle"]; ok {
+		t.Errorf("Expected the idle bucket to be dropped")
+	}
+	if _, ok := l.buckets["active"]; !ok {
+		t.Errorf("Expected the active bucket to be kept")
+	}
+}
+
+func TestLimiter_SweepUsesTheLimitOfEachBucket(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewLimiter()
+	l.now = func() time.Time { return now }
+	slow := Limit{Rate: 0.001, Burst: 30} // refills in 500 minutes
+	fast := Limit{Rate: 100, Burst: 10}   // refills in 100ms
+
+	for i := 0; i < 30; i++ {
+		l.Allow("ip:slow", slow)
+	}
+	l.Allow("key:fast", fast)
+
+	// a sweep run by the fast limit must not free the drained slow bucket
+	now = now.Add(2 * time.Minute)
+	l.Allow("key:fast", fast)
+	if ok, _, _ := l.Allow("ip:slow", slow); ok {
+		t.Fatalf("Expected the slow bucket to still be empty")
+	}
+
+	now = now.Add(10 * time.Hour)
+	l.Allow("key:fast", fast)
+	if _, ok := l.buckets["ip:slow"]; ok {
+		t.Errorf("Expected the slow bucket to be dropped once refilled")
+	}
+}
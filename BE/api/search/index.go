@@ -0,0 +1,200 @@
+package search
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Fields are weighted so a match in the title ranks above a match buried in the summary.
+const (
+	titleWeight   = 3
+	answerWeight  = 2
+	contentWeight = 1
+)
+
+// Document is one summary of a video in one language.
+type Document struct {
+	VideoID string
+	Lang    string
+	Title   string
+	Answer  string
+	Content string
+	Path    string
+}
+
+type Result struct {
+	Document
+	Score int
+}
+
+// Index is an in-process inverted index of the published summaries, one per language.
+// Queries match documents containing every term of the query.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]map[string]int // lang -> term -> videoId -> score
+	docs     map[string]map[string]Document       // lang -> videoId -> document
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]map[string]int),
+		docs:     make(map[string]map[string]Document),
+	}
+}
+
+// Add indexes a document, replacing the previous version of the same video and language.
+func (idx *Index) Add(doc Document) {
+	scores := make(map[string]int)
+	for _, field := range []struct {
+		text   string
+		weight int
+	}{
+		{doc.Title, titleWeight},
+		{doc.Answer, answerWeight},
+		{doc.Content, contentWeight},
+	} {
+		for _, term := range Tokenize(field.text) {
+			scores[term] += field.weight
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(doc.VideoID, doc.Lang)
+	if idx.docs[doc.Lang] == nil {
+		idx.docs[doc.Lang] = make(map[string]Document)
+		idx.postings[doc.Lang] = make(map[string]map[string]int)
+	}
+	idx.docs[doc.Lang][doc.VideoID] = doc
+	for term, score := range scores {
+		if idx.postings[doc.Lang][term] == nil {
+			idx.postings[doc.Lang][term] = make(map[string]int)
+		}
+		idx.postings[doc.Lang][term][doc.VideoID] = score
+	}
+}
+
+// Remove drops a video from the index of a language.
+func (idx *Index) Remove(videoID, lang string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(videoID, lang)
+}
+
+func (idx *Index) remove(videoID, lang string) {
+	if _, ok := idx.docs[lang][videoID]; !ok {
+		return
+	}
+	delete(idx.docs[lang], videoID)
+	for term, videos := range idx.postings[lang] {
+		delete(videos, videoID)
+		if len(videos) == 0 {
+			delete(idx.postings[lang], term)
+		}
+	}
+}
+
+// Len returns how many documents are indexed for a language.
+func (idx *Index) Len(lang string) int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs[lang])
+}
+
+// Search returns up to limit documents of lang matching every term of query, best score first.
+func (idx *Index) Search(lang, query string, limit int) []Result {
+	terms := Tokenize(query)
+	if len(terms) == 0 {
+		return []Result{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var matches map[string]int
+	for _, term := range terms {
+		videos := idx.postings[lang][term]
+		if len(videos) == 0 {
+			return []Result{}
+		}
+		if matches == nil {
+			matches = make(map[string]int, len(videos))
+			for vid, score := range videos {
+				matches[vid] = score
+			}
+			continue
+		}
+		for vid := range matches {
+			score, ok := videos[vid]
+			if !ok {
+				delete(matches, vid)
+				continue
+			}
+			matches[vid] += score
+		}
+	}
+
+	results := make([]Result, 0, len(matches))
+	for vid, score := range matches {
+		results = append(results, Result{Document: idx.docs[lang][vid], Score: score})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].VideoID < results[j].VideoID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Tokenize lowercases text and splits it into words. Scripts written without spaces
+// (Chinese, Japanese, Korean) are split into overlapping character bigrams instead.
+func Tokenize(text string) []string {
+	var terms []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		if len(cjk) == 1 {
+			terms = append(terms, string(cjk))
+		}
+		for i := 0; i+1 < len(cjk); i++ {
+			terms = append(terms, string(cjk[i:i+2]))
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+// The prolonged sound mark (ー) belongs to the Common script but is part of Katakana words.
+func isCJK(r rune) bool {
+	return r == 'ー' || unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
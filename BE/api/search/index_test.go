@@ -0,0 +1,71 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"Hello, World!", []string{"hello", "world"}},
+		{"Go 1.22 release", []string{"go", "1", "22", "release"}},
+		{"Introdução à programação", []string{"introdução", "à", "programação"}},
+		{"東京タワー", []string{"東京", "京タ", "タワ", "ワー"}},
+		{"AI 한국어", []string{"ai", "한국", "국어"}},
+		{"  ", nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{VideoID: "vid1", Lang: "en", Title: "Learning Go", Content: "Channels and goroutines"})
+	idx.Add(Document{VideoID: "vid2", Lang: "en", Title: "Cooking pasta", Answer: "Learning to cook", Content: "Go to the kitchen"})
+	idx.Add(Document{VideoID: "vid3", Lang: "pt", Title: "Aprendendo Go"})
+
+	results := idx.Search("en", "go", 10)
+	if len(results) != 2 || results[0].VideoID != "vid1" || results[1].VideoID != "vid2" {
+		t.Fatalf("Expected title match first, got %+v", results)
+	}
+
+	if results := idx.Search("en", "learning goroutines", 10); len(results) != 1 || results[0].VideoID != "vid1" {
+		t.Errorf("Expected every term to be required, got %+v", results)
+	}
+	if results := idx.Search("en", "go", 1); len(results) != 1 {
+		t.Errorf("Expected limit to be applied, got %d results", len(results))
+	}
+	if results := idx.Search("pt", "go", 10); len(results) != 1 || results[0].VideoID != "vid3" {
+		t.Errorf("Expected languages to be indexed separately, got %+v", results)
+	}
+	if results := idx.Search("en", "!!", 10); len(results) != 0 {
+		t.Errorf("Expected no results for an empty query, got %+v", results)
+	}
+}
+
+func TestIndex_AddReplacesAndRemove(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{VideoID: "vid1", Lang: "en", Title: "Old title"})
+	idx.Add(Document{VideoID: "vid1", Lang: "en", Title: "New title"})
+
+	if results := idx.Search("en", "old", 10); len(results) != 0 {
+		t.Errorf("Expected the old version to be replaced, got %+v", results)
+	}
+	if results := idx.Search("en", "new", 10); len(results) != 1 {
+		t.Errorf("Expected the new version to be indexed, got %+v", results)
+	}
+	if n := idx.Len("en"); n != 1 {
+		t.Errorf("Expected 1 document, got %d", n)
+	}
+
+	idx.Remove("vid1", "en")
+	if results := idx.Search("en", "title", 10); len(results) != 0 || idx.Len("en") != 0 {
+		t.Errorf("Expected the document to be removed, got %+v", results)
+	}
+}
@@ -0,0 +1,49 @@
+package slug
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/ikawaha/kagome-dict/ipa"
+	"github.com/ikawaha/kagome/v2/tokenizer"
+)
+
+// The IPA dictionary takes about 90MB once loaded, it is only loaded with the first
+// Japanese title.
+var (
+	japaneseOnce      sync.Once
+	japaneseTokenizer *tokenizer.Tokenizer
+)
+
+func loadJapaneseTokenizer() *tokenizer.Tokenizer {
+	japaneseOnce.Do(func() {
+		if t, err := tokenizer.New(ipa.Dict(), tokenizer.OmitBosEos()); err == nil {
+			japaneseTokenizer = t
+		}
+	})
+	return japaneseTokenizer
+}
+
+// japaneseReadings writes the words of a Japanese title apart, the ones with kanji replaced
+// by their katakana reading: "東京大学入門" -> "トウキョウダイガク ニュウモン". Words the
+// dictionary cannot read keep their kanji.
+func japaneseReadings(title string) string {
+	t := loadJapaneseTokenizer()
+	if t == nil {
+		return title
+	}
+	var words []string
+	for _, token := range t.Tokenize(title) {
+		word := token.Surface
+		if reading, ok := token.Reading(); ok && reading != "*" && strings.IndexFunc(word, isHan) >= 0 {
+			word = reading
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " ")
+}
+
+func isHan(r rune) bool {
+	return unicode.Is(unicode.Han, r)
+}
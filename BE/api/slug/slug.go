@@ -0,0 +1,241 @@
+package slug
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// Make turns a title into an ASCII URL path segment. Latin diacritics are folded,
+// Cyrillic, Arabic, kana and Hangul are transliterated, and Han characters are
+// romanized with their most common Mandarin reading (pinyin without tones), one
+// syllable per word: "学习 Go 语言" -> "xue-xi-go-yu-yan".
+func Make(title string) string {
+	return makeSlug(title, false)
+}
+
+// ForLanguage is Make for a title written in lang. Japanese kanji are read word by word
+// with a Japanese dictionary rather than in Mandarin: "東京大学入門" ->
+// "toukyoudaigaku-nyuumon". The few words it cannot read keep their kanji.
+func ForLanguage(title string, lang string) string {
+	if lang == "ja" {
+		return makeSlug(japaneseReadings(title), true)
+	}
+	return makeSlug(title, false)
+}
+
+func makeSlug(title string, keepHan bool) string {
+	var b strings.Builder
+	runes := []rune(strings.ToLower(title))
+	lastHan := false
+
+	// sep writes a single hyphen between words
+	sep := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "-") {
+			b.WriteByte('-')
+		}
+	}
+	// write keeps romanized text and kept Han characters apart, "ゴ入門" -> "go-入門"
+	write := func(s string, han bool) {
+		if s == "" {
+			return
+		}
+		if han != lastHan && b.Len() > 0 {
+			sep()
+		}
+		b.WriteString(s)
+		lastHan = han
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			write(string(r), false)
+		case isKana(r):
+			text, n := romanizeKana(runes[i:])
+			write(text, false)
+			i += n - 1
+		case isHangul(r):
+			write(romanizeHangul(runes[i:]), false)
+		case unicode.Is(unicode.Han, r) && keepHan:
+			write(string(r), true)
+		case unicode.Is(unicode.Han, r):
+			if reading := hanReading(r); reading != "" {
+				sep()
+				write(reading, false)
+				sep()
+			}
+		case unicode.Is(unicode.Mn, r) || r == 'ー' || r == 'ـ' || r == '\'' || r == '’':
+			// combining marks, Arabic harakat, the kana long vowel and apostrophes belong to the word
+		default:
+			if t, ok := transliteration(r); ok {
+				write(t, false)
+			} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				write(string(r), false)
+			} else {
+				sep()
+			}
+		}
+	}
+
+	return strings.Trim(b.String(), "-")
+}
+
+// hanReading is the first Mandarin reading of a Han character, "" for the rare ones
+// the dictionary does not know
+func hanReading(r rune) string {
+	readings := pinyin.SinglePinyin(r, pinyin.NewArgs())
+	if len(readings) == 0 {
+		return ""
+	}
+	return readings[0]
+}
+
+var latinFolds = map[string]string{
+	"àáâãäåāăą": "a", "çćĉċč": "c", "ďđð": "d", "èéêëēĕėęě": "e", "ĝğġģ": "g", "ĥħ": "h",
+	"ìíîïĩīĭįı": "i", "ĵ": "j", "ķ": "k", "ĺļľŀł": "l", "ñńņňŉ": "n", "òóôõöøōŏő": "o",
+	"ŕŗř": "r", "śŝşšș": "s", "ţťŧț": "t", "ùúûüũūŭůűų": "u", "ŵ": "w", "ýÿŷ": "y", "źżž": "z",
+}
+
+var transliterations = map[rune]string{
+	'æ': "ae", 'œ': "oe", 'ß': "ss", 'þ': "th", 'ª': "a", 'º': "o",
+
+	// Cyrillic (Russian, Ukrainian, Serbian)
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh",
+	'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u", 'ђ': "dj", 'ј': "j", 'љ': "lj",
+	'њ': "nj", 'ћ': "c", 'џ': "dz",
+
+	// Arabic and Persian letters, short vowels are not written so they are not guessed
+	'ا': "a", 'أ': "a", 'إ': "i", 'آ': "a", 'ٱ': "a", 'ب': "b", 'ت': "t", 'ث': "th", 'ج': "j",
+	'ح': "h", 'خ': "kh", 'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'س': "s", 'ش': "sh",
+	'ص': "s", 'ض': "d", 'ط': "t", 'ظ': "z", 'ع': "", 'غ': "gh", 'ف': "f", 'ق': "q",
+	'ك': "k", 'ل': "l", 'م': "m", 'ن': "n", 'ه': "h", 'و': "w", 'ي': "y", 'ى': "a",
+	'ة': "a", 'ء': "", 'ؤ': "w", 'ئ': "y", 'پ': "p", 'چ': "ch", 'ژ': "zh", 'گ': "g",
+	'ک': "k", 'ی': "y",
+}
+
+func init() {
+	for letters, folded := range latinFolds {
+		for _, r := range letters {
+			transliterations[r] = folded
+		}
+	}
+	// Arabic-Indic and Persian digits
+	for d := rune(0); d < 10; d++ {
+		transliterations['٠'+d] = string('0' + d)
+		transliterations['۰'+d] = string('0' + d)
+	}
+}
+
+func transliteration(r rune) (string, bool) {
+	t, ok := transliterations[r]
+	return t, ok
+}
+
+func isKana(r rune) bool {
+	return (r >= 'ぁ' && r <= 'ゖ') || (r >= 'ァ' && r <= 'ヶ')
+}
+
+// Hepburn romanization of the hiragana, katakana are mapped onto them
+var kanaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o", 'ゎ': "wa",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo",
+}
+
+func hiragana(r rune) rune {
+	if r >= 'ァ' && r <= 'ヶ' {
+		return r - ('ァ' - 'ぁ')
+	}
+	return r
+}
+
+// romanizeKana romanizes one kana syllable, with the small ya/yu/yo and vowels
+// following it, and reports how many runes it used.
+func romanizeKana(runes []rune) (string, int) {
+	r := hiragana(runes[0])
+	if r == 'っ' {
+		// the small tsu doubles the consonant of the next syllable
+		if len(runes) > 1 && isKana(runes[1]) {
+			next, n := romanizeKana(runes[1:])
+			if strings.HasPrefix(next, "ch") {
+				return "t" + next, n + 1
+			}
+			if next != "" && !strings.ContainsRune("aeioun", rune(next[0])) {
+				return next[:1] + next, n + 1
+			}
+			return next, n + 1
+		}
+		return "", 1
+	}
+
+	text := kanaRomaji[r]
+	if len(runes) < 2 {
+		return text, 1
+	}
+	switch small := hiragana(runes[1]); small {
+	case 'ゃ', 'ゅ', 'ょ':
+		if strings.HasSuffix(text, "i") && len(text) > 1 {
+			yoon := kanaRomaji[small]
+			if text == "shi" || text == "chi" || text == "ji" {
+				return text[:len(text)-1] + yoon[1:], 2
+			}
+			return text[:len(text)-1] + yoon, 2
+		}
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		// ファ fa, ティ ti, ヴァ va
+		if len(text) > 1 {
+			return text[:len(text)-1] + kanaRomaji[small], 2
+		}
+	}
+	return text, 1
+}
+
+func isHangul(r rune) bool {
+	return r >= 0xAC00 && r <= 0xD7A3
+}
+
+// Revised Romanization of Korean, by the jamo of the syllable
+var (
+	hangulInitials = []string{"g", "kk", "n", "d", "tt", "r", "m", "b", "pp", "s", "ss", "", "j", "jj", "ch", "k", "t", "p", "h"}
+	hangulMedials  = []string{"a", "ae", "ya", "yae", "eo", "e", "yeo", "ye", "o", "wa", "wae", "oe", "yo", "u", "wo", "we", "wi", "yu", "eu", "ui", "i"}
+	hangulFinals   = []string{"", "k", "k", "k", "n", "n", "n", "t", "l", "k", "m", "l", "l", "l", "p", "l", "m", "p", "p", "t", "t", "ng", "t", "t", "k", "t", "p", "t"}
+	// a final consonant moves to the next syllable when it starts with a silent ㅇ
+	hangulLinkedFinals = map[int]string{1: "g", 2: "kk", 4: "n", 7: "d", 8: "r", 16: "m", 17: "b", 19: "s", 20: "ss", 22: "j", 23: "ch", 24: "k", 25: "t", 26: "p", 27: ""}
+)
+
+// romanizeHangul romanizes the first syllable, looking at the next one for the linking
+func romanizeHangul(runes []rune) string {
+	index := int(runes[0] - 0xAC00)
+	initial, medial, final := index/(21*28), index/28%21, index%28
+
+	text := hangulInitials[initial] + hangulMedials[medial]
+	if final == 0 {
+		return text
+	}
+	if len(runes) > 1 && isHangul(runes[1]) && int(runes[1]-0xAC00)/(21*28) == 11 {
+		if linked, ok := hangulLinkedFinals[final]; ok {
+			return text + linked
+		}
+	}
+	return text + hangulFinals[final]
+}
@@ -0,0 +1,57 @@
+package slug
+
+import "testing"
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"english", "How to Learn Go in 2024?", "how-to-learn-go-in-2024"},
+		{"apostrophe", "Don't Panic", "dont-panic"},
+		{"latin diacritics", "Introdução à Programação: Lição nº 1", "introducao-a-programacao-licao-no-1"},
+		{"german", "Straße über Köln", "strasse-uber-koln"},
+		{"russian", "Как выучить язык программирования", "kak-vyuchit-yazyk-programmirovaniya"},
+		{"ukrainian", "Їжак і щука", "yizhak-i-shchuka"},
+		{"arabic", "كيف تتعلم البرمجة ٢٠٢٤", "kyf-ttlm-albrmja-2024"},
+		{"hiragana", "ちゃんと べんきょう", "chanto-benkyou"},
+		{"katakana", "ゴルーチン ファイル ベッド", "goruchin-fairu-beddo"},
+		{"small tsu before chi", "まっちゃ", "matcha"},
+		{"japanese mixed with kanji", "ゴ言語入門ガイド", "go-yan-yu-ru-men-gaido"},
+		{"chinese", "学习 Go 语言", "xue-xi-go-yu-yan"},
+		{"traditional chinese", "學習Go語言", "xue-xi-go-yu-yan"},
+		{"chinese umlaut", "绿色", "lv-se"},
+		{"korean", "한국어 배우기", "hangugeo-baeugi"},
+		{"korean greeting", "안녕하세요", "annyeonghaseyo"},
+		{"separators", "  --a / b__c--  ", "a-b-c"},
+		{"only punctuation", "?!", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Make(tt.input); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForLanguage(t *testing.T) {
+	japanese := []struct {
+		title string
+		want  string
+	}{
+		{"ゴ言語入門ガイド", "go-gengo-nyuumon-gaido"},
+		{"東京大学入門", "toukyoudaigaku-nyuumon"},
+		{"日本語の勉強", "nihongo-no-benkyou"},
+		{"Go言語で機械学習 2024", "go-gengo-de-kikai-gakushuu-2024"},
+	}
+	for _, tt := range japanese {
+		if got := ForLanguage(tt.title, "ja"); got != tt.want {
+			t.Errorf("ForLanguage(%q, ja) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+	if got := ForLanguage("学习 Go 语言", "zh"); got != "xue-xi-go-yu-yan" {
+		t.Errorf("ForLanguage(zh) = %q, want pinyin", got)
+	}
+}
@@ -0,0 +1,633 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"my_lambda_app/videostate"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// AWSStore keeps metadata in a single DynamoDB table and blobs in an S3 bucket.
+type AWSStore struct {
+	dynamoDBClient *dynamodb.Client
+	s3Client       *s3.Client
+	tableName      string
+	bucketName     string
+}
+
+func NewAWSStore(cfg aws.Config, tableName string, bucketName string) *AWSStore {
+	return &AWSStore{
+		dynamoDBClient: dynamodb.NewFromConfig(cfg),
+		s3Client:       s3.NewFromConfig(cfg),
+		tableName:      tableName,
+		bucketName:     bucketName,
+	}
+}
+
+type dynamoItem struct {
+	Vid                   string            `dynamodbav:"vid"`
+	Title                 map[string]string `dynamodbav:"title"`
+	Summary               map[string]string `dynamodbav:"summary"`
+	Answer                map[string]string `dynamodbav:"answer"`
+	Path                  map[string]string `dynamodbav:"path"`
+	Status                map[string]string `dynamodbav:"status"`
+	Category              string            `dynamodbav:"category"`
+	LikeCount             int               `dynamodbav:"like_count"`
+	ViewCount             int               `dynamodbav:"view_count"`
+	Lang                  string            `dynamodbav:"lang"`
+	VideoLang             string            `dynamodbav:"video_lang"`
+	ChannelId             string            `dynamodbav:"channel_id"`
+	UploadDate            string            `dynamodbav:"video_upload_date"`
+	ArticleUploadDateTime string            `dynamodbav:"article_update_datetime"`
+	Duration              int               `dynamodbav:"duration"`
+	ChannelName           string            `dynamodbav:"channel_name"`
+	DownsubDownloadCap    string            `dynamodbav:"downsub_download_cap"`
+}
+
+func stringMapToAttributeValueMap(m map[string]string) map[string]dynamodbtypes.AttributeValue {
+	avMap := make(map[string]dynamodbtypes.AttributeValue)
+	for k, v := range m {
+		avMap[k] = &dynamodbtypes.AttributeValueMemberS{Value: v}
+	}
+	return avMap
+}
+
+func videoKey(vid string) map[string]dynamodbtypes.AttributeValue {
+	return map[string]dynamodbtypes.AttributeValue{
+		"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("VIDEO#%s", vid)},
+		"SK": &dynamodbtypes.AttributeValueMemberS{Value: "METADATA"},
+	}
+}
+
+func (s *AWSStore) DeleteVideo(videoId string) error {
+	_, err := s.dynamoDBClient.DeleteItem(context.Background(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       videoKey(videoId),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete video %s from DynamoDB: %w", videoId, err)
+	}
+
+	fmt.Printf("Video %s deleted from DynamoDB successfully.\n", videoId)
+	return nil
+}
+
+func (s *AWSStore) PutMetadata(data videostate.Metadata) error {
+	dateTimeNow := time.Now().Format("2006-01-02 15:04")
+
+	if data.Vid == "" {
+		return fmt.Errorf("Vid not found")
+	}
+
+	item := map[string]dynamodbtypes.AttributeValue{
+		"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("VIDEO#%s", data.Vid)},
+		"SK": &dynamodbtypes.AttributeValueMemberS{Value: "METADATA"},
+
+		// GSI for querying by video asc/desc
+		"GSI1PK": &dynamodbtypes.AttributeValueMemberS{Value: "VIDS#"},
+		"GSI1SK": &dynamodbtypes.AttributeValueMemberS{
+			Value: fmt.Sprintf("MOD#%s", dateTimeNow), //  Article creation
+		},
+
+		// GSI for quering by CHAN#{Channel Name}
+		"GSI2PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("CHAN#%s", data.ChannelId)},
+		"GSI2SK": &dynamodbtypes.AttributeValueMemberS{
+			Value: fmt.Sprintf("UPL#%s", data.UploadDate), //  Video Upload
+		},
+
+		// Main data fields
+		"vid":               &dynamodbtypes.AttributeValueMemberS{Value: data.Vid},
+		"lang":              &dynamodbtypes.AttributeValueMemberS{Value: data.Lang},
+		"channel_id":        &dynamodbtypes.AttributeValueMemberS{Value: data.ChannelId},
+		"video_upload_date": &dynamodbtypes.AttributeValueMemberS{Value: data.UploadDate}, // yt publish date
+		"duration":          &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%.2f", float64(data.Duration))},
+		"channel_name":      &dynamodbtypes.AttributeValueMemberS{Value: data.ChannelName},
+		"category":          &dynamodbtypes.AttributeValueMemberS{Value: data.Category},
+		"video_lang":        &dynamodbtypes.AttributeValueMemberS{Value: data.VideoLang},
+
+		// Now stored as Maps
+		"title":   &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Title)},
+		"summary": &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Summary)},
+		"answer":  &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Answer)},
+		"path":    &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Path)},
+		"status":  &dynamodbtypes.AttributeValueMemberM{Value: stringMapToAttributeValueMap(data.Status)},
+
+		"article_update_datetime": &dynamodbtypes.AttributeValueMemberS{Value: time.Now().Format("2006-01-02T15:04:05")},
+		"like_count":              &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", data.LikeCount)},
+		"view_count":              &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", data.ViewCount)},
+		"downsub_download_cap":    &dynamodbtypes.AttributeValueMemberS{Value: data.DownSubDownloadCap},
+	}
+
+	_, err := s.dynamoDBClient.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push to DynamoDB: %w", err)
+	}
+
+	fmt.Println("Data pushed to DynamoDB successfully.")
+	return nil
+}
+
+func (s *AWSStore) PutCategoryStats(data videostate.Metadata, lang string) error {
+	// Pad LikeCount to 8 digits for correct lexicographic sort
+	paddedLikeCount := fmt.Sprintf("%08d", data.LikeCount)
+	dateTimeNow := time.Now().Format("2006-01-02 15:04")
+
+	item := map[string]dynamodbtypes.AttributeValue{
+		"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("LANG#%s", lang)},
+		"SK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("CAT#%s#CREATED#%s", data.Category, dateTimeNow)},
+
+		// GSI for querying by category and LikeCount
+		"GSI1PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("CAT#%s", data.Category)},
+		"GSI1SK": &dynamodbtypes.AttributeValueMemberS{
+			Value: fmt.Sprintf("LANG#%s#LIKES#%s", lang, paddedLikeCount),
+		},
+
+		// Main data fields
+		"vid":               &dynamodbtypes.AttributeValueMemberS{Value: data.Vid},
+		"lang":              &dynamodbtypes.AttributeValueMemberS{Value: lang},
+		"uploader_name":     &dynamodbtypes.AttributeValueMemberS{Value: data.ChannelName},
+		"video_upload_date": &dynamodbtypes.AttributeValueMemberS{Value: data.UploadDate}, // yt publish date
+		"category":          &dynamodbtypes.AttributeValueMemberS{Value: data.Category},
+		"video_lang":        &dynamodbtypes.AttributeValueMemberS{Value: data.VideoLang},
+
+		"title": &dynamodbtypes.AttributeValueMemberS{Value: data.Title[lang]},
+		"path":  &dynamodbtypes.AttributeValueMemberS{Value: data.Path[lang]},
+
+		"like_count": &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", data.LikeCount)},
+		"view_count": &dynamodbtypes.AttributeValueMemberN{Value: fmt.Sprintf("%d", data.ViewCount)},
+	}
+
+	_, err := s.dynamoDBClient.PutItem(context.Background(), &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to push to DynamoDB: %w", err)
+	}
+
+	fmt.Println("Data pushed to DynamoDB successfully.")
+	return nil
+}
+
+func (s *AWSStore) GetMetadata(vid string) (*videostate.Metadata, error) {
+	result, err := s.dynamoDBClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       videoKey(vid),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item from DynamoDB: %w", err)
+	}
+
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item dynamoItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal DynamoDB item: %w", err)
+	}
+
+	meta := item.toMetadata()
+	meta.Vid = vid
+	return &meta, nil
+}
+
+func (item dynamoItem) toMetadata() videostate.Metadata {
+	return videostate.Metadata{
+		Title:                 item.Title,
+		Vid:                   item.Vid,
+		Summary:               item.Summary,
+		Category:              item.Category,
+		Lang:                  item.Lang,
+		Answer:                item.Answer,
+		Path:                  item.Path,
+		Status:                item.Status,
+		ChannelId:             item.ChannelId,
+		UploadDate:            item.UploadDate,
+		ArticleUploadDateTime: item.ArticleUploadDateTime,
+		Duration:              item.Duration,
+		LikeCount:             item.LikeCount,
+		ViewCount:             item.ViewCount,
+		ChannelName:           item.ChannelName,
+		DownSubDownloadCap:    item.DownsubDownloadCap,
+		VideoLang:             item.VideoLang,
+	}
+}
+
+// categoryKeyAttributes are the attributes that make up a LastEvaluatedKey on the base table.
+var categoryKeyAttributes = []string{"PK", "SK"}
+
+func (s *AWSStore) LatestVideosByCategory(lang string, category string, minViews int, limit int, cursor string) ([]videostate.Metadata, string, error) {
+	startKey, err := cursorToKey(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :sk)"),
+		// Rows written before view_count was stored kept the views in like_count
+		FilterExpression: aws.String("view_count >= :minViews OR (attribute_not_exists(view_count) AND like_count >= :minViews)"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":pk": &dynamodbtypes.AttributeValueMemberS{
+				Value: fmt.Sprintf("LANG#%s", lang),
+			},
+			":sk": &dynamodbtypes.AttributeValueMemberS{
+				Value: fmt.Sprintf("CAT#%s", category),
+			},
+			":minViews": &dynamodbtypes.AttributeValueMemberN{
+				Value: strconv.Itoa(minViews),
+			},
+		},
+		ScanIndexForward:  aws.Bool(false), // newest first
+		Limit:             aws.Int32(int32(limit)),
+		ExclusiveStartKey: startKey,
+	}
+
+	// The filter runs after Limit, so keep reading until the page is full or the partition is exhausted
+	filtered := []videostate.Metadata{}
+	for {
+		result, err := s.dynamoDBClient.Query(context.TODO(), input)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to query videos by category: %w", err)
+		}
+
+		for _, item := range result.Items {
+			filtered = append(filtered, categoryItemToMetadata(item, lang))
+			if len(filtered) >= limit {
+				return filtered, keyToCursor(item, categoryKeyAttributes), nil
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			return filtered, "", nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// categoryItemToMetadata reads a LANG#/CAT# row, whose multilingual fields only hold lang.
+func categoryItemToMetadata(item map[string]dynamodbtypes.AttributeValue, lang string) videostate.Metadata {
+	var meta videostate.Metadata
+
+	// Extract safe values
+	if v, ok := item["vid"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.Vid = v.Value
+	}
+	if v, ok := item["lang"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.Lang = v.Value
+	}
+	if v, ok := item["category"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.Category = v.Value
+	}
+	if v, ok := item["video_upload_date"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.UploadDate = v.Value
+	}
+	if v, ok := item["uploader_id"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.ChannelId = v.Value
+	}
+	if v, ok := item["channel_name"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.ChannelName = v.Value
+	}
+
+	// Handle multilingual fields (string → map)
+	if v, ok := item["title"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.Title = map[string]string{lang: v.Value}
+	}
+	if v, ok := item["path"].(*dynamodbtypes.AttributeValueMemberS); ok {
+		meta.Path = map[string]string{lang: v.Value}
+	}
+	if v, ok := item["like_count"].(*dynamodbtypes.AttributeValueMemberN); ok {
+		meta.LikeCount, _ = strconv.Atoi(v.Value)
+	}
+	if v, ok := item["view_count"].(*dynamodbtypes.AttributeValueMemberN); ok {
+		meta.ViewCount, _ = strconv.Atoi(v.Value)
+	}
+
+	return meta
+}
+
+// channelIndex is the GSI keyed by GSI2PK = CHAN#{channelId} / GSI2SK = UPL#{uploadDate}.
+const channelIndex = "GSI2"
+
+// channelKeyAttributes are the attributes that make up a LastEvaluatedKey on the channel index.
+var channelKeyAttributes = []string{"PK", "SK", "GSI2PK", "GSI2SK"}
+
+// channelQuery selects the videos of a channel whose summary in lang is completed.
+func (s *AWSStore) channelQuery(channelID string, lang string) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		IndexName:              aws.String(channelIndex),
+		KeyConditionExpression: aws.String("GSI2PK = :pk"),
+		FilterExpression:       aws.String("#status.#lang = :completed"),
+		ExpressionAttributeNames: map[string]string{
+			"#status": "status",
+			"#lang":   lang,
+		},
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":pk":        &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("CHAN#%s", channelID)},
+			":completed": &dynamodbtypes.AttributeValueMemberS{Value: string(videostate.StatusSummarizeProcessed)},
+		},
+		ScanIndexForward: aws.Bool(false), // newest upload first
+	}
+}
+
+func keyToCursor(item map[string]dynamodbtypes.AttributeValue, attributes []string) string {
+	key := make(map[string]string)
+	for _, name := range attributes {
+		if v, ok := item[name].(*dynamodbtypes.AttributeValueMemberS); ok {
+			key[name] = v.Value
+		}
+	}
+	return encodeCursor(key)
+}
+
+func cursorToKey(cursor string) (map[string]dynamodbtypes.AttributeValue, error) {
+	key, err := decodeCursor(cursor)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return stringMapToAttributeValueMap(key), nil
+}
+
+func (s *AWSStore) VideosByChannel(channelID string, lang string, limit int, cursor string) ([]videostate.Metadata, string, error) {
+	startKey, err := cursorToKey(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	input := s.channelQuery(channelID, lang)
+	input.Limit = aws.Int32(int32(limit))
+	input.ExclusiveStartKey = startKey
+
+	// The filter runs after Limit, so keep reading until the page is full or the index is exhausted
+	videos := []videostate.Metadata{}
+	for {
+		result, err := s.dynamoDBClient.Query(context.TODO(), input)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to query videos by channel: %w", err)
+		}
+
+		for _, raw := range result.Items {
+			var item dynamoItem
+			if err := attributevalue.UnmarshalMap(raw, &item); err != nil {
+				return nil, "", fmt.Errorf("failed to unmarshal DynamoDB item: %w", err)
+			}
+			videos = append(videos, item.toMetadata())
+			if len(videos) >= limit {
+				return videos, keyToCursor(raw, channelKeyAttributes), nil
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			return videos, "", nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func (s *AWSStore) CountVideosByChannel(channelID string, lang string) (int, error) {
+	input := s.channelQuery(channelID, lang)
+	input.Select = dynamodbtypes.SelectCount
+
+	count := 0
+	for {
+		result, err := s.dynamoDBClient.Query(context.TODO(), input)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count videos by channel: %w", err)
+		}
+		count += int(result.Count)
+		if result.LastEvaluatedKey == nil {
+			return count, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// videosIndex lists every metadata row under GSI1PK "VIDS#"
+const videosIndex = "GSI1"
+
+func (s *AWSStore) ForEachVideo(fn func(videostate.Metadata) error) error {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		IndexName:              aws.String(videosIndex),
+		KeyConditionExpression: aws.String("GSI1PK = :pk"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":pk": &dynamodbtypes.AttributeValueMemberS{Value: "VIDS#"},
+		},
+	}
+
+	for {
+		result, err := s.dynamoDBClient.Query(context.TODO(), input)
+		if err != nil {
+			return fmt.Errorf("failed to query videos: %w", err)
+		}
+
+		for _, raw := range result.Items {
+			var item dynamoItem
+			if err := attributevalue.UnmarshalMap(raw, &item); err != nil {
+				return fmt.Errorf("failed to unmarshal DynamoDB item: %w", err)
+			}
+			if err := fn(item.toMetadata()); err != nil {
+				return err
+			}
+		}
+
+		if result.LastEvaluatedKey == nil {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func userKey(id string) map[string]dynamodbtypes.AttributeValue {
+	return map[string]dynamodbtypes.AttributeValue{
+		"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", id)},
+		"SK": &dynamodbtypes.AttributeValueMemberS{Value: "PROFILE"},
+	}
+}
+
+func (s *AWSStore) PutUser(user User) error {
+	if user.ID == "" {
+		return fmt.Errorf("user ID not found")
+	}
+	item, err := attributevalue.MarshalMap(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user: %w", err)
+	}
+	for k, v := range userKey(user.ID) {
+		item[k] = v
+	}
+
+	_, err = s.dynamoDBClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      item,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put user into DynamoDB: %w", err)
+	}
+	return nil
+}
+
+func (s *AWSStore) GetUser(id string) (*User, error) {
+	result, err := s.dynamoDBClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       userKey(id),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var user User
+	if err := attributevalue.UnmarshalMap(result.Item, &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal user: %w", err)
+	}
+	return &user, nil
+}
+
+// libraryKey keeps the library of a user in the partition of its profile.
+func libraryKey(userID string, vid string, lang string) map[string]dynamodbtypes.AttributeValue {
+	return map[string]dynamodbtypes.AttributeValue{
+		"PK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+		"SK": &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("LIB#%s#%s", vid, lang)},
+	}
+}
+
+func (s *AWSStore) PutLibraryItem(item LibraryItem) error {
+	if item.UserID == "" || item.Vid == "" || item.Lang == "" {
+		return fmt.Errorf("library item without user, video or language")
+	}
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("failed to marshal library item: %w", err)
+	}
+	for k, v := range libraryKey(item.UserID, item.Vid, item.Lang) {
+		av[k] = v
+	}
+
+	_, err = s.dynamoDBClient.PutItem(context.TODO(), &dynamodb.PutItemInput{
+		TableName: aws.String(s.tableName),
+		Item:      av,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put library item into DynamoDB: %w", err)
+	}
+	return nil
+}
+
+func (s *AWSStore) GetLibraryItem(userID string, vid string, lang string) (*LibraryItem, error) {
+	result, err := s.dynamoDBClient.GetItem(context.TODO(), &dynamodb.GetItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       libraryKey(userID, vid, lang),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get library item from DynamoDB: %w", err)
+	}
+	if result.Item == nil {
+		return nil, nil
+	}
+
+	var item LibraryItem
+	if err := attributevalue.UnmarshalMap(result.Item, &item); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal library item: %w", err)
+	}
+	return &item, nil
+}
+
+func (s *AWSStore) LibraryItems(userID string) ([]LibraryItem, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.tableName),
+		KeyConditionExpression: aws.String("PK = :pk AND begins_with(SK, :lib)"),
+		ExpressionAttributeValues: map[string]dynamodbtypes.AttributeValue{
+			":pk":  &dynamodbtypes.AttributeValueMemberS{Value: fmt.Sprintf("USER#%s", userID)},
+			":lib": &dynamodbtypes.AttributeValueMemberS{Value: "LIB#"},
+		},
+	}
+
+	items := []LibraryItem{}
+	for {
+		result, err := s.dynamoDBClient.Query(context.TODO(), input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query library: %w", err)
+		}
+		var page []LibraryItem
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal library: %w", err)
+		}
+		items = append(items, page...)
+		if result.LastEvaluatedKey == nil {
+			return items, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func (s *AWSStore) DeleteLibraryItem(userID string, vid string, lang string) error {
+	_, err := s.dynamoDBClient.DeleteItem(context.TODO(), &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.tableName),
+		Key:       libraryKey(userID, vid, lang),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete library item from DynamoDB: %w", err)
+	}
+	return nil
+}
+
+func (s *AWSStore) PutObject(key string, content string) error {
+	fmt.Println("Uploading subtitle to S3...")
+	_, err := s.s3Client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucketName),
+		Key:         aws.String(key),
+		Body:        strings.NewReader(content),
+		ContentType: aws.String("text/plain"),
+		ACL:         types.ObjectCannedACLPrivate,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	fmt.Println("Subtitle uploaded to S3 successfully.")
+	return nil
+}
+
+func (s *AWSStore) GetObject(key string) (string, error) {
+	fmt.Println("Fetching content from S3...")
+
+	output, err := s.s3Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return "", fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch from S3: %w", err)
+	}
+	defer output.Body.Close()
+
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read S3 content: %w", err)
+	}
+
+	fmt.Println("Content fetched from S3 successfully.")
+	return string(content), nil
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor was not produced by the store.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursors are opaque to callers: the key of the last returned row, as base64 JSON.
+func encodeCursor(key map[string]string) string {
+	if len(key) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (map[string]string, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var key map[string]string
+	if err := json.Unmarshal(data, &key); err != nil || len(key) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return key, nil
+}
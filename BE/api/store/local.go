@@ -0,0 +1,450 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"my_lambda_app/videostate"
+)
+
+// LocalStore is a file-backed Store meant for local development and tests.
+// Layout under dir:
+//
+//	videos/{vid}.json          full metadata rows
+//	categories/{lang}.json     category listing entries, one file per language
+//	users/{id}.json            user accounts
+//	library/{userId}.json      library of a user
+//	objects/{key}              blobs such as captions
+type LocalStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// categoryEntry mirrors the LANG#/CAT# rows written to DynamoDB.
+type categoryEntry struct {
+	SK       string              `json:"sk"`
+	Metadata videostate.Metadata `json:"metadata"`
+}
+
+// categoryViews is the view count of a category entry. Entries written before the views
+// were stored apart kept them in LikeCount.
+func categoryViews(meta videostate.Metadata) int {
+	if meta.ViewCount == 0 {
+		return meta.LikeCount
+	}
+	return meta.ViewCount
+}
+
+func NewLocalStore(dir string) (*LocalStore, error) {
+	for _, sub := range []string{"videos", "categories", "users", "library", "objects"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create local store dir: %w", err)
+		}
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) videoPath(vid string) string {
+	return filepath.Join(s.dir, "videos", url.PathEscape(vid)+".json")
+}
+
+func (s *LocalStore) categoryPath(lang string) string {
+	return filepath.Join(s.dir, "categories", url.PathEscape(lang)+".json")
+}
+
+func (s *LocalStore) userPath(id string) string {
+	return filepath.Join(s.dir, "users", url.PathEscape(id)+".json")
+}
+
+func (s *LocalStore) libraryPath(userID string) string {
+	return filepath.Join(s.dir, "library", url.PathEscape(userID)+".json")
+}
+
+func (s *LocalStore) objectPath(key string) string {
+	return filepath.Join(s.dir, "objects", url.PathEscape(key))
+}
+
+func readJSON(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeJSON writes through a temp file so a crash never leaves a half-written row.
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *LocalStore) PutMetadata(data videostate.Metadata) error {
+	if data.Vid == "" {
+		return fmt.Errorf("Vid not found")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data.ArticleUploadDateTime = time.Now().Format("2006-01-02T15:04:05")
+	if err := writeJSON(s.videoPath(data.Vid), data); err != nil {
+		return fmt.Errorf("failed to write metadata to local store: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) PutCategoryStats(data videostate.Metadata, lang string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []categoryEntry
+	path := s.categoryPath(lang)
+	if _, err := readJSON(path, &entries); err != nil {
+		return fmt.Errorf("failed to read category entries: %w", err)
+	}
+
+	dateTimeNow := time.Now().Format("2006-01-02 15:04")
+	sk := fmt.Sprintf("CAT#%s#CREATED#%s", data.Category, dateTimeNow)
+
+	// Pushing the same video twice within a minute replaces its row instead of duplicating it
+	entries = slices.DeleteFunc(entries, func(entry categoryEntry) bool {
+		return entry.SK == sk && entry.Metadata.Vid == data.Vid
+	})
+	entries = append(entries, categoryEntry{
+		SK: sk,
+		Metadata: videostate.Metadata{
+			Vid:         data.Vid,
+			Lang:        lang,
+			ChannelName: data.ChannelName,
+			UploadDate:  data.UploadDate,
+			Category:    data.Category,
+			VideoLang:   data.VideoLang,
+			Title:       map[string]string{lang: data.Title[lang]},
+			Path:        map[string]string{lang: data.Path[lang]},
+			LikeCount:   data.LikeCount,
+			ViewCount:   data.ViewCount,
+		},
+	})
+
+	if err := writeJSON(path, entries); err != nil {
+		return fmt.Errorf("failed to write category entries: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) GetMetadata(vid string) (*videostate.Metadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var meta videostate.Metadata
+	found, err := readJSON(s.videoPath(vid), &meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata from local store: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &meta, nil
+}
+
+func (s *LocalStore) LatestVideosByCategory(lang string, category string, minViews int, limit int, cursor string) ([]videostate.Metadata, string, error) {
+	key, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []categoryEntry
+	if _, err := readJSON(s.categoryPath(lang), &entries); err != nil {
+		return nil, "", fmt.Errorf("failed to query videos by category: %w", err)
+	}
+
+	// Same ordering as the DynamoDB query: SK descending, newest first.
+	// Entries written in the same minute share an SK, the video ID keeps the cursor stable.
+	sortKey := func(entry categoryEntry) string {
+		return entry.SK + "#" + entry.Metadata.Vid
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return sortKey(entries[i]) > sortKey(entries[j])
+	})
+
+	prefix := fmt.Sprintf("CAT#%s", category)
+	var matching []categoryEntry
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.SK, prefix) || categoryViews(entry.Metadata) < minViews {
+			continue
+		}
+		if key != nil && sortKey(entry) >= key["SK"] {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+
+	if len(matching) <= limit {
+		filtered := []videostate.Metadata{}
+		for _, entry := range matching {
+			filtered = append(filtered, entry.Metadata)
+		}
+		return filtered, "", nil
+	}
+
+	filtered := make([]videostate.Metadata, 0, limit)
+	for _, entry := range matching[:limit] {
+		filtered = append(filtered, entry.Metadata)
+	}
+	return filtered, encodeCursor(map[string]string{"SK": sortKey(matching[limit-1])}), nil
+}
+
+// channelSortKey mirrors GSI2SK, with the video ID to break ties between same-day uploads.
+func channelSortKey(meta videostate.Metadata) string {
+	return fmt.Sprintf("UPL#%s#%s", meta.UploadDate, meta.Vid)
+}
+
+// channelVideos scans every stored video of a channel summarized in lang, newest upload first.
+func (s *LocalStore) channelVideos(channelID string, lang string) ([]videostate.Metadata, error) {
+	files, err := os.ReadDir(filepath.Join(s.dir, "videos"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local videos: %w", err)
+	}
+
+	var videos []videostate.Metadata
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		var meta videostate.Metadata
+		if _, err := readJSON(filepath.Join(s.dir, "videos", file.Name()), &meta); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name(), err)
+		}
+		if meta.ChannelId == channelID && meta.Status[lang] == string(videostate.StatusSummarizeProcessed) {
+			videos = append(videos, meta)
+		}
+	}
+
+	sort.Slice(videos, func(i, j int) bool {
+		return channelSortKey(videos[i]) > channelSortKey(videos[j])
+	})
+	return videos, nil
+}
+
+func (s *LocalStore) VideosByChannel(channelID string, lang string, limit int, cursor string) ([]videostate.Metadata, string, error) {
+	key, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all, err := s.channelVideos(channelID, lang)
+	if err != nil {
+		return nil, "", err
+	}
+
+	videos := []videostate.Metadata{}
+	for _, meta := range all {
+		if key != nil && channelSortKey(meta) >= key["GSI2SK"] {
+			continue
+		}
+		videos = append(videos, meta)
+		if len(videos) >= limit {
+			if meta.Vid == all[len(all)-1].Vid {
+				return videos, "", nil
+			}
+			return videos, encodeCursor(map[string]string{"GSI2SK": channelSortKey(meta)}), nil
+		}
+	}
+	return videos, "", nil
+}
+
+func (s *LocalStore) CountVideosByChannel(channelID string, lang string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	videos, err := s.channelVideos(channelID, lang)
+	if err != nil {
+		return 0, err
+	}
+	return len(videos), nil
+}
+
+func (s *LocalStore) ForEachVideo(fn func(videostate.Metadata) error) error {
+	s.mu.Lock()
+	files, err := os.ReadDir(filepath.Join(s.dir, "videos"))
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("failed to list local videos: %w", err)
+	}
+
+	var videos []videostate.Metadata
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		var meta videostate.Metadata
+		if _, err := readJSON(filepath.Join(s.dir, "videos", file.Name()), &meta); err != nil {
+			s.mu.Unlock()
+			return fmt.Errorf("failed to read %s: %w", file.Name(), err)
+		}
+		videos = append(videos, meta)
+	}
+	s.mu.Unlock()
+
+	// fn runs unlocked so it can use the store
+	for _, meta := range videos {
+		if err := fn(meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *LocalStore) DeleteVideo(vid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.videoPath(vid)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete video %s from local store: %w", vid, err)
+	}
+	return nil
+}
+
+func (s *LocalStore) PutUser(user User) error {
+	if user.ID == "" {
+		return fmt.Errorf("user ID not found")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeJSON(s.userPath(user.ID), user); err != nil {
+		return fmt.Errorf("failed to write user to local store: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) GetUser(id string) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var user User
+	found, err := readJSON(s.userPath(id), &user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user from local store: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (s *LocalStore) PutLibraryItem(item LibraryItem) error {
+	if item.UserID == "" || item.Vid == "" || item.Lang == "" {
+		return fmt.Errorf("library item without user, video or language")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []LibraryItem
+	path := s.libraryPath(item.UserID)
+	if _, err := readJSON(path, &items); err != nil {
+		return fmt.Errorf("failed to read library: %w", err)
+	}
+	items = slices.DeleteFunc(items, func(existing LibraryItem) bool {
+		return existing.Vid == item.Vid && existing.Lang == item.Lang
+	})
+	items = append(items, item)
+	if err := writeJSON(path, items); err != nil {
+		return fmt.Errorf("failed to write library: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) GetLibraryItem(userID string, vid string, lang string) (*LibraryItem, error) {
+	items, err := s.LibraryItems(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if item.Vid == vid && item.Lang == lang {
+			return &item, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *LocalStore) LibraryItems(userID string) ([]LibraryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := []LibraryItem{}
+	if _, err := readJSON(s.libraryPath(userID), &items); err != nil {
+		return nil, fmt.Errorf("failed to read library: %w", err)
+	}
+	return items, nil
+}
+
+func (s *LocalStore) DeleteLibraryItem(userID string, vid string, lang string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []LibraryItem
+	path := s.libraryPath(userID)
+	found, err := readJSON(path, &items)
+	if err != nil {
+		return fmt.Errorf("failed to read library: %w", err)
+	}
+	if !found {
+		return nil
+	}
+	items = slices.DeleteFunc(items, func(existing LibraryItem) bool {
+		return existing.Vid == vid && existing.Lang == lang
+	})
+	if err := writeJSON(path, items); err != nil {
+		return fmt.Errorf("failed to write library: %w", err)
+	}
+	return nil
+}
+
+func (s *LocalStore) PutObject(key string, content string) error {
+	if err := os.WriteFile(s.objectPath(key), []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *LocalStore) GetObject(key string) (string, error) {
+	data, err := os.ReadFile(s.objectPath(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+	return string(data), nil
+}
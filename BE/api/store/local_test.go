@@ -0,0 +1,291 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"my_lambda_app/videostate"
+)
+
+func TestLocalStore_MetadataRoundTrip(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	meta, err := s.GetMetadata("missing0001")
+	if err != nil || meta != nil {
+		t.Fatalf("Expected nil metadata for unknown video, got %v, %v", meta, err)
+	}
+
+	data := videostate.Metadata{
+		Vid:      "abc12345678",
+		Lang:     "en",
+		Category: "Education",
+		Title:    map[string]string{"en": "A title"},
+		Summary:  map[string]string{"en": "A summary"},
+		Status:   map[string]string{"en": string(videostate.StatusSummarizeProcessed)},
+	}
+	if err := s.PutMetadata(data); err != nil {
+		t.Fatalf("PutMetadata: %v", err)
+	}
+
+	meta, err = s.GetMetadata("abc12345678")
+	if err != nil || meta == nil {
+		t.Fatalf("Expected stored metadata, got %v, %v", meta, err)
+	}
+	if meta.Summary["en"] != "A summary" || meta.Category != "Education" {
+		t.Errorf("Unexpected metadata: %+v", meta)
+	}
+	if meta.ArticleUploadDateTime == "" {
+		t.Errorf("Expected ArticleUploadDateTime to be set")
+	}
+
+	if err := s.DeleteVideo("abc12345678"); err != nil {
+		t.Fatalf("DeleteVideo: %v", err)
+	}
+	if meta, _ := s.GetMetadata("abc12345678"); meta != nil {
+		t.Errorf("Expected video to be deleted")
+	}
+
+	if err := s.PutMetadata(videostate.Metadata{}); err == nil {
+		t.Errorf("Expected error when Vid is empty")
+	}
+}
+
+func TestLocalStore_LatestVideosByCategory(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	videos := []videostate.Metadata{
+		{Vid: "vid00000001", Category: "Music", LikeCount: 300, ViewCount: 10, Title: map[string]string{"pt": "Um"}},
+		{Vid: "vid00000002", Category: "Music", LikeCount: 5, ViewCount: 500, Title: map[string]string{"pt": "Dois"}},
+		{Vid: "vid00000003", Category: "Sports", ViewCount: 900, Title: map[string]string{"pt": "Três"}},
+		// written before the views were stored apart, LikeCount holds them
+		{Vid: "vid00000004", Category: "Music", LikeCount: 700, Title: map[string]string{"pt": "Quatro"}},
+	}
+	for _, v := range videos {
+		if err := s.PutCategoryStats(v, "pt"); err != nil {
+			t.Fatalf("PutCategoryStats: %v", err)
+		}
+	}
+
+	items, next, err := s.LatestVideosByCategory("pt", "Music", 100, 10, "")
+	if err != nil {
+		t.Fatalf("LatestVideosByCategory: %v", err)
+	}
+	if len(items) != 2 || next != "" {
+		t.Fatalf("Expected 2 items on a single page, got %d (next %q)", len(items), next)
+	}
+	for _, item := range items {
+		if item.Category != "Music" || item.Vid == "vid00000001" {
+			t.Errorf("Unexpected item %+v", item)
+		}
+	}
+
+	// Walking the pages returns every match once
+	seen := make(map[string]bool)
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		items, next, err = s.LatestVideosByCategory("pt", "Music", 0, 1, cursor)
+		if err != nil {
+			t.Fatalf("LatestVideosByCategory: %v", err)
+		}
+		if len(items) != 1 {
+			t.Fatalf("Expected limit to be honoured, got %d", len(items))
+		}
+		if seen[items[0].Vid] {
+			t.Errorf("Video %s returned twice", items[0].Vid)
+		}
+		seen[items[0].Vid] = true
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(seen) != 3 {
+		t.Errorf("Expected 3 Music videos across pages, got %v", seen)
+	}
+
+	items, _, _ = s.LatestVideosByCategory("en", "Music", 0, 10, "")
+	if len(items) != 0 {
+		t.Errorf("Expected no items for another language, got %d", len(items))
+	}
+}
+
+func TestLocalStore_Objects(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	if _, err := s.GetObject("nope-caption.txt"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected error for missing object")
+	}
+
+	if err := s.PutObject("abc12345678-caption.txt", "1\n00:00:01,000 --> 00:00:02,000\nhi\n"); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	content, err := s.GetObject("abc12345678-caption.txt")
+	if err != nil {
+		t.Fatalf("GetObject: %v", err)
+	}
+	if content != "1\n00:00:01,000 --> 00:00:02,000\nhi\n" {
+		t.Errorf("Unexpected content %q", content)
+	}
+}
+
+func TestLocalStore_VideosByChannel(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	completed := map[string]string{"en": string(videostate.StatusSummarizeProcessed)}
+	videos := []videostate.Metadata{
+		{Vid: "chanVid0001", ChannelId: "UC1", UploadDate: "2025-01-01", Status: completed},
+		{Vid: "chanVid0002", ChannelId: "UC1", UploadDate: "2025-03-01", Status: completed},
+		{Vid: "chanVid0003", ChannelId: "UC1", UploadDate: "2025-02-01", Status: completed},
+		{Vid: "chanVid0004", ChannelId: "UC1", UploadDate: "2025-04-01", Status: map[string]string{"en": "processing-pending"}},
+		{Vid: "chanVid0005", ChannelId: "UC2", UploadDate: "2025-05-01", Status: completed},
+	}
+	for _, v := range videos {
+		if err := s.PutMetadata(v); err != nil {
+			t.Fatalf("PutMetadata: %v", err)
+		}
+	}
+
+	count, err := s.CountVideosByChannel("UC1", "en")
+	if err != nil || count != 3 {
+		t.Errorf("Expected 3 summarized videos, got %d, %v", count, err)
+	}
+
+	var got []string
+	cursor := ""
+	for page := 0; page < 3; page++ {
+		items, next, err := s.VideosByChannel("UC1", "en", 2, cursor)
+		if err != nil {
+			t.Fatalf("VideosByChannel: %v", err)
+		}
+		for _, item := range items {
+			got = append(got, item.Vid)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	want := []string{"chanVid0002", "chanVid0003", "chanVid0001"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+
+	if _, _, err := s.VideosByChannel("UC1", "en", 2, "not-a-cursor"); err != ErrInvalidCursor {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestLocalStore_Users(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	if user, err := s.GetUser("1234"); err != nil || user != nil {
+		t.Fatalf("Expected nil for a missing user, got %+v, %v", user, err)
+	}
+	if err := s.PutUser(User{}); err == nil {
+		t.Errorf("Expected error without an ID")
+	}
+
+	if err := s.PutUser(User{ID: "1234", Email: "ana@example.com", Name: "Ana"}); err != nil {
+		t.Fatalf("PutUser: %v", err)
+	}
+	if err := s.PutUser(User{ID: "1234", Email: "ana@example.com", Name: "Ana Maria", Language: "pt"}); err != nil {
+		t.Fatalf("PutUser: %v", err)
+	}
+	user, err := s.GetUser("1234")
+	if err != nil || user == nil {
+		t.Fatalf("GetUser = %+v, %v", user, err)
+	}
+	if user.Name != "Ana Maria" || user.Language != "pt" {
+		t.Errorf("Expected the user to be replaced, got %+v", user)
+	}
+}
+
+func TestLocalStore_Library(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+
+	if items, err := s.LibraryItems("user-1"); err != nil || len(items) != 0 {
+		t.Fatalf("Expected an empty library, got %+v, %v", items, err)
+	}
+	if err := s.PutLibraryItem(LibraryItem{UserID: "user-1"}); err == nil {
+		t.Errorf("Expected error without a video")
+	}
+
+	for _, item := range []LibraryItem{
+		{UserID: "user-1", Vid: "abc12345678", Lang: "en"},
+		{UserID: "user-1", Vid: "abc12345678", Lang: "pt"},
+		{UserID: "user-1", Vid: "abc12345678", Lang: "en", Favorite: true, Tags: []string{"go"}},
+		{UserID: "user-2", Vid: "abc12345678", Lang: "en"},
+	} {
+		if err := s.PutLibraryItem(item); err != nil {
+			t.Fatalf("PutLibraryItem: %v", err)
+		}
+	}
+
+	items, err := s.LibraryItems("user-1")
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %+v, %v", items, err)
+	}
+	item, err := s.GetLibraryItem("user-1", "abc12345678", "en")
+	if err != nil || item == nil || !item.Favorite || len(item.Tags) != 1 {
+		t.Fatalf("Expected the item to be replaced, got %+v, %v", item, err)
+	}
+
+	if err := s.DeleteLibraryItem("user-1", "abc12345678", "en"); err != nil {
+		t.Fatalf("DeleteLibraryItem: %v", err)
+	}
+	if item, _ := s.GetLibraryItem("user-1", "abc12345678", "en"); item != nil {
+		t.Errorf("Expected the item to be deleted, got %+v", item)
+	}
+	if items, _ := s.LibraryItems("user-2"); len(items) != 1 {
+		t.Errorf("Expected the library of another user to be untouched, got %+v", items)
+	}
+}
+
+func TestLocalStore_ForEachVideo(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore: %v", err)
+	}
+	for _, vid := range []string{"abc12345678", "def12345678"} {
+		if err := s.PutMetadata(videostate.Metadata{Vid: vid, Lang: "en"}); err != nil {
+			t.Fatalf("PutMetadata: %v", err)
+		}
+	}
+
+	seen := map[string]bool{}
+	err = s.ForEachVideo(func(meta videostate.Metadata) error {
+		seen[meta.Vid] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachVideo: %v", err)
+	}
+	if len(seen) != 2 || !seen["abc12345678"] || !seen["def12345678"] {
+		t.Errorf("Expected both videos, got %v", seen)
+	}
+}
@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+
+	"my_lambda_app/videostate"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// Store is the persistence layer used by the API. The AWS implementation
+// keeps video metadata in DynamoDB and captions in S3; the local one keeps
+// everything on disk so the API can run without AWS credentials.
+type Store interface {
+	// PutMetadata writes the full multilingual metadata row of a video.
+	PutMetadata(data videostate.Metadata) error
+	// PutCategoryStats writes the per-language category listing entry of a video.
+	PutCategoryStats(data videostate.Metadata, lang string) error
+	// GetMetadata returns the stored metadata of a video, or nil when it does not exist.
+	GetMetadata(vid string) (*videostate.Metadata, error)
+	// LatestVideosByCategory returns a page of the newest videos of a category in a language with at
+	// least minViews views. Pagination works like VideosByChannel.
+	LatestVideosByCategory(lang string, category string, minViews int, limit int, cursor string) ([]videostate.Metadata, string, error)
+	// VideosByChannel returns a page of the videos of a channel summarized in lang, newest upload first.
+	// Pass "" as cursor for the first page; the returned cursor is "" on the last page.
+	VideosByChannel(channelID string, lang string, limit int, cursor string) ([]videostate.Metadata, string, error)
+	// CountVideosByChannel returns how many videos of a channel are summarized in lang.
+	CountVideosByChannel(channelID string, lang string) (int, error)
+	// ForEachVideo calls fn with the metadata row of every stored video, in no particular
+	// order, and stops at the first error fn returns.
+	ForEachVideo(fn func(videostate.Metadata) error) error
+	// DeleteVideo removes the metadata row of a video.
+	DeleteVideo(vid string) error
+	// PutUser creates or replaces the account of a user.
+	PutUser(user User) error
+	// GetUser returns the account of a user, or nil when it does not exist.
+	GetUser(id string) (*User, error)
+	// PutLibraryItem creates or replaces a video of the library of a user.
+	PutLibraryItem(item LibraryItem) error
+	// GetLibraryItem returns a video of the library of a user, or nil when it is not there.
+	GetLibraryItem(userID string, vid string, lang string) (*LibraryItem, error)
+	// LibraryItems returns the whole library of a user, in no particular order.
+	LibraryItems(userID string) ([]LibraryItem, error)
+	// DeleteLibraryItem removes a video from the library of a user.
+	DeleteLibraryItem(userID string, vid string, lang string) error
+	// PutObject stores a text blob (captions) under key.
+	PutObject(key string, content string) error
+	// GetObject reads a text blob previously stored with PutObject.
+	GetObject(key string) (string, error)
+}
+
+// User is an account created on the first Google sign-in. ID is the Google subject.
+type User struct {
+	ID          string `json:"id" dynamodbav:"user_id"`
+	Email       string `json:"email" dynamodbav:"email"`
+	Name        string `json:"name" dynamodbav:"name"`
+	Picture     string `json:"picture,omitempty" dynamodbav:"picture"`
+	Language    string `json:"language,omitempty" dynamodbav:"language"`
+	CreatedAt   string `json:"created_at" dynamodbav:"created_at"`
+	LastLoginAt string `json:"last_login_at" dynamodbav:"last_login_at"`
+}
+
+// LibraryItem is a video summarized by a user, keyed like videostate.Metadata by video and language.
+type LibraryItem struct {
+	UserID    string   `json:"user_id" dynamodbav:"user_id"`
+	Vid       string   `json:"videoId" dynamodbav:"vid"`
+	Lang      string   `json:"lang" dynamodbav:"lang"`
+	Saved     bool     `json:"saved" dynamodbav:"saved"`
+	Favorite  bool     `json:"favorite" dynamodbav:"favorite"`
+	Notes     string   `json:"notes,omitempty" dynamodbav:"notes"`
+	Tags      []string `json:"tags,omitempty" dynamodbav:"tags,stringset,omitempty"`
+	AddedAt   string   `json:"added_at" dynamodbav:"added_at"`
+	UpdatedAt string   `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// ErrObjectNotFound is returned by GetObject when nothing is stored under the key.
+// It matches fs.ErrNotExist, so packages using the store through their own interface
+// can check it without importing this package.
+var ErrObjectNotFound = fmt.Errorf("object not found: %w", fs.ErrNotExist)
+
+const (
+	BackendAWS   = "aws"
+	BackendLocal = "local"
+)
+
+const defaultBucketName = "sumtube"
+const defaultLocalDir = "./data"
+
+// NewFromEnv builds the Store selected by STORAGE_BACKEND ("aws" by default, or "local").
+// The local backend writes under LOCAL_STORE_DIR.
+func NewFromEnv() (Store, error) {
+	backend := os.Getenv("STORAGE_BACKEND")
+	if backend == "" {
+		backend = BackendAWS
+	}
+
+	switch backend {
+	case BackendLocal:
+		dir := os.Getenv("LOCAL_STORE_DIR")
+		if dir == "" {
+			dir = defaultLocalDir
+		}
+		log.Printf("Using local storage backend at %s", dir)
+		return NewLocalStore(dir)
+	case BackendAWS:
+		tableName := os.Getenv("DYNAMODB_TABLE_NAME")
+		if tableName == "" {
+			log.Println("Please add DYNAMODB_TABLE_NAME to .env file")
+		}
+		cfg, err := config.LoadDefaultConfig(context.Background(),
+			config.WithRegion("us-east-1"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load AWS SDK config: %w", err)
+		}
+		return NewAWSStore(cfg, tableName, defaultBucketName), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,248 @@
+// Package subtitle parses and renders the captions of the summary pipeline: SubRip (DownSub,
+// youtube-transcript-py), WebVTT (yt-dlp, YouTube auto captions) and their variants.
+package subtitle
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoCues is returned when a caption has no timed text at all
+var ErrNoCues = errors.New("subtitle: no cues found")
+
+// Cue is one timed line of a caption
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+var (
+	timingLine = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{2}(?:[,.]\d{1,3})?)\s*-->\s*((?:\d+:)?\d{1,2}:\d{2}(?:[,.]\d{1,3})?)`)
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Parse reads an SRT or WebVTT caption, the format is detected from the WEBVTT header.
+func Parse(data string) ([]Cue, error) {
+	data = strings.TrimPrefix(strings.ReplaceAll(data, "\r\n", "\n"), "\ufeff")
+	if strings.HasPrefix(strings.TrimSpace(data), "WEBVTT") {
+		return ParseVTT(data)
+	}
+	return ParseSRT(data)
+}
+
+// ParseSRT reads SubRip cues: an optional counter, the timing line and the text lines.
+// The DownSub variant ("0:18:32,000", single digit hours) and timings without
+// milliseconds are accepted.
+func ParseSRT(data string) ([]Cue, error) {
+	return parseBlocks(data, false)
+}
+
+// ParseVTT reads WebVTT cues, skipping the header, NOTE, STYLE and REGION blocks
+// and the cue settings after the timing (e.g. "align:start position:0%").
+func ParseVTT(data string) ([]Cue, error) {
+	return parseBlocks(data, true)
+}
+
+func parseBlocks(data string, vtt bool) ([]Cue, error) {
+	var cues []Cue
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		match := timingLine.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		start, err := ParseTimestamp(match[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := ParseTimestamp(match[2])
+		if err != nil {
+			return nil, err
+		}
+
+		// a WebVTT cue ends at an empty line, YouTube auto captions start their cues
+		// with a line holding a single space
+		endOfCue := func(line string) bool {
+			if vtt {
+				return line == "" || timingLine.MatchString(line)
+			}
+			return strings.TrimSpace(line) == "" || timingLine.MatchString(line)
+		}
+
+		var text []string
+		for i+1 < len(lines) && !endOfCue(lines[i+1]) {
+			i++
+			text = append(text, lines[i])
+		}
+		// an SRT counter of the next cue can stick to the text when the blank line is missing
+		if !vtt && len(text) > 0 && i+1 < len(lines) && timingLine.MatchString(lines[i+1]) {
+			if _, err := strconv.Atoi(strings.TrimSpace(text[len(text)-1])); err == nil {
+				text = text[:len(text)-1]
+			}
+		}
+
+		cue := Cue{Start: start, End: end, Text: cleanText(strings.Join(text, "\n"))}
+		if cue.Text != "" {
+			cues = append(cues, cue)
+		}
+	}
+
+	if len(cues) == 0 {
+		return nil, ErrNoCues
+	}
+	return cues, nil
+}
+
+// cleanText drops the markup of the cue text (<i>, <c>, <00:00:01.000>) and its entities
+func cleanText(text string) string {
+	text = html.UnescapeString(tagPattern.ReplaceAllString(text, ""))
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// maxHours keeps the durations of malformed captions far from overflowing
+const maxHours = 999
+
+// ParseTimestamp reads "HH:MM:SS,mmm", "HH:MM:SS.mmm" or "MM:SS.mmm", the milliseconds
+// are optional
+func ParseTimestamp(s string) (time.Duration, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	clock, fraction, _ := strings.Cut(s, ".")
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("subtitle: invalid timestamp %q", s)
+	}
+	if len(parts) == 2 {
+		parts = append([]string{"0"}, parts...)
+	}
+
+	var values [3]int
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil || v < 0 || (i == 0 && v > maxHours) || (i > 0 && v > 59) {
+			return 0, fmt.Errorf("subtitle: invalid timestamp %q", s)
+		}
+		values[i] = v
+	}
+	millis := 0
+	if fraction != "" {
+		fraction = (fraction + "00")[:3]
+		v, err := strconv.Atoi(fraction)
+		if err != nil {
+			return 0, fmt.Errorf("subtitle: invalid timestamp %q", s)
+		}
+		millis = v
+	}
+
+	return time.Duration(values[0])*time.Hour + time.Duration(values[1])*time.Minute +
+		time.Duration(values[2])*time.Second + time.Duration(millis)*time.Millisecond, nil
+}
+
+// FormatTimestamp writes d as "HH:MM:SS" followed by the milliseconds after sep
+// ("," for SRT, "." for WebVTT), or without them when sep is empty.
+func FormatTimestamp(d time.Duration, sep string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	clock := fmt.Sprintf("%02d:%02d:%02d", ms/3600000, ms/60000%60, ms/1000%60)
+	if sep == "" {
+		return clock
+	}
+	return fmt.Sprintf("%s%s%03d", clock, sep, ms%1000)
+}
+
+// FormatSRT renders the cues as SubRip
+func FormatSRT(cues []Cue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, FormatTimestamp(cue.Start, ","), FormatTimestamp(cue.End, ","), cue.Text)
+	}
+	return b.String()
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// FormatVTT renders the cues as WebVTT, escaping the text so it is not read as markup
+func FormatVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", FormatTimestamp(cue.Start, "."), FormatTimestamp(cue.End, "."), vttEscaper.Replace(cue.Text))
+	}
+	return b.String()
+}
+
+// FormatText renders the cues as plain text, one cue per line, prefixed with
+// "[HH:MM:SS]" when timestamps is set.
+func FormatText(cues []Cue, timestamps bool) string {
+	var b strings.Builder
+	for _, cue := range cues {
+		text := strings.ReplaceAll(cue.Text, "\n", " ")
+		if timestamps {
+			fmt.Fprintf(&b, "[%s] %s\n", FormatTimestamp(cue.Start, ""), text)
+		} else {
+			b.WriteString(text + "\n")
+		}
+	}
+	return b.String()
+}
+
+// Dedupe removes the rolling lines of automatic captions, where every cue repeats the
+// line of the previous one before adding its own. Cues left without a new line only
+// extend the previous cue.
+func Dedupe(cues []Cue) []Cue {
+	var deduped []Cue
+	var previous []string
+	for _, cue := range cues {
+		lines := strings.Split(cue.Text, "\n")
+		var fresh []string
+		for _, line := range lines {
+			if !slices.Contains(previous, line) {
+				fresh = append(fresh, line)
+			}
+		}
+		previous = lines
+
+		if len(fresh) == 0 {
+			if last := len(deduped) - 1; last >= 0 && cue.End > deduped[last].End {
+				deduped[last].End = cue.End
+			}
+			continue
+		}
+		deduped = append(deduped, Cue{Start: cue.Start, End: cue.End, Text: strings.Join(fresh, "\n")})
+	}
+	return deduped
+}
+
+// Normalize parses a caption of any supported format and drops the rolling lines
+func Normalize(data string) ([]Cue, error) {
+	cues, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return Dedupe(cues), nil
+}
+
+// Duration is the end of the last cue
+func Duration(cues []Cue) time.Duration {
+	var end time.Duration
+	for _, cue := range cues {
+		end = max(end, cue.End)
+	}
+	return end
+}
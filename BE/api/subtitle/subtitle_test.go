@@ -0,0 +1,175 @@
+package subtitle
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSRT(t *testing.T) {
+	input := "\ufeff1\r\n0:00:01,000 --> 0:00:04,500\r\nHello <i>world</i>\r\n\r\n2\r\n00:00:05,000 --> 00:00:09,000\r\nTom &amp; Jerry\r\nsecond line\r\n3\r\n1:02:03,040 --> 1:02:05,000\r\nFinal\r\n"
+	cues, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Cue{
+		{Start: time.Second, End: 4500 * time.Millisecond, Text: "Hello world"},
+		{Start: 5 * time.Second, End: 9 * time.Second, Text: "Tom & Jerry\nsecond line"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 40*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "Final"},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("Parse() = %+v, want %+v", cues, want)
+	}
+}
+
+func TestParseVTT(t *testing.T) {
+	input := `WEBVTT
+Kind: captions
+Language: en
+
+NOTE a comment
+
+00:01.000 --> 00:03.000 align:start position:0%
+Short <c.colorE5E5E5>timestamps</c>
+
+00:00:03.000 --> 00:00:06.250
+Full timestamps
+`
+	cues, err := Parse(input)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []Cue{
+		{Start: time.Second, End: 3 * time.Second, Text: "Short timestamps"},
+		{Start: 3 * time.Second, End: 6250 * time.Millisecond, Text: "Full timestamps"},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("Parse() = %+v, want %+v", cues, want)
+	}
+}
+
+func TestParse_NoCues(t *testing.T) {
+	for _, input := range []string{"", "WEBVTT\n\n", "just some text"} {
+		if _, err := Parse(input); err != ErrNoCues {
+			t.Errorf("Parse(%q) error = %v, want ErrNoCues", input, err)
+		}
+	}
+	if _, err := Parse("00:00:01,000 --> 00:61:00,000\ntext"); err == nil {
+		t.Error("Parse() accepted 61 minutes")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cues := []Cue{
+		{Start: 1500 * time.Millisecond, End: 4 * time.Second, Text: "Hello"},
+		{Start: time.Hour + 5*time.Second, End: time.Hour + 7*time.Second, Text: "two\nlines"},
+	}
+
+	if got, want := FormatSRT(cues), "1\n00:00:01,500 --> 00:00:04,000\nHello\n\n2\n01:00:05,000 --> 01:00:07,000\ntwo\nlines\n\n"; got != want {
+		t.Errorf("FormatSRT() = %q, want %q", got, want)
+	}
+	if got, want := FormatVTT(cues), "WEBVTT\n\n00:00:01.500 --> 00:00:04.000\nHello\n\n01:00:05.000 --> 01:00:07.000\ntwo\nlines\n\n"; got != want {
+		t.Errorf("FormatVTT() = %q, want %q", got, want)
+	}
+	if got, want := FormatText(cues, true), "[00:00:01] Hello\n[01:00:05] two lines\n"; got != want {
+		t.Errorf("FormatText(timestamps) = %q, want %q", got, want)
+	}
+	if got, want := FormatText(cues, false), "Hello\ntwo lines\n"; got != want {
+		t.Errorf("FormatText() = %q, want %q", got, want)
+	}
+
+	// rendering and parsing back keeps the cues
+	for name, rendered := range map[string]string{"srt": FormatSRT(cues), "vtt": FormatVTT(cues)} {
+		parsed, err := Parse(rendered)
+		if err != nil || !reflect.DeepEqual(parsed, cues) {
+			t.Errorf("%s round trip = %+v, %v", name, parsed, err)
+		}
+	}
+}
+
+func TestDedupe_YouTubeAutoCaptions(t *testing.T) {
+	data, err := os.ReadFile("testdata/youtube-auto.vtt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cues, err := Normalize(string(data))
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	want := []Cue{
+		{Start: 160 * time.Millisecond, End: 2320 * time.Millisecond, Text: "welcome back to the channel"},
+		{Start: 2320 * time.Millisecond, End: 5040 * time.Millisecond, Text: "today we talk about Go"},
+		{Start: 5040 * time.Millisecond, End: 8 * time.Second, Text: "and channels"},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("Normalize() = %+v, want %+v", cues, want)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	tests := []struct {
+		file     string
+		cues     int
+		first    string
+		duration time.Duration
+	}{
+		{"downsub.srt", 3, "Hello everyone", time.Hour + 2*time.Minute + 5*time.Second},
+		{"transcript-py.srt", 3, "[Music]", 12 * time.Second},
+		{"youtube-auto.vtt", 3, "welcome back to the channel", 8 * time.Second},
+		{"yt-dlp.vtt", 2, "Short timings", 6 * time.Second},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cues, err := Normalize(string(data))
+		if err != nil {
+			t.Errorf("%s: Normalize() error = %v", tt.file, err)
+			continue
+		}
+		if len(cues) != tt.cues || cues[0].Text != tt.first || Duration(cues) != tt.duration {
+			t.Errorf("%s: got %d cues, first %q, duration %v", tt.file, len(cues), cues[0].Text, Duration(cues))
+		}
+		for _, cue := range cues {
+			if strings.ContainsAny(cue.Text, "<>") || strings.Contains(cue.Text, "&amp;") {
+				t.Errorf("%s: markup left in %q", tt.file, cue.Text)
+			}
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	files, _ := filepath.Glob(filepath.Join("testdata", "*"))
+	for _, file := range files {
+		if data, err := os.ReadFile(file); err == nil {
+			f.Add(string(data))
+		}
+	}
+	f.Add("1\n00:00:01,000 --> 00:00:02,000\n&lt;b&gt; --&gt; 5\n2\n00:00:02,000 --> 00:00:03,000\nx")
+	f.Add("WEBVTT\n\n999:59:59.999 --> 0:01\n \n<c>a</c>")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		cues, err := Parse(data)
+		if err != nil {
+			return
+		}
+		for _, cue := range cues {
+			if cue.Start < 0 || cue.End < 0 || cue.Text == "" || strings.Contains(cue.Text, "\n\n") {
+				t.Fatalf("invalid cue %+v", cue)
+			}
+		}
+
+		// WebVTT keeps everything a cue can hold
+		again, err := Parse(FormatVTT(cues))
+		if err != nil || !reflect.DeepEqual(again, cues) {
+			t.Fatalf("WebVTT round trip of %+v = %+v, %v", cues, again, err)
+		}
+		if deduped := Dedupe(cues); len(deduped) == 0 || len(deduped) > len(cues) {
+			t.Fatalf("Dedupe() kept %d of %d cues", len(deduped), len(cues))
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"my_lambda_app/search"
+	"my_lambda_app/subtitle"
+)
+
+const (
+	maxQuestionLength = 500
+	// askSegmentWindow groups consecutive cues so a retrieved segment carries enough context
+	askSegmentWindow = 30 * time.Second
+	askMaxSegments   = 6
+)
+
+// captionCue is one timed line of the transcript, or a group of them
+type captionCue = subtitle.Cue
+
+type AskRequest struct {
+	Question string `json:"question"`
+	Lang     string `json:"lang"`
+}
+
+type AskCitation struct {
+	Timestamp string `json:"timestamp"`
+	Seconds   int    `json:"seconds"`
+	URL       string `json:"url"`
+	Text      string `json:"text"`
+}
+
+type AskResponse struct {
+	VideoID   string        `json:"videoId"`
+	Question  string        `json:"question"`
+	Lang      string        `json:"lang"`
+	Answer    string        `json:"answer"`
+	Citations []AskCitation `json:"citations"`
+}
+
+// parseSRTCues reads the timed text of the stored caption as single line cues
+func parseSRTCues(srt string) []captionCue {
+	cues, err := subtitle.Normalize(srt)
+	if err != nil {
+		return nil
+	}
+	for i := range cues {
+		cues[i].Text = strings.ReplaceAll(cues[i].Text, "\n", " ")
+	}
+	return cues
+}
+
+// groupCues merges consecutive cues into segments of about window
+func groupCues(cues []captionCue, window time.Duration) []captionCue {
+	var segments []captionCue
+	for _, cue := range cues {
+		last := len(segments) - 1
+		if last >= 0 && cue.Start < segments[last].Start+window {
+			segments[last].Text += " " + cue.Text
+			if cue.End > segments[last].End {
+				segments[last].End = cue.End
+			}
+			continue
+		}
+		segments = append(segments, cue)
+	}
+	return segments
+}
+
+// retrieveSegments ranks the segments against the question with BM25 and returns the best
+// ones in the order of the video. Questions matching nothing (e.g. "what is it about?")
+// get segments spread over the whole video instead.
+func retrieveSegments(segments []captionCue, question string, limit int) []captionCue {
+	const k1, b = 1.2, 0.75
+
+	terms := search.Tokenize(question)
+	docs := make([]map[string]int, len(segments))
+	lengths := make([]int, len(segments))
+	docFreq := map[string]int{}
+	totalLength := 0
+	for i, segment := range segments {
+		tokens := search.Tokenize(segment.Text)
+		totalLength += len(tokens)
+		lengths[i] = len(tokens)
+		docs[i] = map[string]int{}
+		for _, token := range tokens {
+			if docs[i][token] == 0 {
+				docFreq[token]++
+			}
+			docs[i][token]++
+		}
+	}
+	if len(segments) == 0 {
+		return nil
+	}
+	avgLength := float64(totalLength) / float64(len(segments))
+
+	type scored struct {
+		index int
+		score float64
+	}
+	var ranked []scored
+	for i, doc := range docs {
+		score := 0.0
+		for _, term := range terms {
+			tf := float64(doc[term])
+			if tf == 0 {
+				continue
+			}
+			idf := math.Log(1 + (float64(len(segments))-float64(docFreq[term])+0.5)/(float64(docFreq[term])+0.5))
+			score += idf * tf * (k1 + 1) / (tf + k1*(1-b+b*float64(lengths[i])/avgLength))
+		}
+		if score > 0 {
+			ranked = append(ranked, scored{i, score})
+		}
+	}
+
+	var picked []int
+	if len(ranked) == 0 {
+		step := float64(len(segments)) / float64(min(limit, len(segments)))
+		for i := 0; i < min(limit, len(segments)); i++ {
+			picked = append(picked, int(float64(i)*step))
+		}
+	} else {
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+		for i := 0; i < len(ranked) && i < limit; i++ {
+			picked = append(picked, ranked[i].index)
+		}
+		sort.Ints(picked)
+	}
+
+	result := make([]captionCue, 0, len(picked))
+	for _, i := range picked {
+		result = append(result, segments[i])
+	}
+	return result
+}
+
+// askExcerpts is the $$captions$$ of the ask template, each segment prefixed with its time
+func askExcerpts(segments []captionCue) string {
+	var b strings.Builder
+	for _, segment := range segments {
+		fmt.Fprintf(&b, "[%s - %s] %s\n", subtitle.FormatTimestamp(segment.Start, ""), subtitle.FormatTimestamp(segment.End, ""), segment.Text)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// askCitations links the timestamps of the answer to the retrieved segments, timestamps
+// outside of them were not given to the model and are not cited.
+func askCitations(videoID string, answer string, segments []captionCue) []AskCitation {
+	citations := []AskCitation{}
+	seen := map[string]bool{}
+	for _, timestamp := range summaryTimestampPattern.FindAllString(answer, -1) {
+		at, err := subtitle.ParseTimestamp(timestamp)
+		if err != nil {
+			continue
+		}
+		timestamp = subtitle.FormatTimestamp(at, "")
+		if seen[timestamp] {
+			continue
+		}
+		for _, segment := range segments {
+			if at >= segment.Start && at <= segment.End {
+				seen[timestamp] = true
+				seconds := int(at.Seconds())
+				citations = append(citations, AskCitation{
+					Timestamp: timestamp,
+					Seconds:   seconds,
+					URL:       fmt.Sprintf("https://www.youtube.com/watch?v=%s&t=%ds", videoID, seconds),
+					Text:      segment.Text,
+				})
+				break
+			}
+		}
+	}
+	return citations
+}
+
+// POST /video/{videoId}/ask {"question": "...", "lang": "en"}
+// Answers a question about a video from the parts of its stored transcript matching it.
+func handleAskVideo(w http.ResponseWriter, r *http.Request) {
+	videoID, err := extractVideoID("https://www.youtube.com/watch?v=" + r.PathValue("videoId"))
+	if err != nil {
+		http.Error(w, "Invalid videoId", http.StatusBadRequest)
+		return
+	}
+
+	var request AskRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 8<<10)).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	request.Question = strings.TrimSpace(request.Question)
+	if request.Question == "" || len([]rune(request.Question)) > maxQuestionLength {
+		http.Error(w, fmt.Sprintf("'question' is required and limited to %d characters", maxQuestionLength), http.StatusBadRequest)
+		return
+	}
+	if request.Lang == "" {
+		request.Lang = "en"
+	}
+
+	caption, err := dataStore.GetObject(videoID + "-caption.txt")
+	if err != nil || strings.TrimSpace(caption) == "" {
+		http.Error(w, "No transcript stored for this video, summarize it first", http.StatusNotFound)
+		return
+	}
+	segments := retrieveSegments(groupCues(parseSRTCues(caption), askSegmentWindow), request.Question, askMaxSegments)
+	if len(segments) == 0 {
+		http.Error(w, "The transcript of this video is empty", http.StatusNotFound)
+		return
+	}
+
+	title := ""
+	if metadata, err := dataStore.GetMetadata(videoID); err == nil && metadata != nil {
+		title = metadata.Title[request.Lang]
+		if title == "" {
+			title = firstNonEmptyFromMap(metadata.Title)
+		}
+	}
+
+	response, err := callLLMModel(llmModelName, "ask", "", LLMInput{
+		Language: request.Lang,
+		Title:    title,
+		Captions: askExcerpts(segments),
+		Question: request.Question,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	answer, err := llmResponseText(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	answer = strings.TrimSpace(answer)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AskResponse{
+		VideoID:   videoID,
+		Question:  request.Question,
+		Lang:      request.Lang,
+		Answer:    answer,
+		Citations: askCitations(videoID, answer, segments),
+	})
+}
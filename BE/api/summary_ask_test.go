@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const askCaption = `1
+00:00:01,000 --> 00:00:05,000
+Welcome to the <i>cooking</i> show
+
+2
+00:00:40,000 --> 00:00:45,000
+First we chop the onions
+
+3
+00:01:30,000 --> 00:01:36,000
+Then the tomatoes go in the pan
+
+4
+00:02:20,000 --> 00:02:26,000
+Season with salt and basil`
+
+func TestParseSRTCuesAndRetrieveSegments(t *testing.T) {
+	cues := parseSRTCues(askCaption)
+	if len(cues) != 4 || cues[0].Text != "Welcome to the cooking show" || cues[2].Start != 90*time.Second {
+		t.Fatalf("parseSRTCues() = %+v", cues)
+	}
+
+	if segments := retrieveSegments(groupCues(cues, askSegmentWindow), "when do the tomatoes go in?", 1); len(segments) != 1 || !strings.Contains(segments[0].Text, "tomatoes") {
+		t.Fatalf("retrieveSegments() = %+v, want the tomato segment", segments)
+	}
+	segments := retrieveSegments(groupCues(cues, askSegmentWindow), "when do the tomatoes go in?", 3)
+	for i := 1; i < len(segments); i++ {
+		if segments[i].Start < segments[i-1].Start {
+			t.Errorf("segments are not in the order of the video: %+v", segments)
+		}
+	}
+
+	// nothing matches: segments spread over the video
+	if segments := retrieveSegments(groupCues(cues, askSegmentWindow), "xyz", 2); len(segments) != 2 || segments[0].Start != time.Second {
+		t.Errorf("retrieveSegments(no match) = %+v", segments)
+	}
+}
+
+func TestHandleAskVideo(t *testing.T) {
+	videoID := "askVideo001"
+	if err := dataStore.PutObject(videoID+"-caption.txt", askCaption); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	calls := stubLLMModel(t, func(template, caption string) string {
+		return "The tomatoes go in after the onions (00:01:32), not at (00:05:00)."
+	})
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := post("/video/"+videoID+"/ask", `{"question":"When do the tomatoes go in?","lang":"en"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response AskResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0].template != "ask" || (*calls)[0].question != "When do the tomatoes go in?" {
+		t.Fatalf("llm-model calls = %+v", *calls)
+	}
+	if !strings.Contains((*calls)[0].caption, "[00:01:30 - 00:01:36] Then the tomatoes go in the pan") {
+		t.Errorf("excerpts = %q", (*calls)[0].caption)
+	}
+	// only the timestamp inside a retrieved segment is cited
+	if len(response.Citations) != 1 || response.Citations[0].Seconds != 92 ||
+		response.Citations[0].URL != "https://www.youtube.com/watch?v=askVideo001&t=92s" {
+		t.Errorf("citations = %+v", response.Citations)
+	}
+
+	if rec := post("/video/"+videoID+"/ask", `{"question":"  "}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a question, got %d", rec.Code)
+	}
+	if rec := post("/video/noCaption01/ask", `{"question":"why?"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without a transcript, got %d", rec.Code)
+	}
+	if rec := post("/video/bad/ask", `{"question":"why?"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid video, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"my_lambda_app/store"
+	"my_lambda_app/videostate"
+)
+
+const maxBatchItems = 50
+
+// Batch states reported by GET /summary/batch/{batchId}
+const (
+	batchItemProcessing = "processing"
+	batchItemCompleted  = "completed"
+	batchItemFailed     = "failed"
+)
+
+type SummaryBatchItem struct {
+	VideoID  string `json:"videoId"`
+	Language string `json:"language"`
+}
+
+type SummaryBatch struct {
+	ID         string             `json:"batchId"`
+	CreatedAt  string             `json:"createdAt"`
+	PlaylistID string             `json:"playlistId,omitempty"`
+	Title      string             `json:"title,omitempty"`
+	Items      []SummaryBatchItem `json:"items"`
+}
+
+type SummaryBatchItemStatus struct {
+	VideoID  string                                      `json:"videoId"`
+	Language string                                      `json:"language"`
+	State    string                                      `json:"state"`
+	Result   *HandleSummarySingleLanguageRequestResponse `json:"result,omitempty"`
+}
+
+type SummaryBatchResponse struct {
+	ID         string                   `json:"batchId"`
+	CreatedAt  string                   `json:"createdAt"`
+	PlaylistID string                   `json:"playlistId,omitempty"`
+	Title      string                   `json:"title,omitempty"`
+	Total      int                      `json:"total"`
+	Completed  int                      `json:"completed"`
+	Failed     int                      `json:"failed"`
+	Processing int                      `json:"processing"`
+	Done       bool                     `json:"done"`
+	Items      []SummaryBatchItemStatus `json:"items"`
+}
+
+// Batches are cached in memory and written to the store so they can still be queried after a restart.
+var summaryBatches = make(map[string]*SummaryBatch)
+var summaryBatchesMu sync.Mutex
+
+// enqueueBatchItem is swapped in tests to avoid starting the real pipeline.
+var enqueueBatchItem = func(videoID string, lang string) {
+	enqueueSummary(videoID, lang, "download-and-digest", false)
+}
+
+func summaryBatchKey(batchID string) string {
+	return "batch-" + batchID + ".json"
+}
+
+// summaryFailureKey holds the error status a job ended with. Failed jobs only live in the
+// queue until their TTL and leave no summary in the store, their batches read it from here.
+func summaryFailureKey(videoID string, lang string) string {
+	return videoID + "-" + lang + "-failed.txt"
+}
+
+// recordSummaryFailure is registered on videoQueue and stores the final error status of a job
+func recordSummaryFailure(videoID string, language string, status videostate.VideoStatus) {
+	if status == videostate.StatusSummarizeProcessed || !isFinalStatus(status) {
+		return
+	}
+	if err := dataStore.PutObject(summaryFailureKey(videoID, language), string(status)); err != nil {
+		log.Printf("❌ Failed to record the failure of %s (%s): %v", videoID, language, err)
+	}
+}
+
+func newBatchID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func saveSummaryBatch(batch *SummaryBatch) error {
+	summaryBatchesMu.Lock()
+	summaryBatches[batch.ID] = batch
+	summaryBatchesMu.Unlock()
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode batch: %w", err)
+	}
+	return dataStore.PutObject(summaryBatchKey(batch.ID), string(data))
+}
+
+// loadSummaryBatch returns store.ErrObjectNotFound when the batch does not exist.
+func loadSummaryBatch(batchID string) (*SummaryBatch, error) {
+	summaryBatchesMu.Lock()
+	batch, ok := summaryBatches[batchID]
+	summaryBatchesMu.Unlock()
+	if ok {
+		return batch, nil
+	}
+
+	content, err := dataStore.GetObject(summaryBatchKey(batchID))
+	if err != nil {
+		return nil, err
+	}
+	batch = &SummaryBatch{}
+	if err := json.Unmarshal([]byte(content), batch); err != nil {
+		return nil, fmt.Errorf("failed to parse batch: %w", err)
+	}
+
+	summaryBatchesMu.Lock()
+	summaryBatches[batchID] = batch
+	summaryBatchesMu.Unlock()
+	return batch, nil
+}
+
+// summaryBatchItemStatus reads the state of one item from the queue while it is in flight,
+// or from the store once it has left the queue: the summary when it completed, the
+// recorded failure otherwise.
+func summaryBatchItemStatus(item SummaryBatchItem) SummaryBatchItemStatus {
+	var status videostate.VideoStatus
+	var metadata *videostate.Metadata
+	if videoQueue.Exists(item.VideoID, item.Language) {
+		status = videoQueue.GetStatus(item.VideoID, item.Language)
+		metadata = videoQueue.GetVideoMeta(item.VideoID, item.Language)
+	} else {
+		if cached, err := loadContentWhenItsCached(item.VideoID, item.Language); err == nil && cached.Vid != "" {
+			status = videostate.VideoStatus(cached.Status[item.Language])
+			metadata = &cached
+		}
+		if status != videostate.StatusSummarizeProcessed {
+			if failed, err := dataStore.GetObject(summaryFailureKey(item.VideoID, item.Language)); err == nil && failed != "" {
+				status = videostate.VideoStatus(failed)
+			}
+		}
+	}
+
+	itemStatus := SummaryBatchItemStatus{
+		VideoID:  item.VideoID,
+		Language: item.Language,
+		State:    batchItemProcessing,
+	}
+	if status == videostate.StatusSummarizeProcessed {
+		itemStatus.State = batchItemCompleted
+	} else if isFinalStatus(status) {
+		itemStatus.State = batchItemFailed
+	}
+	if metadata != nil {
+		itemStatus.Result = buildSummaryResponse(item.VideoID, item.Language, metadata, videoQueue.CanBeRetried(item.VideoID, item.Language))
+	}
+	return itemStatus
+}
+
+func buildSummaryBatchResponse(batch *SummaryBatch) SummaryBatchResponse {
+	response := SummaryBatchResponse{
+		ID:         batch.ID,
+		CreatedAt:  batch.CreatedAt,
+		PlaylistID: batch.PlaylistID,
+		Title:      batch.Title,
+		Total:      len(batch.Items),
+		Items:      make([]SummaryBatchItemStatus, 0, len(batch.Items)),
+	}
+	for _, item := range batch.Items {
+		itemStatus := summaryBatchItemStatus(item)
+		switch itemStatus.State {
+		case batchItemCompleted:
+			response.Completed++
+		case batchItemFailed:
+			response.Failed++
+		default:
+			response.Processing++
+		}
+		response.Items = append(response.Items, itemStatus)
+	}
+	response.Done = response.Processing == 0
+	return response
+}
+
+// handleCreateSummaryBatch enqueues several videos at once.
+// POST /summary/batch {"items": [{"videoId": "...", "language": "en"}, ...]}
+func handleCreateSummaryBatch(w http.ResponseWriter, r *http.Request) {
+	var requestBody struct {
+		Items []SummaryBatchItem `json:"items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(requestBody.Items) == 0 {
+		http.Error(w, "Batch has no items", http.StatusBadRequest)
+		return
+	}
+	if len(requestBody.Items) > maxBatchItems {
+		http.Error(w, fmt.Sprintf("Batch exceeds %d items", maxBatchItems), http.StatusBadRequest)
+		return
+	}
+
+	// Validate everything before enqueueing anything, and drop duplicated pairs
+	seen := make(map[SummaryBatchItem]bool)
+	items := make([]SummaryBatchItem, 0, len(requestBody.Items))
+	for i, item := range requestBody.Items {
+		if item.Language == "" {
+			http.Error(w, fmt.Sprintf("Item %d: missing language", i), http.StatusBadRequest)
+			return
+		}
+		videoID, err := extractVideoID(fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.VideoID))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Item %d: error extracting video ID: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		item.VideoID = videoID
+		if seen[item] {
+			continue
+		}
+		seen[item] = true
+		items = append(items, item)
+	}
+
+	batchID, err := newBatchID()
+	if err != nil {
+		http.Error(w, "Failed to create batch", http.StatusInternalServerError)
+		return
+	}
+	batch := &SummaryBatch{
+		ID:        batchID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Items:     items,
+	}
+	if err := saveSummaryBatch(batch); err != nil {
+		// The batch is still queryable from memory until the next restart
+		log.Printf("❌ Failed to persist batch %s: %v", batchID, err)
+	}
+
+	for _, item := range items {
+		enqueueBatchItem(item.VideoID, item.Language)
+	}
+	log.Printf("📦 Batch %s enqueued %d videos", batchID, len(items))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(buildSummaryBatchResponse(batch))
+}
+
+// handleGetSummaryBatch returns the aggregated status of a batch and the result of each item.
+// GET /summary/batch/{batchId}
+func handleGetSummaryBatch(w http.ResponseWriter, r *http.Request) {
+	batch, err := loadSummaryBatch(r.PathValue("batchId"))
+	if errors.Is(err, store.ErrObjectNotFound) {
+		http.Error(w, "Batch not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to load batch %s: %v", r.PathValue("batchId"), err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildSummaryBatchResponse(batch))
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"my_lambda_app/videostate"
+)
+
+func TestHandleCreateSummaryBatch(t *testing.T) {
+	resetVideoQueue()
+	var enqueued []SummaryBatchItem
+	enqueueBatchItem = func(videoID string, lang string) {
+		enqueued = append(enqueued, SummaryBatchItem{VideoID: videoID, Language: lang})
+		videoQueue.Add(videostate.ProcessingVideo{VideoID: videoID, Language: lang})
+		videoQueue.SetStatus(videoID, lang, videostate.StatusPending)
+	}
+	defer func() {
+		enqueueBatchItem = func(videoID string, lang string) {
+			enqueueSummary(videoID, lang, "download-and-digest", false)
+		}
+	}()
+
+	body := `{"items":[{"videoId":"batchVid001","language":"en"},{"videoId":"batchVid002","language":"pt"},{"videoId":"batchVid001","language":"en"}]}`
+	req := httptest.NewRequest("POST", "/summary/batch", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created SummaryBatchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if created.ID == "" {
+		t.Fatal("Expected a batch ID")
+	}
+	if len(enqueued) != 2 || created.Total != 2 || created.Processing != 2 || created.Done {
+		t.Errorf("Expected 2 deduplicated processing items, got enqueued=%v response=%+v", enqueued, created)
+	}
+
+	// One item finishes, the other fails
+	videoQueue.SetStatus("batchVid001", "en", videostate.StatusSummarizeProcessed)
+	videoQueue.SetStatus("batchVid002", "pt", videostate.StatusMetadataTTlExceeded)
+
+	req = httptest.NewRequest("GET", "/summary/batch/"+created.ID, nil)
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+
+	var got SummaryBatchResponse
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if got.Completed != 1 || got.Failed != 1 || got.Processing != 0 || !got.Done {
+		t.Errorf("Unexpected aggregate %+v", got)
+	}
+	if got.Items[0].State != batchItemCompleted || got.Items[0].Result == nil {
+		t.Errorf("Expected first item completed with a result, got %+v", got.Items[0])
+	}
+
+	// The failed item has no summary in the store, its failure outlives the queue
+	resetVideoQueue()
+	req = httptest.NewRequest("GET", "/summary/batch/"+created.ID, nil)
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	got = SummaryBatchResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if got.Items[1].State != batchItemFailed {
+		t.Errorf("Expected the failed item to stay failed after leaving the queue, got %+v", got.Items[1])
+	}
+
+	// The batch is read back from the store once it is no longer cached
+	summaryBatchesMu.Lock()
+	delete(summaryBatches, created.ID)
+	summaryBatchesMu.Unlock()
+	if _, err := loadSummaryBatch(created.ID); err != nil {
+		t.Errorf("Expected batch to be loaded from the store: %v", err)
+	}
+}
+
+func TestHandleCreateSummaryBatch_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		body string
+	}{
+		{name: "Invalid JSON", body: `{`},
+		{name: "No items", body: `{"items":[]}`},
+		{name: "Missing language", body: `{"items":[{"videoId":"batchVid001"}]}`},
+		{name: "Invalid video ID", body: `{"items":[{"videoId":"short","language":"en"}]}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/summary/batch", strings.NewReader(tc.body))
+			rec := httptest.NewRecorder()
+			newRouter().ServeHTTP(rec, req)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected 400, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestHandleGetSummaryBatch_NotFound(t *testing.T) {
+	req := httptest.NewRequest("GET", "/summary/batch/doesnotexist", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleGetSummaryBatch_Unreadable(t *testing.T) {
+	if err := dataStore.PutObject(summaryBatchKey("corruptBatch"), "{not json"); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	req := httptest.NewRequest("GET", "/summary/batch/corruptBatch", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for a batch the store cannot read, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"my_lambda_app/store"
+)
+
+const maxChannelPageSize = 50
+
+type ChannelVideosResponse struct {
+	ChannelID   string                                       `json:"channel_id"`
+	ChannelName string                                       `json:"channel_name,omitempty"`
+	Lang        string                                       `json:"lang"`
+	VideoCount  int                                          `json:"video_count"`
+	Videos      []HandleSummarySingleLanguageRequestResponse `json:"videos"`
+	NextCursor  string                                       `json:"next_cursor,omitempty"`
+}
+
+// handleChannelSummaryRequest lists the summarized videos of a channel, newest upload first.
+// GET /summary/channel/{channelId}?lang=en&limit=20&cursor=...
+func handleChannelSummaryRequest(w http.ResponseWriter, r *http.Request) {
+	channelID := r.PathValue("channelId")
+	lang := r.URL.Query().Get("lang")
+	if channelID == "" || lang == "" {
+		http.Error(w, "Missing 'channelId' or 'lang'", http.StatusBadRequest)
+		return
+	}
+
+	limit := 20
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 {
+		limit = min(val, maxChannelPageSize)
+	}
+
+	items, nextCursor, err := dataStore.VideosByChannel(channelID, lang, limit, r.URL.Query().Get("cursor"))
+	if errors.Is(err, store.ErrInvalidCursor) {
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching videos by channel: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	count, err := dataStore.CountVideosByChannel(channelID, lang)
+	if err != nil {
+		log.Printf("Error counting videos by channel: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response := ChannelVideosResponse{
+		ChannelID:  channelID,
+		Lang:       lang,
+		VideoCount: count,
+		Videos:     make([]HandleSummarySingleLanguageRequestResponse, len(items)),
+		NextCursor: nextCursor,
+	}
+	for i, item := range items {
+		response.Videos[i] = convertMetadataToHandleSummaryRequestResponse(item, lang)
+		if response.ChannelName == "" {
+			response.ChannelName = item.ChannelName
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
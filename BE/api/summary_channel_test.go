@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my_lambda_app/videostate"
+)
+
+func TestHandleChannelSummaryRequest(t *testing.T) {
+	completed := map[string]string{"pt": string(videostate.StatusSummarizeProcessed)}
+	for _, v := range []videostate.Metadata{
+		{Vid: "chanApi0001", ChannelId: "UCapiTest", ChannelName: "Channel", UploadDate: "2025-01-01", Status: completed, Title: map[string]string{"pt": "Primeiro"}},
+		{Vid: "chanApi0002", ChannelId: "UCapiTest", ChannelName: "Channel", UploadDate: "2025-02-01", Status: completed, Title: map[string]string{"pt": "Segundo"}},
+	} {
+		if err := dataStore.PutMetadata(v); err != nil {
+			t.Fatalf("PutMetadata: %v", err)
+		}
+	}
+
+	get := func(url string) (*httptest.ResponseRecorder, ChannelVideosResponse) {
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		var response ChannelVideosResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Decode response: %v", err)
+			}
+		}
+		return rec, response
+	}
+
+	rec, first := get("/summary/channel/UCapiTest?lang=pt&limit=1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if first.ChannelName != "Channel" || first.VideoCount != 2 || len(first.Videos) != 1 || first.Videos[0].Title != "Segundo" {
+		t.Errorf("Unexpected first page %+v", first)
+	}
+	if first.NextCursor == "" {
+		t.Fatal("Expected a cursor for the second page")
+	}
+
+	_, second := get("/summary/channel/UCapiTest?lang=pt&limit=1&cursor=" + first.NextCursor)
+	if len(second.Videos) != 1 || second.Videos[0].Title != "Primeiro" || second.NextCursor != "" {
+		t.Errorf("Unexpected second page %+v", second)
+	}
+
+	if rec, _ := get("/summary/channel/UCapiTest?lang=pt&cursor=bad"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid cursor, got %d", rec.Code)
+	}
+	if rec, _ := get("/summary/channel/UCapiTest"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without lang, got %d", rec.Code)
+	}
+}
+
+func TestHandleCategorySummaryRequest_Pagination(t *testing.T) {
+	for i, vid := range []string{"catApi00001", "catApi00002", "catApi00003"} {
+		meta := videostate.Metadata{
+			Vid:       vid,
+			Category:  "Gaming",
+			ViewCount: 100 * (i + 1),
+			Title:     map[string]string{"ko": vid},
+		}
+		if err := dataStore.PutCategoryStats(meta, "ko"); err != nil {
+			t.Fatalf("PutCategoryStats: %v", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	url := "/summary/category?category=Gaming&lang=ko&min_views=150&limit=1"
+	for page := 0; page < 5; page++ {
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rec.Code)
+		}
+		var response CategoryVideosResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Decode response: %v", err)
+		}
+		if len(response.Videos) != 1 {
+			t.Fatalf("Expected a full page, got %+v", response)
+		}
+		seen[response.Videos[0].VideoID] = true
+		if response.NextCursor == "" {
+			break
+		}
+		url = "/summary/category?category=Gaming&lang=ko&min_views=150&limit=1&cursor=" + response.NextCursor
+	}
+
+	if len(seen) != 2 || seen["catApi00001"] {
+		t.Errorf("Expected the 2 videos above min_views, got %v", seen)
+	}
+
+	// min_likes is still accepted, it always counted views
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/summary/category?category=Gaming&lang=ko&min_likes=250", nil))
+	var response CategoryVideosResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if len(response.Videos) != 1 || response.Videos[0].VideoID != "catApi00003" {
+		t.Errorf("Expected only catApi00003 for min_likes=250, got %+v", response.Videos)
+	}
+}
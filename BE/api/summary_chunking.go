@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"my_lambda_app/subtitle"
+)
+
+// Captions longer than the window of the model are summarized chunk by chunk (map) and the
+// partial summaries are merged into the prompt1 format (reduce).
+var llmChunkWindows = map[string]time.Duration{
+	"gemini-2.0-flash": 45 * time.Minute,
+	"deepseekr1":       15 * time.Minute,
+}
+
+const defaultChunkWindow = 20 * time.Minute
+
+// maxParallelChunks bounds the concurrent llm-model calls of one video
+const maxParallelChunks = 4
+
+var envNameUnsafe = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// captionChunk is a run of cues sent to the model in one call, its text is SRT
+type captionChunk = subtitle.Cue
+
+// chunkWindow returns the caption duration one call of the model can handle,
+// LLM_CHUNK_MINUTES_{MODEL} (e.g. LLM_CHUNK_MINUTES_GEMINI_2_0_FLASH=30) overrides it.
+func chunkWindow(model string) time.Duration {
+	envName := "LLM_CHUNK_MINUTES_" + strings.ToUpper(envNameUnsafe.ReplaceAllString(model, "_"))
+	if minutes, err := strconv.Atoi(os.Getenv(envName)); err == nil && minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	if window, ok := llmChunkWindows[model]; ok {
+		return window
+	}
+	return defaultChunkWindow
+}
+
+// splitCaptionByWindow groups the cues of an SRT or WebVTT caption in chunks of at most
+// window, rendered back as SRT. The cues keep their original timestamps so the partial
+// summaries point to the right moment of the video. A caption without cues is one chunk.
+func splitCaptionByWindow(caption string, window time.Duration) []captionChunk {
+	cues, err := subtitle.Parse(caption)
+	if err != nil {
+		if text := strings.TrimSpace(caption); text != "" {
+			return []captionChunk{{Text: text}}
+		}
+		return nil
+	}
+
+	var chunks []captionChunk
+	first := 0
+	for i := 1; i <= len(cues); i++ {
+		if i < len(cues) && cues[i].Start < cues[first].Start+window {
+			continue
+		}
+		chunk := captionChunk{Start: cues[first].Start, Text: strings.TrimSpace(subtitle.FormatSRT(cues[first:i]))}
+		for _, cue := range cues[first:i] {
+			chunk.End = max(chunk.End, cue.End)
+		}
+		chunks = append(chunks, chunk)
+		first = i
+	}
+	return chunks
+}
+
+// summarizeLongCaption summarizes every chunk with the "chunk" template and merges the
+// partial summaries with the "merge" template, which answers in the prompt1 format.
+func summarizeLongCaption(caption string, lang string, title string, model string, window time.Duration) (string, error) {
+	chunks := splitCaptionByWindow(caption, window)
+	log.Printf("✂️ Caption split in %d chunks of %s for %s", len(chunks), window, model)
+
+	partials := make([]string, len(chunks))
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, maxParallelChunks)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk captionChunk) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			response, err := callLLMModel(model, "chunk", "", LLMInput{Language: lang, Title: title, Captions: chunk.Text})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			partials[i], errs[i] = llmResponseText(response)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	var merged strings.Builder
+	for i, chunk := range chunks {
+		if errs[i] != nil {
+			return "", fmt.Errorf("failed to summarize chunk %d/%d: %w", i+1, len(chunks), errs[i])
+		}
+		fmt.Fprintf(&merged, "## Part %d (%s - %s)\n%s\n\n", i+1, subtitle.FormatTimestamp(chunk.Start, ""), subtitle.FormatTimestamp(chunk.End, ""), strings.TrimSpace(partials[i]))
+	}
+
+	response, err := callLLMModel(model, "merge", llmOutputJSON, LLMInput{Language: lang, Title: title, Captions: strings.TrimSpace(merged.String())})
+	if err != nil {
+		return "", fmt.Errorf("failed to merge %d chunk summaries: %w", len(chunks), err)
+	}
+	return llmResponseText(response)
+}
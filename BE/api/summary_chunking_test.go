@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"my_lambda_app/subtitle"
+)
+
+// longSRT builds one cue per minute for the given number of minutes
+func longSRT(minutes int) string {
+	var b strings.Builder
+	for i := 0; i < minutes; i++ {
+		fmt.Fprintf(&b, "%d\n%s,000 --> %s,500\nminute %d\n\n", i+1, subtitle.FormatTimestamp(time.Duration(i)*time.Minute, ""), subtitle.FormatTimestamp(time.Duration(i)*time.Minute+30*time.Second, ""), i)
+	}
+	return b.String()
+}
+
+func TestSplitCaptionByWindow(t *testing.T) {
+	chunks := splitCaptionByWindow(longSRT(50), 20*time.Minute)
+	if len(chunks) != 3 {
+		t.Fatalf("got %d chunks, want 3", len(chunks))
+	}
+
+	wantBounds := [][2]string{{"00:00:00", "00:19:30"}, {"00:20:00", "00:39:30"}, {"00:40:00", "00:49:30"}}
+	for i, chunk := range chunks {
+		if got := [2]string{subtitle.FormatTimestamp(chunk.Start, ""), subtitle.FormatTimestamp(chunk.End, "")}; got != wantBounds[i] {
+			t.Errorf("chunk %d bounds = %v, want %v", i, got, wantBounds[i])
+		}
+	}
+	// Cues keep their original timestamps
+	if !strings.Contains(chunks[1].Text, "00:20:00,000 --> 00:20:30,500\nminute 20") {
+		t.Errorf("chunk 1 lost its timestamps:\n%s", chunks[1].Text)
+	}
+	if strings.Contains(chunks[1].Text, "minute 19\n") || strings.Contains(chunks[1].Text, "minute 40") {
+		t.Errorf("chunk 1 has cues of other windows:\n%s", chunks[1].Text)
+	}
+
+	if chunks := splitCaptionByWindow(longSRT(5), 20*time.Minute); len(chunks) != 1 {
+		t.Errorf("short caption got %d chunks, want 1", len(chunks))
+	}
+
+	vtt := "WEBVTT\n\n00:00:01.000 --> 00:00:04.000\nhello\n\n00:30:00.000 --> 00:30:02.000\nlater\n"
+	if chunks := splitCaptionByWindow(vtt, 20*time.Minute); len(chunks) != 2 || !strings.Contains(chunks[1].Text, "00:30:00,000 --> 00:30:02,000\nlater") {
+		t.Errorf("WebVTT caption got chunks %+v, want 2 SRT chunks", chunks)
+	}
+	if chunks := splitCaptionByWindow("a transcript without timings", 20*time.Minute); len(chunks) != 1 || chunks[0].Text != "a transcript without timings" {
+		t.Errorf("caption without cues got chunks %+v, want it as one chunk", chunks)
+	}
+}
+
+func TestChunkWindow(t *testing.T) {
+	if got := chunkWindow("gemini-2.0-flash"); got != 45*time.Minute {
+		t.Errorf("chunkWindow(gemini) = %s", got)
+	}
+	if got := chunkWindow("unknown-model"); got != defaultChunkWindow {
+		t.Errorf("chunkWindow(unknown) = %s", got)
+	}
+	t.Setenv("LLM_CHUNK_MINUTES_GEMINI_2_0_FLASH", "10")
+	if got := chunkWindow("gemini-2.0-flash"); got != 10*time.Minute {
+		t.Errorf("chunkWindow with env = %s", got)
+	}
+}
+
+type llmCall struct {
+	template string
+	output   string
+	caption  string
+	question string
+}
+
+// stubLLMModel records the llm-model calls and answers with answer, or with the template
+// name when answer is nil
+func stubLLMModel(t *testing.T, answer func(template string, caption string) string) *[]llmCall {
+	var mu sync.Mutex
+	calls := []llmCall{}
+	original := callLLMModel
+	callLLMModel = func(model, template, output string, input LLMInput) (*SummarizeResponse, error) {
+		mu.Lock()
+		calls = append(calls, llmCall{template, output, input.Captions, input.Question})
+		mu.Unlock()
+		text := template + " answer"
+		if answer != nil {
+			text = answer(template, input.Captions)
+		}
+		result, _ := json.Marshal(text)
+		return &SummarizeResponse{Result: result}, nil
+	}
+	t.Cleanup(func() { callLLMModel = original })
+	return &calls
+}
+
+func TestSummarizeText_LongCaptionIsChunked(t *testing.T) {
+	calls := stubLLMModel(t, nil)
+	t.Setenv("LLM_CHUNK_MINUTES_GEMINI_2_0_FLASH", "20")
+
+	text, err := summarizeText(longSRT(50), "en", "A long talk")
+	if err != nil {
+		t.Fatalf("summarizeText() error = %v", err)
+	}
+	if text != "merge answer" {
+		t.Errorf("summarizeText() = %q, want the merge answer", text)
+	}
+
+	if len(*calls) != 4 {
+		t.Fatalf("got %d llm-model calls, want 3 chunks + 1 merge", len(*calls))
+	}
+	merge := (*calls)[3]
+	if merge.template != "merge" || merge.output != llmOutputJSON {
+		t.Fatalf("last call = %+v, want the JSON merge", merge)
+	}
+	for _, part := range []string{"## Part 1 (00:00:00 - 00:19:30)", "## Part 2 (00:20:00 - 00:39:30)", "## Part 3 (00:40:00 - 00:49:30)"} {
+		if !strings.Contains(merge.caption, part+"\nchunk answer") {
+			t.Errorf("merge input is missing %q:\n%s", part, merge.caption)
+		}
+	}
+	for _, call := range (*calls)[:3] {
+		if call.template != "chunk" {
+			t.Errorf("call %+v, want a chunk call", call)
+		}
+	}
+}
+
+func TestSummarizeText_ShortCaptionSingleCall(t *testing.T) {
+	calls := stubLLMModel(t, nil)
+
+	text, err := summarizeText(longSRT(5), "en", "A short talk")
+	if err != nil {
+		t.Fatalf("summarizeText() error = %v", err)
+	}
+	if text != "prompt1 answer" || len(*calls) != 1 || (*calls)[0].template != "prompt1" {
+		t.Errorf("summarizeText() = %q with calls %+v, want one prompt1 call", text, *calls)
+	}
+}
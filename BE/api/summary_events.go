@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"my_lambda_app/events"
+	"my_lambda_app/videostate"
+)
+
+const (
+	summaryEventStatus = "status"
+	summaryEventResult = "result"
+)
+
+// How long an events stream stays open without the job reaching a final status.
+const summaryEventsMaxDuration = 10 * time.Minute
+const summaryEventsHeartbeat = 15 * time.Second
+
+var summaryEvents = events.NewBroker(16)
+
+type SummaryStatusEvent struct {
+	VideoID string `json:"videoId"`
+	Lang    string `json:"lang"`
+	Status  string `json:"status"`
+}
+
+func summaryEventsTopic(videoID string, lang string) string {
+	return fmt.Sprintf("%s#%s", videoID, lang)
+}
+
+func isFinalStatus(status videostate.VideoStatus) bool {
+	return status.IsFinal()
+}
+
+// publishSummaryStatus is registered on videoQueue and forwards every status transition,
+// followed by the full response once the job reaches a final status.
+func publishSummaryStatus(videoID string, language string, status videostate.VideoStatus) {
+	topic := summaryEventsTopic(videoID, language)
+	summaryEvents.Publish(topic, events.Event{
+		Type: summaryEventStatus,
+		Data: SummaryStatusEvent{VideoID: videoID, Lang: language, Status: string(status)},
+	})
+
+	if isFinalStatus(status) {
+		metadata := videoQueue.GetVideoMeta(videoID, language)
+		summaryEvents.Publish(topic, events.Event{
+			Type: summaryEventResult,
+			Data: buildSummaryResponse(videoID, language, metadata, videoQueue.CanBeRetried(videoID, language)),
+		})
+	}
+}
+
+func writeSSE(w http.ResponseWriter, flusher http.Flusher, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// handleSummaryEvents streams the progress of a summary job as Server-Sent Events.
+// GET /summary/{videoId}/{lang}/events
+func handleSummaryEvents(w http.ResponseWriter, r *http.Request) {
+	videoID := r.PathValue("videoId")
+	lang := r.PathValue("lang")
+	if videoID == "" || lang == "" {
+		http.Error(w, "Missing videoId or lang", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before reading the current state so no transition is missed in between
+	stream, unsubscribe := summaryEvents.Subscribe(summaryEventsTopic(videoID, lang))
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable nginx buffering
+	w.WriteHeader(http.StatusOK)
+
+	// Initial snapshot: the queue when the job is in flight, the store otherwise
+	var status videostate.VideoStatus
+	var metadata *videostate.Metadata
+	if videoQueue.Exists(videoID, lang) {
+		status = videoQueue.GetStatus(videoID, lang)
+		metadata = videoQueue.GetVideoMeta(videoID, lang)
+	} else if cached, err := loadContentWhenItsCached(videoID, lang); err == nil && cached.Vid != "" {
+		status = videostate.VideoStatus(cached.Status[lang])
+		metadata = &cached
+	}
+
+	if status != "" {
+		err := writeSSE(w, flusher, events.Event{
+			Type: summaryEventStatus,
+			Data: SummaryStatusEvent{VideoID: videoID, Lang: lang, Status: string(status)},
+		})
+		if err != nil {
+			return
+		}
+	}
+	if isFinalStatus(status) {
+		writeSSE(w, flusher, events.Event{
+			Type: summaryEventResult,
+			Data: buildSummaryResponse(videoID, lang, metadata, videoQueue.CanBeRetried(videoID, lang)),
+		})
+		return
+	}
+
+	heartbeat := time.NewTicker(summaryEventsHeartbeat)
+	defer heartbeat.Stop()
+	deadline := time.NewTimer(summaryEventsMaxDuration)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			log.Printf("⏱️ Closing events stream for %s (%s) after %s", videoID, lang, summaryEventsMaxDuration)
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-stream:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, flusher, event); err != nil {
+				return
+			}
+			if event.Type == summaryEventResult {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"my_lambda_app/videostate"
+)
+
+func TestHandleSummaryEvents_FinishedJob(t *testing.T) {
+	resetVideoQueue()
+	videoQueue.Add(videostate.ProcessingVideo{
+		VideoID:  "evtDone0001",
+		Language: "en",
+		Metadata: videostate.Metadata{
+			Title:   map[string]string{"en": "Done"},
+			Summary: map[string]string{"en": "Some content"},
+		},
+	})
+	videoQueue.SetStatus("evtDone0001", "en", videostate.StatusSummarizeProcessed)
+
+	req := httptest.NewRequest("GET", "/summary/evtDone0001/en/events", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected text/event-stream, got %q", ct)
+	}
+	if !strings.Contains(body, "event: status\ndata: {\"videoId\":\"evtDone0001\",\"lang\":\"en\",\"status\":\"completed\"}") {
+		t.Errorf("Expected status event, got %q", body)
+	}
+	if !strings.Contains(body, "event: result\n") || !strings.Contains(body, "\"content\":\"Some content\"") {
+		t.Errorf("Expected result event with the summary, got %q", body)
+	}
+}
+
+func TestHandleSummaryEvents_StreamsTransitions(t *testing.T) {
+	resetVideoQueue()
+	videoQueue.Add(videostate.ProcessingVideo{VideoID: "evtLive0001", Language: "pt"})
+
+	server := httptest.NewServer(newRouter())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/summary/evtLive0001/pt/events")
+	if err != nil {
+		t.Fatalf("GET events: %v", err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+
+	readEvent := func() string {
+		var event strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("reading stream: %v", err)
+			}
+			if line == "\n" {
+				return event.String()
+			}
+			event.WriteString(line)
+		}
+	}
+
+	// The snapshot is written before any transition happens
+	if event := readEvent(); !strings.Contains(event, "\"status\":\"processing-pending\"") {
+		t.Fatalf("Expected pending snapshot, got %q", event)
+	}
+
+	videoQueue.SetStatus("evtLive0001", "pt", videostate.StatusMetadataProcessed)
+	videoQueue.SetStatus("evtLive0001", "pt", videostate.StatusSummarizeProcessed)
+
+	for _, want := range []string{"\"status\":\"metadata-processed\"", "\"status\":\"completed\"", "event: result"} {
+		if event := readEvent(); !strings.Contains(event, want) {
+			t.Errorf("Expected %s, got %q", want, event)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"my_lambda_app/store"
+)
+
+const (
+	maxLibraryPageSize = 100
+	maxLibraryTags     = 20
+	maxLibraryTagSize  = 32
+	maxLibraryNoteSize = 2000
+)
+
+// Fixed width so library timestamps sort as strings.
+const libraryTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type LibraryItemResponse struct {
+	store.LibraryItem
+	Title       string `json:"title"`
+	Path        string `json:"path"`
+	Answer      string `json:"answer"`
+	ChannelName string `json:"channel_name,omitempty"`
+	Duration    int    `json:"duration,omitempty"`
+	Status      string `json:"status,omitempty"`
+}
+
+type LibraryResponse struct {
+	Items []LibraryItemResponse `json:"items"`
+	Total int                   `json:"total"`
+	Tags  []string              `json:"tags"`
+}
+
+// requireUser writes a 401 and returns nil when the request has no session.
+func requireUser(w http.ResponseWriter, r *http.Request) *store.User {
+	user, err := currentUser(r)
+	if err != nil {
+		log.Printf("❌ Failed to load the current user: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return nil
+	}
+	if user == nil {
+		http.Error(w, "Not signed in", http.StatusUnauthorized)
+		return nil
+	}
+	return user
+}
+
+// recordLibraryHistory adds a summarized video to the library of a user, or bumps it
+// to the top when it is already there. POST /summary calls it when it enqueues the job,
+// not on every poll.
+func recordLibraryHistory(userID string, videoID string, lang string) {
+	now := time.Now().UTC().Format(libraryTimeLayout)
+	item, err := dataStore.GetLibraryItem(userID, videoID, lang)
+	if err != nil {
+		log.Printf("❌ Failed to read library of %s: %v", userID, err)
+		return
+	}
+	if item == nil {
+		item = &store.LibraryItem{UserID: userID, Vid: videoID, Lang: lang, AddedAt: now}
+	}
+	item.UpdatedAt = now
+	if err := dataStore.PutLibraryItem(*item); err != nil {
+		log.Printf("❌ Failed to save library of %s: %v", userID, err)
+	}
+}
+
+// normalizeTags lowercases and trims tags, dropping empty and duplicated ones.
+func normalizeTags(tags []string) []string {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+func queryBool(r *http.Request, name string) bool {
+	value := strings.ToLower(r.URL.Query().Get(name))
+	return value == "true" || value == "1" || value == "yes"
+}
+
+// handleListLibrary lists the library of the signed in user, most recent activity first.
+// GET /library?lang=en&tag=go&favorite=true&saved=true&limit=50&offset=0
+func handleListLibrary(w http.ResponseWriter, r *http.Request) {
+	user := requireUser(w, r)
+	if user == nil {
+		return
+	}
+
+	items, err := dataStore.LibraryItems(user.ID)
+	if err != nil {
+		log.Printf("❌ Failed to read library of %s: %v", user.ID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	tag := strings.ToLower(r.URL.Query().Get("tag"))
+	favorite := queryBool(r, "favorite")
+	saved := queryBool(r, "saved")
+
+	tags := []string{}
+	filtered := items[:0]
+	for _, item := range items {
+		if lang != "" && item.Lang != lang {
+			continue
+		}
+		for _, t := range item.Tags {
+			if !slices.Contains(tags, t) {
+				tags = append(tags, t)
+			}
+		}
+		if (tag != "" && !slices.Contains(item.Tags, tag)) || (favorite && !item.Favorite) || (saved && !item.Saved) {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	sort.Strings(tags)
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].UpdatedAt > filtered[j].UpdatedAt
+	})
+
+	limit := 50
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 {
+		limit = min(val, maxLibraryPageSize)
+	}
+	offset := 0
+	if val, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && val > 0 {
+		offset = min(val, len(filtered))
+	}
+	page := filtered[offset:min(offset+limit, len(filtered))]
+
+	response := LibraryResponse{
+		Items: make([]LibraryItemResponse, 0, len(page)),
+		Total: len(filtered),
+		Tags:  tags,
+	}
+	for _, item := range page {
+		itemResponse := LibraryItemResponse{LibraryItem: item}
+		if metadata, err := dataStore.GetMetadata(item.Vid); err == nil && metadata != nil {
+			itemResponse.Title = metadata.Title[item.Lang]
+			itemResponse.Path = metadata.Path[item.Lang]
+			itemResponse.Answer = metadata.Answer[item.Lang]
+			itemResponse.ChannelName = metadata.ChannelName
+			itemResponse.Duration = metadata.Duration
+			itemResponse.Status = metadata.Status[item.Lang]
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// handleUpdateLibraryItem saves a video to the library or changes its flags, notes and tags.
+// Only the fields present in the body are changed.
+// PUT /library/{videoId}/{lang} {"saved": true, "favorite": true, "notes": "...", "tags": ["go"]}
+func handleUpdateLibraryItem(w http.ResponseWriter, r *http.Request) {
+	user := requireUser(w, r)
+	if user == nil {
+		return
+	}
+
+	var requestBody struct {
+		Saved    *bool     `json:"saved"`
+		Favorite *bool     `json:"favorite"`
+		Notes    *string   `json:"notes"`
+		Tags     *[]string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	videoID, err := extractVideoID("https://www.youtube.com/watch?v=" + r.PathValue("videoId"))
+	if err != nil {
+		http.Error(w, "Invalid videoId", http.StatusBadRequest)
+		return
+	}
+	lang := r.PathValue("lang")
+
+	now := time.Now().UTC().Format(libraryTimeLayout)
+	item, err := dataStore.GetLibraryItem(user.ID, videoID, lang)
+	if err != nil {
+		log.Printf("❌ Failed to read library of %s: %v", user.ID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if item == nil {
+		item = &store.LibraryItem{UserID: user.ID, Vid: videoID, Lang: lang, AddedAt: now}
+	}
+
+	if requestBody.Saved != nil {
+		item.Saved = *requestBody.Saved
+	}
+	if requestBody.Favorite != nil {
+		item.Favorite = *requestBody.Favorite
+	}
+	if requestBody.Notes != nil {
+		if len(*requestBody.Notes) > maxLibraryNoteSize {
+			http.Error(w, "Notes are too long", http.StatusBadRequest)
+			return
+		}
+		item.Notes = *requestBody.Notes
+	}
+	if requestBody.Tags != nil {
+		tags := normalizeTags(*requestBody.Tags)
+		if len(tags) > maxLibraryTags || slices.ContainsFunc(tags, func(tag string) bool { return len(tag) > maxLibraryTagSize }) {
+			http.Error(w, "Too many or too long tags", http.StatusBadRequest)
+			return
+		}
+		item.Tags = tags
+	}
+	item.UpdatedAt = now
+
+	if err := dataStore.PutLibraryItem(*item); err != nil {
+		log.Printf("❌ Failed to save library of %s: %v", user.ID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// handleDeleteLibraryItem removes a video from the library.
+// DELETE /library/{videoId}/{lang}
+func handleDeleteLibraryItem(w http.ResponseWriter, r *http.Request) {
+	user := requireUser(w, r)
+	if user == nil {
+		return
+	}
+
+	if err := dataStore.DeleteLibraryItem(user.ID, r.PathValue("videoId"), r.PathValue("lang")); err != nil {
+		log.Printf("❌ Failed to delete from library of %s: %v", user.ID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"my_lambda_app/store"
+	"my_lambda_app/videostate"
+)
+
+// signIn creates an account and returns its session cookie.
+func signIn(t *testing.T, userID string) *http.Cookie {
+	t.Helper()
+	if err := dataStore.PutUser(store.User{ID: userID, Email: userID + "@example.com"}); err != nil {
+		t.Fatalf("PutUser: %v", err)
+	}
+	value, err := sessionSigner.Encode(userID, time.Hour)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return &http.Cookie{Name: sessionCookie, Value: value}
+}
+
+func TestLibrary_HistoryTagsAndRemove(t *testing.T) {
+	userID := "library-user-" + time.Now().Format("150405.000000000")
+	session := signIn(t, userID)
+	dataStore.PutMetadata(videostate.Metadata{
+		Vid:    "libVideo001",
+		Title:  map[string]string{"en": "Library video"},
+		Path:   map[string]string{"en": "library-video"},
+		Status: map[string]string{"en": string(videostate.StatusSummarizeProcessed)},
+	})
+
+	request := func(method string, url string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.AddCookie(session)
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, req)
+		return rec
+	}
+	list := func(query string) LibraryResponse {
+		rec := request("GET", "/library"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", rec.Code)
+		}
+		var response LibraryResponse
+		json.NewDecoder(rec.Body).Decode(&response)
+		return response
+	}
+
+	recordLibraryHistory(userID, "libVideo001", "en")
+	recordLibraryHistory(userID, "libVideo002", "pt")
+
+	library := list("?lang=en")
+	if library.Total != 1 || library.Items[0].Vid != "libVideo001" || library.Items[0].Title != "Library video" || library.Items[0].Path != "library-video" {
+		t.Fatalf("Unexpected library %+v", library)
+	}
+
+	rec := request("PUT", "/library/libVideo001/en", `{"favorite": true, "notes": "Watch again", "tags": [" Go ", "go", "Talks"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var item store.LibraryItem
+	json.NewDecoder(rec.Body).Decode(&item)
+	if !item.Favorite || item.Notes != "Watch again" || strings.Join(item.Tags, ",") != "go,talks" {
+		t.Errorf("Unexpected item %+v", item)
+	}
+
+	// Recording the video again keeps its flags
+	recordLibraryHistory(userID, "libVideo001", "en")
+	if library := list("?favorite=true"); library.Total != 1 || library.Items[0].Notes != "Watch again" {
+		t.Errorf("Expected the favorite to be kept, got %+v", library)
+	}
+	if library := list("?tag=talks"); library.Total != 1 || strings.Join(library.Tags, ",") != "go,talks" {
+		t.Errorf("Unexpected tag filter %+v", library)
+	}
+	if library := list(""); library.Total != 2 || library.Items[0].Vid != "libVideo001" {
+		t.Errorf("Expected the most recent activity first, got %+v", library)
+	}
+
+	if rec := request("PUT", "/library/bad/en", `{"saved": true}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid video, got %d", rec.Code)
+	}
+
+	if rec := request("DELETE", "/library/libVideo001/en", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	if library := list(""); library.Total != 1 || library.Items[0].Vid != "libVideo002" {
+		t.Errorf("Expected the video to be removed, got %+v", library)
+	}
+}
+
+func TestLibrary_RequiresSession(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/library", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", rec.Code)
+	}
+}
+
+func TestLibrary_HistoryRecordedOncePerEnqueue(t *testing.T) {
+	resetVideoQueue()
+	userID := "library-poll-" + time.Now().Format("150405.000000000")
+	session := signIn(t, userID)
+	dataStore.PutMetadata(videostate.Metadata{
+		Vid:     "libVideo003",
+		Title:   map[string]string{"en": "Polled video"},
+		Summary: map[string]string{"en": "Done"},
+		Status:  map[string]string{"en": string(videostate.StatusSummarizeProcessed)},
+	})
+
+	summarize := func() {
+		req := httptest.NewRequest("POST", "/summary", strings.NewReader(`{"videoId": "libVideo003", "language": "en"}`))
+		req.AddCookie(session)
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	summarize()
+	if item, _ := dataStore.GetLibraryItem(userID, "libVideo003", "en"); item == nil {
+		t.Fatalf("Expected the enqueued video in the library")
+	}
+
+	// Polling the same job does not record it again
+	dataStore.DeleteLibraryItem(userID, "libVideo003", "en")
+	summarize()
+	if item, _ := dataStore.GetLibraryItem(userID, "libVideo003", "en"); item != nil {
+		t.Errorf("Expected the poll not to touch the library, got %+v", item)
+	}
+}
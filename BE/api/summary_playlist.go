@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"my_lambda_app/store"
+)
+
+var playlistMetadataURL = "http://youtube-metadata-server:6060/playlist"
+
+var playlistIDPattern = regexp.MustCompile(`^[0-9A-Za-z_-]{10,64}$`)
+
+type PlaylistMetadata struct {
+	PlaylistID string   `json:"playlist_id"`
+	Title      string   `json:"title"`
+	VideoIDs   []string `json:"video_ids"`
+}
+
+// extractPlaylistID accepts a playlist URL (list= parameter) or a bare playlist ID.
+func extractPlaylistID(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if strings.Contains(input, "list=") {
+		if !strings.Contains(input, "://") {
+			input = "https://" + input
+		}
+		u, err := url.Parse(input)
+		if err != nil {
+			return "", fmt.Errorf("invalid playlist URL: %w", err)
+		}
+		input = u.Query().Get("list")
+	}
+	if !playlistIDPattern.MatchString(input) {
+		return "", fmt.Errorf("invalid playlist ID %q", input)
+	}
+	return input, nil
+}
+
+// The playlist batch ID is derived from the playlist so the overview page of a
+// playlist and language can always be found again.
+func playlistBatchID(playlistID string, lang string) string {
+	return fmt.Sprintf("playlist-%s-%s", playlistID, lang)
+}
+
+// getPlaylistVideos asks the youtube-metadata service to expand a playlist into its video IDs.
+func getPlaylistVideos(playlistID string) (*PlaylistMetadata, error) {
+	query := url.Values{}
+	query.Set("list", playlistID)
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s?%s", playlistMetadataURL, query.Encode()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request playlist: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata server returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read playlist data: %w", err)
+	}
+
+	var playlist PlaylistMetadata
+	if err := json.Unmarshal(data, &playlist); err != nil {
+		return nil, fmt.Errorf("failed to parse playlist JSON: %w", err)
+	}
+	return &playlist, nil
+}
+
+// handleCreatePlaylistSummary expands a playlist and enqueues each of its videos.
+// Submitting the same playlist again returns the existing batch, unless ?refresh=true.
+// POST /summary/playlist {"playlist": "https://www.youtube.com/playlist?list=...", "language": "en"}
+func handleCreatePlaylistSummary(w http.ResponseWriter, r *http.Request) {
+	var requestBody struct {
+		Playlist string `json:"playlist"`
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if requestBody.Language == "" {
+		http.Error(w, "Missing language", http.StatusBadRequest)
+		return
+	}
+	playlistID, err := extractPlaylistID(requestBody.Playlist)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error extracting playlist ID: %v", err), http.StatusBadRequest)
+		return
+	}
+	lang := requestBody.Language
+	batchID := playlistBatchID(playlistID, lang)
+
+	refreshParam := strings.ToLower(r.URL.Query().Get("refresh"))
+	refresh := refreshParam == "true" || refreshParam == "1" || refreshParam == "yes"
+	if !refresh {
+		batch, err := loadSummaryBatch(batchID)
+		if err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(buildSummaryBatchResponse(batch))
+			return
+		}
+		// only a playlist never submitted is expanded, not one the store failed to read
+		if !errors.Is(err, store.ErrObjectNotFound) {
+			log.Printf("❌ Failed to load playlist batch %s: %v", batchID, err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	playlist, err := getPlaylistVideos(playlistID)
+	if err != nil {
+		log.Printf("❌ Failed to expand playlist %s: %v", playlistID, err)
+		http.Error(w, fmt.Sprintf("Error fetching playlist: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	videoIDs := playlist.VideoIDs
+	if len(videoIDs) > maxBatchItems {
+		log.Printf("⚠️ Playlist %s has %d videos, summarizing the first %d", playlistID, len(videoIDs), maxBatchItems)
+		videoIDs = videoIDs[:maxBatchItems]
+	}
+
+	items := make([]SummaryBatchItem, 0, len(videoIDs))
+	for _, vid := range videoIDs {
+		videoID, err := extractVideoID(fmt.Sprintf("https://www.youtube.com/watch?v=%s", vid))
+		if err != nil {
+			continue
+		}
+		items = append(items, SummaryBatchItem{VideoID: videoID, Language: lang})
+	}
+	if len(items) == 0 {
+		http.Error(w, "Playlist has no videos", http.StatusUnprocessableEntity)
+		return
+	}
+
+	batch := &SummaryBatch{
+		ID:         batchID,
+		CreatedAt:  time.Now().UTC().Format(time.RFC3339),
+		PlaylistID: playlistID,
+		Title:      playlist.Title,
+		Items:      items,
+	}
+	if err := saveSummaryBatch(batch); err != nil {
+		log.Printf("❌ Failed to persist playlist batch %s: %v", batchID, err)
+	}
+
+	for _, item := range items {
+		enqueueBatchItem(item.VideoID, item.Language)
+	}
+	log.Printf("📦 Playlist %s enqueued %d videos", playlistID, len(items))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(buildSummaryBatchResponse(batch))
+}
+
+// handleGetPlaylistSummary returns the per-video summaries of a playlist, in playlist order.
+// GET /summary/playlist/{playlistId}?lang=en
+func handleGetPlaylistSummary(w http.ResponseWriter, r *http.Request) {
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		http.Error(w, "Missing lang", http.StatusBadRequest)
+		return
+	}
+	batchID := playlistBatchID(r.PathValue("playlistId"), lang)
+	batch, err := loadSummaryBatch(batchID)
+	if errors.Is(err, store.ErrObjectNotFound) {
+		http.Error(w, "Playlist not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("❌ Failed to load playlist batch %s: %v", batchID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildSummaryBatchResponse(batch))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractPlaylistID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "Playlist URL",
+			input:    "https://www.youtube.com/playlist?list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf",
+			expected: "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf",
+		},
+		{
+			name:     "Watch URL inside a playlist",
+			input:    "https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf&index=2",
+			expected: "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf",
+		},
+		{
+			name:     "URL without scheme",
+			input:    "youtube.com/playlist?list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf",
+			expected: "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf",
+		},
+		{
+			name:     "Bare playlist ID",
+			input:    " PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf ",
+			expected: "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf",
+		},
+		{
+			name:    "Video URL",
+			input:   "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+			wantErr: true,
+		},
+		{
+			name:    "Empty list",
+			input:   "https://www.youtube.com/playlist?list=",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := extractPlaylistID(tc.input)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Expected error: %v, got error: %v", tc.wantErr, err)
+				return
+			}
+
+			if result != tc.expected {
+				t.Errorf("Expected result: %s, got: %s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestHandleCreatePlaylistSummary(t *testing.T) {
+	resetVideoQueue()
+
+	metadataCalls := 0
+	metadataServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metadataCalls++
+		if got := r.URL.Query().Get("list"); got != "PLtestPlaylist01" {
+			t.Errorf("Expected list=PLtestPlaylist01, got %q", got)
+		}
+		w.Write([]byte(`{"playlist_id":"PLtestPlaylist01","title":"My playlist","video_ids":["playVid0001","playVid0002"]}`))
+	}))
+	defer metadataServer.Close()
+
+	defaultURL := playlistMetadataURL
+	playlistMetadataURL = metadataServer.URL
+	var enqueued []string
+	enqueueBatchItem = func(videoID string, lang string) {
+		enqueued = append(enqueued, videoID+"#"+lang)
+	}
+	defer func() {
+		playlistMetadataURL = defaultURL
+		enqueueBatchItem = func(videoID string, lang string) {
+			enqueueSummary(videoID, lang, "download-and-digest", false)
+		}
+	}()
+
+	post := func(path string) SummaryBatchResponse {
+		body := `{"playlist":"https://www.youtube.com/playlist?list=PLtestPlaylist01","language":"en"}`
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted && rec.Code != http.StatusOK {
+			t.Fatalf("Expected 202 or 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var response SummaryBatchResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Decode response: %v", err)
+		}
+		return response
+	}
+
+	// refresh=true expands the playlist even when an earlier run left a batch behind
+	created := post("/summary/playlist?refresh=true")
+	if created.PlaylistID != "PLtestPlaylist01" || created.Title != "My playlist" || created.Total != 2 {
+		t.Errorf("Unexpected playlist response %+v", created)
+	}
+	if len(enqueued) != 2 || enqueued[0] != "playVid0001#en" || enqueued[1] != "playVid0002#en" {
+		t.Errorf("Expected both videos enqueued in order, got %v", enqueued)
+	}
+
+	// Submitting it again reuses the batch instead of expanding the playlist again
+	if again := post("/summary/playlist"); again.ID != created.ID || metadataCalls != 1 || len(enqueued) != 2 {
+		t.Errorf("Expected the existing batch, got %+v after %d metadata calls", again, metadataCalls)
+	}
+
+	req := httptest.NewRequest("GET", "/summary/playlist/PLtestPlaylist01?lang=en", nil)
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"title":"My playlist"`) {
+		t.Errorf("Expected playlist overview, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleCreatePlaylistSummary_UnreadableBatch(t *testing.T) {
+	batchID := playlistBatchID("PLcorruptList1", "en")
+	if err := dataStore.PutObject(summaryBatchKey(batchID), "{not json"); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	var enqueued []string
+	enqueueBatchItem = func(videoID string, lang string) {
+		enqueued = append(enqueued, videoID)
+	}
+	defer func() {
+		enqueueBatchItem = func(videoID string, lang string) {
+			enqueueSummary(videoID, lang, "download-and-digest", false)
+		}
+	}()
+
+	body := `{"playlist":"PLcorruptList1","language":"en"}`
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/summary/playlist", strings.NewReader(body)))
+	if rec.Code != http.StatusInternalServerError || len(enqueued) != 0 {
+		t.Errorf("Expected 500 without enqueueing the playlist again, got %d and %v", rec.Code, enqueued)
+	}
+
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/summary/playlist/PLcorruptList1?lang=en", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 for GET, got %d", rec.Code)
+	}
+}
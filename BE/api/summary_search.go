@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"my_lambda_app/search"
+	"my_lambda_app/videostate"
+)
+
+const maxSearchResults = 50
+
+// searchIndex holds the completed summaries. It is rebuilt from the store at startup,
+// then filled as summaries complete.
+var searchIndex = search.NewIndex()
+
+// rebuildSearchIndex indexes every completed summary of the store.
+func rebuildSearchIndex() error {
+	indexed := 0
+	err := dataStore.ForEachVideo(func(metadata videostate.Metadata) error {
+		for lang := range metadata.Status {
+			if metadata.Status[lang] == string(videostate.StatusSummarizeProcessed) {
+				indexSummary(metadata.Vid, lang, metadata)
+				indexed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rebuild the search index: %w", err)
+	}
+	log.Printf("🔎 Search index rebuilt with %d summaries", indexed)
+	return nil
+}
+
+type SearchResult struct {
+	VideoID string `json:"videoId"`
+	Lang    string `json:"lang"`
+	Title   string `json:"title"`
+	Path    string `json:"path"`
+	Answer  string `json:"answer"`
+	Score   int    `json:"score"`
+}
+
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Lang    string         `json:"lang"`
+	Results []SearchResult `json:"results"`
+}
+
+// indexSummary adds the summary of a video in lang to the search index once it is completed.
+func indexSummary(videoID string, lang string, metadata videostate.Metadata) {
+	if metadata.Status[lang] != string(videostate.StatusSummarizeProcessed) || metadata.Summary[lang] == "" {
+		return
+	}
+	searchIndex.Add(search.Document{
+		VideoID: videoID,
+		Lang:    lang,
+		Title:   metadata.Title[lang],
+		Answer:  metadata.Answer[lang],
+		Content: metadata.Summary[lang],
+		Path:    metadata.Path[lang],
+	})
+}
+
+// handleSearch looks up the published summaries of a language.
+// GET /search?q=...&lang=en&limit=20
+func handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	lang := r.URL.Query().Get("lang")
+	if query == "" || lang == "" {
+		http.Error(w, "Missing 'q' or 'lang'", http.StatusBadRequest)
+		return
+	}
+
+	limit := 20
+	if val, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && val > 0 {
+		limit = min(val, maxSearchResults)
+	}
+
+	response := SearchResponse{
+		Query:   query,
+		Lang:    lang,
+		Results: []SearchResult{},
+	}
+	for _, result := range searchIndex.Search(lang, query, limit) {
+		response.Results = append(response.Results, SearchResult{
+			VideoID: result.VideoID,
+			Lang:    result.Lang,
+			Title:   result.Title,
+			Path:    result.Path,
+			Answer:  result.Answer,
+			Score:   result.Score,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"my_lambda_app/videostate"
+)
+
+func TestHandleSearch(t *testing.T) {
+	completed := map[string]string{"fr": string(videostate.StatusSummarizeProcessed)}
+	indexSummary("searchVid01", "fr", videostate.Metadata{
+		Status:  completed,
+		Title:   map[string]string{"fr": "Recette de la ratatouille"},
+		Answer:  map[string]string{"fr": "Des légumes mijotés"},
+		Summary: map[string]string{"fr": "Couper les courgettes et les aubergines"},
+		Path:    map[string]string{"fr": "recette-de-la-ratatouille"},
+	})
+	// Summaries still processing are not published yet
+	indexSummary("searchVid02", "fr", videostate.Metadata{
+		Status:  map[string]string{"fr": string(videostate.StatusDownloadProcessed)},
+		Title:   map[string]string{"fr": "Ratatouille en cours"},
+		Summary: map[string]string{"fr": "..."},
+	})
+
+	get := func(url string) (*httptest.ResponseRecorder, SearchResponse) {
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		var response SearchResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("Decode response: %v", err)
+			}
+		}
+		return rec, response
+	}
+
+	rec, response := get("/search?q=Ratatouille&lang=fr")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if len(response.Results) != 1 || response.Results[0].VideoID != "searchVid01" || response.Results[0].Path != "recette-de-la-ratatouille" {
+		t.Errorf("Unexpected results %+v", response.Results)
+	}
+
+	if _, response := get("/search?q=courgettes+l%C3%A9gumes&lang=fr"); len(response.Results) != 1 {
+		t.Errorf("Expected a match across answer and summary, got %+v", response.Results)
+	}
+	if _, response := get("/search?q=ratatouille&lang=en"); len(response.Results) != 0 {
+		t.Errorf("Expected no results in another language, got %+v", response.Results)
+	}
+	if rec, _ := get("/search?lang=fr"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without q, got %d", rec.Code)
+	}
+}
+
+func TestRebuildSearchIndex(t *testing.T) {
+	metadata := videostate.Metadata{
+		Vid:     "searchVid03",
+		Status:  map[string]string{"de": string(videostate.StatusSummarizeProcessed), "it": string(videostate.StatusPending)},
+		Title:   map[string]string{"de": "Sauerteigbrot backen", "it": "Pane"},
+		Summary: map[string]string{"de": "Sauerteig über Nacht gehen lassen", "it": "..."},
+		Path:    map[string]string{"de": "sauerteigbrot-backen"},
+	}
+	if err := dataStore.PutMetadata(metadata); err != nil {
+		t.Fatalf("PutMetadata: %v", err)
+	}
+	searchIndex.Remove("searchVid03", "de")
+
+	if err := rebuildSearchIndex(); err != nil {
+		t.Fatalf("rebuildSearchIndex: %v", err)
+	}
+	if results := searchIndex.Search("de", "Sauerteig", 10); len(results) != 1 || results[0].VideoID != "searchVid03" {
+		t.Errorf("Expected the stored summary to be indexed again, got %+v", results)
+	}
+	if results := searchIndex.Search("it", "Pane", 10); len(results) != 0 {
+		t.Errorf("Expected summaries still processing to stay out of the index, got %+v", results)
+	}
+}
+
+func TestLoadContentWhenItsCached_DoesNotIndex(t *testing.T) {
+	metadata := videostate.Metadata{
+		Vid:     "searchVid04",
+		Status:  map[string]string{"es": string(videostate.StatusSummarizeProcessed)},
+		Title:   map[string]string{"es": "Paella valenciana"},
+		Summary: map[string]string{"es": "Arroz con azafrán"},
+	}
+	if err := dataStore.PutMetadata(metadata); err != nil {
+		t.Fatalf("PutMetadata: %v", err)
+	}
+	searchIndex.Remove("searchVid04", "es")
+
+	if _, err := loadContentWhenItsCached("searchVid04", "es"); err != nil {
+		t.Fatalf("loadContentWhenItsCached: %v", err)
+	}
+	if results := searchIndex.Search("es", "Paella", 10); len(results) != 0 {
+		t.Errorf("Expected reading a summary to leave the index alone, got %+v", results)
+	}
+}
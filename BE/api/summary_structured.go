@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// llmOutputJSON asks llm-model for a schema-validated JSON summary instead of the
+// ╔$field╗ text, see BE/llm-model/structured.go
+const llmOutputJSON = "json"
+
+// llmResultText turns the llm-model result into text: a JSON summary object is kept as
+// is, a plain string (legacy output or an answer that failed validation) is unquoted.
+func llmResultText(result json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(result, &text); err == nil {
+		return text
+	}
+	return string(result)
+}
+
+// llmResponseText returns the text of an llm-model response, an error without any
+// result means the model call itself failed
+func llmResponseText(response *SummarizeResponse) (string, error) {
+	if response.Error != "" {
+		if len(response.Result) == 0 {
+			return "", fmt.Errorf("llm-model failed: %s", response.Error)
+		}
+		log.Printf("⚠️ llm-model: %s", response.Error)
+	}
+	return llmResultText(response.Result), nil
+}
+
+// parseStructuredSummary decodes the JSON summary returned by llm-model in JSON mode
+func parseStructuredSummary(input string) (VideoGPTSummary, error) {
+	trimmed := strings.TrimSpace(input)
+	if !strings.HasPrefix(trimmed, "{") {
+		return VideoGPTSummary{}, fmt.Errorf("not a JSON object")
+	}
+
+	var summary VideoGPTSummary
+	if err := json.Unmarshal([]byte(trimmed), &summary); err != nil {
+		return VideoGPTSummary{}, fmt.Errorf("invalid JSON summary: %w", err)
+	}
+	if strings.TrimSpace(summary.Content) == "" {
+		return VideoGPTSummary{}, fmt.Errorf("JSON summary has no content")
+	}
+	return summary, nil
+}
+
+// summarizeSubtitle reads the summary from the llm-model output, preferring the
+// structured JSON and falling back to the legacy ╔$field╗ parser.
+func summarizeSubtitle(prompt string) (*VideoGPTSummary, error) {
+	summary, err := parseStructuredSummary(prompt)
+	if err == nil {
+		return &summary, nil
+	}
+	log.Printf("⚠️ Structured summary unavailable (%v), using the legacy parser", err)
+
+	sanitizedSummary, err := parseFields(prompt)
+	if err != nil {
+		return nil, fmt.Errorf("error sanitizing summary JSON: %v", err)
+	}
+
+	return &sanitizedSummary, nil
+}
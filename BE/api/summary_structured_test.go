@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLLMResultText(t *testing.T) {
+	tests := []struct {
+		name   string
+		result string
+		want   string
+	}{
+		{"legacy text", `"╔$content:Hello╗"`, "╔$content:Hello╗"},
+		{"json summary", `{"content":"Hello","lang":"en"}`, `{"content":"Hello","lang":"en"}`},
+		{"empty", ``, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := llmResultText(json.RawMessage(tt.result)); got != tt.want {
+				t.Errorf("llmResultText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeSubtitle_Structured(t *testing.T) {
+	summary, err := summarizeSubtitle(`{"content":"### [(00:01:00) Intro](00:01:00)\nText","lang":"pt","answer":"Como fazer","title":"Título"}`)
+	if err != nil {
+		t.Fatalf("summarizeSubtitle() error = %v", err)
+	}
+	if summary.Lang != "pt" || summary.Title != "Título" || summary.Answer != "Como fazer" {
+		t.Errorf("summarizeSubtitle() = %+v", summary)
+	}
+	if summary.Content != "### [(00:01:00) Intro](00:01:00)\nText" {
+		t.Errorf("Content = %q", summary.Content)
+	}
+}
+
+func TestSummarizeSubtitle_LegacyFallback(t *testing.T) {
+	input := "╔$content:Some summary╗\n╔$lang:en╗\n╔$answer:How to test╗\n╔$title:Testing╗"
+	summary, err := summarizeSubtitle(input)
+	if err != nil {
+		t.Fatalf("summarizeSubtitle() error = %v", err)
+	}
+	if summary.Content != "Some summary" || summary.Lang != "en" || summary.Answer != "How to test" || summary.Title != "Testing" {
+		t.Errorf("summarizeSubtitle() = %+v", summary)
+	}
+
+	// A JSON object without content is not a usable summary either
+	summary, err = summarizeSubtitle(`{"lang":"en"}`)
+	if err != nil {
+		t.Fatalf("summarizeSubtitle() error = %v", err)
+	}
+	if summary.Content != "" {
+		t.Errorf("Content = %q, want empty", summary.Content)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"my_lambda_app/subtitle"
+)
+
+const (
+	TimestampValid   = "valid"
+	TimestampSnapped = "snapped"
+	TimestampDropped = "dropped"
+)
+
+var (
+	// [1. (HH:MM:SS) Title](HH:MM:SS), the link of a summary point, or a bare (HH:MM:SS)
+	summaryTimestampRef = regexp.MustCompile(`\[([^\]\n]*)\]\((\d{1,2}:\d{2}:\d{2})\)|\s*\((\d{1,2}:\d{2}:\d{2})\)`)
+	// (HH:MM:SS) inside the text of a link
+	summaryTimestampInLink = regexp.MustCompile(`\s*\((\d{1,2}:\d{2}:\d{2})\)`)
+)
+
+// TimestampCheck is the verdict on one timestamp of a summary
+type TimestampCheck struct {
+	Original   string `json:"original"`
+	Snapped    string `json:"snapped,omitempty"`
+	Status     string `json:"status"`
+	OutOfOrder bool   `json:"out_of_order,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// TimestampReport is stored next to the summary, {videoId}-{lang}-timestamps.json
+type TimestampReport struct {
+	VideoID    string           `json:"videoId"`
+	Lang       string           `json:"lang"`
+	Duration   int              `json:"duration"`
+	Cues       int              `json:"cues"`
+	Valid      int              `json:"valid"`
+	Snapped    int              `json:"snapped"`
+	Dropped    int              `json:"dropped"`
+	OutOfOrder int              `json:"out_of_order"`
+	Timestamps []TimestampCheck `json:"timestamps"`
+	CheckedAt  string           `json:"checked_at"`
+}
+
+func timestampReportKey(videoID string, lang string) string {
+	return videoID + "-" + lang + "-timestamps.json"
+}
+
+// timestampValidator snaps the timestamps of a summary to the start of the nearest cue and
+// rejects the ones after the end of the video. Every distinct timestamp is checked once,
+// so the heading and the link of a point always agree.
+type timestampValidator struct {
+	starts   []time.Duration
+	limit    time.Duration
+	verdicts map[string]*TimestampCheck
+	report   *TimestampReport
+	last     time.Duration
+}
+
+func (v *timestampValidator) check(timestamp string) *TimestampCheck {
+	at, err := subtitle.ParseTimestamp(timestamp)
+	// "0:02:05" and "00:02:05" are the same timestamp
+	key := timestamp
+	if err == nil {
+		key = subtitle.FormatTimestamp(at, "")
+	}
+	if verdict, ok := v.verdicts[key]; ok {
+		return verdict
+	}
+	verdict := &TimestampCheck{Original: timestamp, Status: TimestampValid}
+	v.verdicts[key] = verdict
+
+	switch {
+	case err != nil:
+		verdict.Status, verdict.Reason = TimestampDropped, "not a valid time"
+	case v.limit > 0 && at > v.limit:
+		verdict.Status, verdict.Reason = TimestampDropped, "after the end of the video"
+	case len(v.starts) > 0:
+		snapped := nearestCueStart(v.starts, at).Truncate(time.Second)
+		if snapped != at {
+			verdict.Status, verdict.Snapped = TimestampSnapped, subtitle.FormatTimestamp(snapped, "")
+			at = snapped
+		}
+	}
+	if verdict.Status == TimestampValid {
+		// normalizes "1:02:03" so the renderer links it
+		verdict.Snapped = subtitle.FormatTimestamp(at, "")
+		if verdict.Snapped == timestamp {
+			verdict.Snapped = ""
+		}
+	}
+
+	if verdict.Status != TimestampDropped {
+		if at < v.last {
+			verdict.OutOfOrder = true
+			v.report.OutOfOrder++
+		}
+		v.last = at
+	}
+	switch verdict.Status {
+	case TimestampValid:
+		v.report.Valid++
+	case TimestampSnapped:
+		v.report.Snapped++
+	case TimestampDropped:
+		v.report.Dropped++
+	}
+	v.report.Timestamps = append(v.report.Timestamps, *verdict)
+	return verdict
+}
+
+// replacement is the timestamp to write back, "" when it is dropped
+func (verdict *TimestampCheck) replacement() string {
+	if verdict.Status == TimestampDropped {
+		return ""
+	}
+	if verdict.Snapped != "" {
+		return verdict.Snapped
+	}
+	return verdict.Original
+}
+
+// nearestCueStart is the cue start closest to at, starts are sorted
+func nearestCueStart(starts []time.Duration, at time.Duration) time.Duration {
+	i := sort.Search(len(starts), func(i int) bool { return starts[i] >= at })
+	if i == len(starts) {
+		return starts[i-1]
+	}
+	if i > 0 && at-starts[i-1] <= starts[i]-at {
+		return starts[i-1]
+	}
+	return starts[i]
+}
+
+// validateSummaryTimestamps checks the (HH:MM:SS) references of a summary against the
+// caption cues and the duration of the video (in seconds, 0 when unknown). Links to a
+// dropped timestamp are unlinked, other dropped references are removed, out of order
+// ones are kept and flagged in the report.
+func validateSummaryTimestamps(content string, cues []subtitle.Cue, duration int) (string, TimestampReport) {
+	report := TimestampReport{Duration: duration, Cues: len(cues), Timestamps: []TimestampCheck{}}
+	v := &timestampValidator{
+		limit:    time.Duration(duration) * time.Second,
+		verdicts: map[string]*TimestampCheck{},
+		report:   &report,
+	}
+	for _, cue := range cues {
+		v.starts = append(v.starts, cue.Start)
+		v.limit = max(v.limit, cue.End)
+	}
+	sort.Slice(v.starts, func(i, j int) bool { return v.starts[i] < v.starts[j] })
+
+	content = summaryTimestampRef.ReplaceAllStringFunc(content, func(ref string) string {
+		match := summaryTimestampRef.FindStringSubmatch(ref)
+		if match[2] == "" {
+			verdict := v.check(match[3])
+			if verdict.Status == TimestampDropped {
+				return ""
+			}
+			return strings.Replace(ref, match[3], verdict.replacement(), 1)
+		}
+
+		text := summaryTimestampInLink.ReplaceAllStringFunc(match[1], func(inner string) string {
+			timestamp := summaryTimestampInLink.FindStringSubmatch(inner)[1]
+			if verdict := v.check(timestamp); verdict.Status != TimestampDropped {
+				return strings.Replace(inner, timestamp, verdict.replacement(), 1)
+			}
+			return ""
+		})
+		verdict := v.check(match[2])
+		if verdict.Status == TimestampDropped {
+			return strings.TrimSpace(text)
+		}
+		return "[" + text + "](" + verdict.replacement() + ")"
+	})
+	return content, report
+}
+
+// checkSummaryTimestamps validates a fresh summary and stores its report, the summary is
+// returned unchanged when the caption cannot be parsed and the duration is unknown.
+func checkSummaryTimestamps(videoID string, lang string, content string, caption string, duration int) string {
+	cues, err := subtitle.Parse(caption)
+	if err != nil && duration <= 0 {
+		log.Printf("⚠️ Timestamps of %s (%s) not validated: %v", videoID, lang, err)
+		return content
+	}
+
+	content, report := validateSummaryTimestamps(content, cues, duration)
+	report.VideoID = videoID
+	report.Lang = lang
+	report.CheckedAt = time.Now().UTC().Format(time.RFC3339)
+	if report.Snapped > 0 || report.Dropped > 0 || report.OutOfOrder > 0 {
+		log.Printf("⚠️ Timestamps of %s (%s): %d snapped, %d dropped, %d out of order", videoID, lang, report.Snapped, report.Dropped, report.OutOfOrder)
+	}
+
+	data, err := json.Marshal(report)
+	if err == nil {
+		err = dataStore.PutObject(timestampReportKey(videoID, lang), string(data))
+	}
+	if err != nil {
+		log.Printf("❌ Failed to store the timestamp report of %s (%s): %v", videoID, lang, err)
+	}
+	return content
+}
+
+// handleTimestampReport returns the timestamp validation report of a summary.
+// GET /summary/{videoId}/{lang}/timestamps
+func handleTimestampReport(w http.ResponseWriter, r *http.Request) {
+	videoID, err := extractVideoID("https://www.youtube.com/watch?v=" + r.PathValue("videoId"))
+	if err != nil {
+		http.Error(w, "Invalid videoId", http.StatusBadRequest)
+		return
+	}
+	content, err := dataStore.GetObject(timestampReportKey(videoID, r.PathValue("lang")))
+	if err != nil || content == "" {
+		http.Error(w, "No timestamp report for this summary", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(content))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"my_lambda_app/subtitle"
+)
+
+func TestValidateSummaryTimestamps(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 1 * time.Second, End: 5 * time.Second, Text: "intro"},
+		{Start: 62 * time.Second, End: 70 * time.Second, Text: "first point"},
+		{Start: 125500 * time.Millisecond, End: 140 * time.Second, Text: "second point"},
+	}
+	content := "### [1. (00:01:00) Intro](00:01:00)\n" +
+		"### [2. (0:02:05) Second](0:02:05)\n" +
+		"### [3. (00:00:01) Back](00:00:01)\n" +
+		"### [4. (01:30:00) Made up](01:30:00)\n" +
+		"See also (00:99:00) and (00:02:05)."
+
+	got, report := validateSummaryTimestamps(content, cues, 300)
+	want := "### [1. (00:01:02) Intro](00:01:02)\n" +
+		"### [2. (00:02:05) Second](00:02:05)\n" +
+		"### [3. (00:00:01) Back](00:00:01)\n" +
+		"### 4. Made up\n" +
+		"See also and (00:02:05)."
+	if got != want {
+		t.Errorf("content =\n%s\nwant\n%s", got, want)
+	}
+
+	if report.Valid != 2 || report.Snapped != 1 || report.Dropped != 2 || report.OutOfOrder != 1 || len(report.Timestamps) != 5 {
+		t.Errorf("report = %+v", report)
+	}
+	checks := map[string]TimestampCheck{}
+	for _, check := range report.Timestamps {
+		checks[check.Original] = check
+	}
+	if check := checks["00:01:00"]; check.Status != TimestampSnapped || check.Snapped != "00:01:02" {
+		t.Errorf("00:01:00 = %+v", check)
+	}
+	if check := checks["0:02:05"]; check.Status != TimestampValid || check.Snapped != "00:02:05" {
+		t.Errorf("0:02:05 = %+v", check)
+	}
+	if check := checks["00:00:01"]; !check.OutOfOrder {
+		t.Errorf("00:00:01 = %+v, want out of order", check)
+	}
+	if check := checks["01:30:00"]; check.Status != TimestampDropped || check.Reason == "" {
+		t.Errorf("01:30:00 = %+v", check)
+	}
+}
+
+func TestValidateSummaryTimestamps_DurationOnly(t *testing.T) {
+	got, report := validateSummaryTimestamps("[(00:00:30) a](00:00:30) [(00:20:00) b](00:20:00)", nil, 600)
+	if got != "[(00:00:30) a](00:00:30) b" || report.Valid != 1 || report.Dropped != 1 {
+		t.Errorf("validateSummaryTimestamps() = %q, %+v", got, report)
+	}
+}
+
+func TestHandleTimestampReport(t *testing.T) {
+	videoID := "tsReport001"
+	caption := "1\n00:00:10,000 --> 00:00:20,000\nhello\n"
+	if got := checkSummaryTimestamps(videoID, "en", "[(00:00:12) Hello](00:00:12)", caption, 0); got != "[(00:00:10) Hello](00:00:10)" {
+		t.Fatalf("checkSummaryTimestamps() = %q", got)
+	}
+
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/summary/"+videoID+"/en/timestamps", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var report TimestampReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("Decode report: %v", err)
+	}
+	if report.VideoID != videoID || report.Snapped != 1 || report.Cues != 1 || report.CheckedAt == "" {
+		t.Errorf("report = %+v", report)
+	}
+
+	rec = httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/summary/"+videoID+"/fr/timestamps", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("missing report = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"my_lambda_app/subtitle"
+)
+
+// transcriptFormats maps the ?format= values to their content type and file extension
+var transcriptFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"srt":  {"application/x-subrip; charset=utf-8", "srt"},
+	"vtt":  {"text/vtt; charset=utf-8", "vtt"},
+	"txt":  {"text/plain; charset=utf-8", "txt"},
+	"json": {"application/json", "json"},
+}
+
+type TranscriptCue struct {
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	StartMs int64  `json:"startMs"`
+	EndMs   int64  `json:"endMs"`
+	Text    string `json:"text"`
+}
+
+type TranscriptResponse struct {
+	VideoID string          `json:"videoId"`
+	Lang    string          `json:"lang,omitempty"`
+	Cues    []TranscriptCue `json:"cues"`
+}
+
+// GET /transcript/{videoId}?lang=en&format=srt|vtt|txt|json&timestamps=true
+// Serves the stored caption of a video, normalized through the subtitle parser.
+func handleTranscript(w http.ResponseWriter, r *http.Request) {
+	videoID, err := extractVideoID("https://www.youtube.com/watch?v=" + r.PathValue("videoId"))
+	if err != nil {
+		http.Error(w, "Invalid videoId", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	format := strings.ToLower(query.Get("format"))
+	if format == "" {
+		format = "srt"
+	}
+	output, ok := transcriptFormats[format]
+	if !ok {
+		http.Error(w, "Invalid format, use srt, vtt, txt or json", http.StatusBadRequest)
+		return
+	}
+
+	// the caption is stored once, in the language of the video
+	videoLang := ""
+	if metadata, err := dataStore.GetMetadata(videoID); err == nil && metadata != nil {
+		videoLang = metadata.VideoLang
+	}
+	if lang := query.Get("lang"); lang != "" && videoLang != "" && lang != videoLang {
+		http.Error(w, fmt.Sprintf("The transcript of this video is only available in '%s'", videoLang), http.StatusNotFound)
+		return
+	}
+
+	caption, err := dataStore.GetObject(videoID + "-caption.txt")
+	if err != nil || strings.TrimSpace(caption) == "" {
+		http.Error(w, "No transcript stored for this video, summarize it first", http.StatusNotFound)
+		return
+	}
+	cues, err := subtitle.Parse(caption)
+	if err != nil {
+		http.Error(w, "The transcript of this video is empty or invalid", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", output.contentType)
+	if videoLang != "" {
+		w.Header().Set("Content-Language", videoLang)
+	}
+
+	switch format {
+	case "json":
+		response := TranscriptResponse{VideoID: videoID, Lang: videoLang, Cues: make([]TranscriptCue, 0, len(cues))}
+		for _, cue := range cues {
+			response.Cues = append(response.Cues, TranscriptCue{
+				Start:   subtitle.FormatTimestamp(cue.Start, "."),
+				End:     subtitle.FormatTimestamp(cue.End, "."),
+				StartMs: cue.Start.Milliseconds(),
+				EndMs:   cue.End.Milliseconds(),
+				Text:    cue.Text,
+			})
+		}
+		json.NewEncoder(w).Encode(response)
+		return
+	case "srt":
+		caption = subtitle.FormatSRT(cues)
+	case "vtt":
+		caption = subtitle.FormatVTT(cues)
+	case "txt":
+		caption = subtitle.FormatText(cues, query.Get("timestamps") == "true")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, videoID, output.extension))
+	fmt.Fprint(w, caption)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"my_lambda_app/videostate"
+)
+
+func TestHandleTranscript(t *testing.T) {
+	videoID := "transcrpt01"
+	caption := "1\n0:00:01,000 --> 0:00:04,000\nHello <i>there</i>\n\n2\n0:01:05,500 --> 0:01:08,000\nGeneral Kenobi\n"
+	if err := dataStore.PutObject(videoID+"-caption.txt", caption); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	if err := dataStore.PutMetadata(videostate.Metadata{Vid: videoID, VideoLang: "en", Title: map[string]string{"en": "Transcript"}}); err != nil {
+		t.Fatalf("PutMetadata: %v", err)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		newRouter().ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	tests := []struct {
+		query       string
+		contentType string
+		body        string
+	}{
+		{"", "application/x-subrip; charset=utf-8", "1\n00:00:01,000 --> 00:00:04,000\nHello there\n\n2\n00:01:05,500 --> 00:01:08,000\nGeneral Kenobi\n\n"},
+		{"?format=vtt&lang=en", "text/vtt; charset=utf-8", "WEBVTT\n\n00:00:01.000 --> 00:00:04.000\nHello there\n\n00:01:05.500 --> 00:01:08.000\nGeneral Kenobi\n\n"},
+		{"?format=txt", "text/plain; charset=utf-8", "Hello there\nGeneral Kenobi\n"},
+		{"?format=txt&timestamps=true", "text/plain; charset=utf-8", "[00:00:01] Hello there\n[00:01:05] General Kenobi\n"},
+	}
+	for _, tt := range tests {
+		rec := get("/transcript/" + videoID + tt.query)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", tt.query, rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.query, got, tt.contentType)
+		}
+		if got := rec.Body.String(); got != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.query, got, tt.body)
+		}
+		if !strings.Contains(rec.Header().Get("Content-Disposition"), videoID) {
+			t.Errorf("%s: Content-Disposition = %q", tt.query, rec.Header().Get("Content-Disposition"))
+		}
+	}
+
+	rec := get("/transcript/" + videoID + "?format=json")
+	var response TranscriptResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Decode response: %v", err)
+	}
+	if response.Lang != "en" || len(response.Cues) != 2 || response.Cues[1].StartMs != 65500 || response.Cues[1].Start != "00:01:05.500" {
+		t.Errorf("json transcript = %+v", response)
+	}
+
+	for path, want := range map[string]int{
+		"/transcript/" + videoID + "?format=pdf": http.StatusBadRequest,
+		"/transcript/" + videoID + "?lang=fr":    http.StatusNotFound,
+		"/transcript/noCaption01":                http.StatusNotFound,
+		"/transcript/bad":                        http.StatusBadRequest,
+	} {
+		if rec := get(path); rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
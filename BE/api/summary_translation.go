@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"my_lambda_app/videostate"
+)
+
+// maxTranslationAttempts is how many translations are tried before falling back to
+// the full summarization of the captions
+const maxTranslationAttempts = 2
+
+var summaryTimestampPattern = regexp.MustCompile(`\d{1,2}:\d{2}:\d{2}`)
+
+// translationSource picks the completed summary a new language is translated from,
+// preferring the language of the video, it returns "" when there is none.
+func translationSource(content videostate.Metadata, lang string) string {
+	isCompleted := func(l string) bool {
+		return l != lang && content.Summary[l] != "" && content.Status[l] == string(videostate.StatusSummarizeProcessed)
+	}
+	if isCompleted(content.VideoLang) {
+		return content.VideoLang
+	}
+
+	languages := make([]string, 0, len(content.Summary))
+	for l := range content.Summary {
+		languages = append(languages, l)
+	}
+	sort.Strings(languages)
+	for _, l := range languages {
+		if isCompleted(l) {
+			return l
+		}
+	}
+	return ""
+}
+
+// translationInput lays the source fields out like the legacy ╔$field╗ output
+func translationInput(content videostate.Metadata, source string) string {
+	return fmt.Sprintf("╔$title:%s╗\n╔$answer:%s╗\n╔$content:%s╗", content.Title[source], content.Answer[source], content.Summary[source])
+}
+
+// translateSummary translates the source summary with the "translate" template and
+// rejects translations that lost or changed any (HH:MM:SS) timestamp.
+func translateSummary(content videostate.Metadata, source string, lang string) (*VideoGPTSummary, error) {
+	wantTimestamps := summaryTimestampPattern.FindAllString(content.Summary[source], -1)
+	var lastErr error
+
+	for attempt := 1; attempt <= maxTranslationAttempts; attempt++ {
+		response, err := callLLMModel(llmModelName, "translate", llmOutputJSON, LLMInput{Language: lang, Title: content.Title[source], Captions: translationInput(content, source)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to translate summary: %w", err)
+		}
+		text, err := llmResponseText(response)
+		if err != nil {
+			return nil, err
+		}
+		summary, err := summarizeSubtitle(text)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case strings.TrimSpace(summary.Content) == "":
+			lastErr = fmt.Errorf("translation has no content")
+		case !slices.Equal(summaryTimestampPattern.FindAllString(summary.Content, -1), wantTimestamps):
+			lastErr = fmt.Errorf("translation changed the timestamps of the summary")
+		default:
+			return summary, nil
+		}
+		log.Printf("⚠️ Translation of %s into %s rejected (attempt %d): %v", source, lang, attempt, lastErr)
+	}
+	return nil, lastErr
+}
+
+// processingQueueVideoTranslate fills a new language from an existing summary instead of
+// running the metadata, caption and summarization stages again. When the translation
+// fails the job falls back to the full pipeline.
+func processingQueueVideoTranslate(videoId string, language string, source string, content videostate.Metadata) {
+	videoQueue.Add(videostate.ProcessingVideo{
+		VideoID:  videoId,
+		Language: language,
+		Metadata: content,
+	})
+	videoQueue.SetStatus(videoId, language, videostate.StatusTranslating)
+
+	summary, err := translateSummary(content, source, language)
+	if err != nil {
+		log.Printf("❌ Failed to translate %s into %s, summarizing the captions instead: %v", videoId, language, err)
+		videoQueue.SetStatus(videoId, language, videostate.StatusPending)
+		processingVideoQueue(videoId, language)
+		return
+	}
+
+	metadata := videoQueue.GetVideoMeta(videoId, language)
+	if metadata == nil {
+		log.Printf("❌ %s (%s) left the queue during the translation", videoId, language)
+		return
+	}
+	for _, m := range []*map[string]string{&metadata.Title, &metadata.Path, &metadata.Summary, &metadata.Answer} {
+		if *m == nil {
+			*m = make(map[string]string)
+		}
+	}
+
+	title := sanitizeTitle(summary.Title)
+	if title == "" {
+		title = content.Title[source]
+	}
+	metadata.Title[language] = title
+	metadata.Path[language] = localizedPath(metadata.Path, videoId, language, title)
+	metadata.Summary[language] = summary.Content
+	metadata.Answer[language] = summary.Answer
+
+	videoQueue.Add(videostate.ProcessingVideo{
+		VideoID:  videoId,
+		Language: language,
+		Metadata: *metadata,
+	})
+	log.Println("🚀 [2] Set Status ", videostate.StatusSummarizeProcessed)
+	videoQueue.SetStatus(videoId, language, videostate.StatusSummarizeProcessed)
+
+	go storeCompletedSummary(videoId, language)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"my_lambda_app/videostate"
+)
+
+const translatedSummary = `{"content":"Resumo.\n### [(00:01:05) Introdução](00:01:05)\nTexto.\n### [(00:12:40) Conclusão](00:12:40)","lang":"pt","answer":"Como testar","title":"Como testar em Go"}`
+
+func completedEnglishSummary(videoID string) videostate.Metadata {
+	return videostate.Metadata{
+		Vid:       videoID,
+		VideoLang: "en",
+		Title:     map[string]string{"en": "how to test in go"},
+		Path:      map[string]string{"en": "how-to-test-in-go"},
+		Summary:   map[string]string{"en": "Summary.\n### [(00:01:05) Intro](00:01:05)\nText.\n### [(00:12:40) Wrap up](00:12:40)"},
+		Answer:    map[string]string{"en": "How to test"},
+		Status:    map[string]string{"en": string(videostate.StatusSummarizeProcessed)},
+		Category:  "Education",
+	}
+}
+
+func TestTranslationSource(t *testing.T) {
+	content := completedEnglishSummary("abc")
+	content.Summary["de"] = "Zusammenfassung"
+	content.Status["de"] = string(videostate.StatusSummarizeProcessed)
+	content.Summary["es"] = "Resumen"
+	content.Status["es"] = string(videostate.StatusPending)
+
+	if got := translationSource(content, "pt"); got != "en" {
+		t.Errorf("translationSource() = %q, want the video language", got)
+	}
+	if got := translationSource(content, "en"); got != "de" {
+		t.Errorf("translationSource() = %q, want the first other completed language", got)
+	}
+	if got := translationSource(completedEnglishSummary("abc"), "en"); got != "" {
+		t.Errorf("translationSource() = %q, want none", got)
+	}
+}
+
+func TestTranslateSummary_RejectsChangedTimestamps(t *testing.T) {
+	calls := stubLLMModel(t, func(template, caption string) string {
+		return strings.Replace(translatedSummary, "00:12:40", "00:12:41", 2)
+	})
+
+	if _, err := translateSummary(completedEnglishSummary("abc"), "en", "pt"); err == nil {
+		t.Fatal("translateSummary() accepted a translation with other timestamps")
+	}
+	if len(*calls) != maxTranslationAttempts {
+		t.Errorf("got %d llm-model calls, want %d", len(*calls), maxTranslationAttempts)
+	}
+}
+
+func waitForStatus(t *testing.T, videoID, lang string, want videostate.VideoStatus) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for videoQueue.GetStatus(videoID, lang) != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("status of %s (%s) = %q, want %q", videoID, lang, videoQueue.GetStatus(videoID, lang), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestEnqueueSummary_TranslatesCompletedSummary(t *testing.T) {
+	videoID := fmt.Sprintf("tr%09d", time.Now().UnixNano()%1e9)
+	if err := dataStore.PutMetadata(completedEnglishSummary(videoID)); err != nil {
+		t.Fatalf("PutMetadata: %v", err)
+	}
+	calls := stubLLMModel(t, func(template, caption string) string {
+		return translatedSummary
+	})
+
+	enqueueSummary(videoID, "pt", "", false)
+	waitForStatus(t, videoID, "pt", videostate.StatusSummarizeProcessed)
+
+	if len(*calls) != 1 || (*calls)[0].template != "translate" {
+		t.Fatalf("llm-model calls = %+v, want one translate call", *calls)
+	}
+	if !strings.Contains((*calls)[0].caption, "╔$content:Summary.") {
+		t.Errorf("translate input = %q, want the English summary", (*calls)[0].caption)
+	}
+
+	metadata := videoQueue.GetVideoMeta(videoID, "pt")
+	if metadata.Title["pt"] != "como testar em go" || metadata.Path["pt"] != "como-testar-em-go" {
+		t.Errorf("title/path = %q/%q", metadata.Title["pt"], metadata.Path["pt"])
+	}
+	if metadata.Answer["pt"] != "Como testar" || !strings.Contains(metadata.Summary["pt"], "(00:12:40) Conclusão") {
+		t.Errorf("answer/summary = %q/%q", metadata.Answer["pt"], metadata.Summary["pt"])
+	}
+	if metadata.Summary["en"] == "" {
+		t.Error("the English summary was dropped")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stored, _ := dataStore.GetMetadata(videoID)
+		if stored != nil && stored.Summary["pt"] != "" && stored.Summary["en"] != "" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stored metadata = %+v, want both languages", stored)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
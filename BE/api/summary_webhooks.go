@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"my_lambda_app/videostate"
+	"my_lambda_app/webhooks"
+)
+
+const (
+	webhookEventCompleted = "summary.completed"
+	webhookEventFailed    = "summary.failed"
+)
+
+// Receivers verify X-Sumtube-Signature with WEBHOOK_SIGNING_SECRET. Webhooks are disabled
+// when it is unset.
+var summaryWebhooks = webhooks.NewDispatcher(
+	os.Getenv("WEBHOOK_SIGNING_SECRET"),
+	[]time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute, 10 * time.Minute},
+	1000,
+)
+
+type SummaryWebhookPayload struct {
+	Event   string                                      `json:"event"`
+	VideoID string                                      `json:"videoId"`
+	Lang    string                                      `json:"lang"`
+	Status  string                                      `json:"status"`
+	Summary *HandleSummarySingleLanguageRequestResponse `json:"summary"`
+}
+
+// Callback URLs waiting for a job to finish, keyed by summaryEventsTopic. They are written
+// to the store as well, so jobs resumed after a restart still notify their callers.
+var webhookSubscriptions = make(map[string][]string)
+var webhookSubscriptionsMu sync.Mutex
+
+func webhookSubscriptionsKey(topic string) string {
+	return "webhooks-" + topic + ".json"
+}
+
+func saveWebhookSubscriptions(topic string, urls []string) error {
+	data, err := json.Marshal(urls)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhooks: %w", err)
+	}
+	return dataStore.PutObject(webhookSubscriptionsKey(topic), string(data))
+}
+
+// loadWebhookSubscriptions must be called with webhookSubscriptionsMu held.
+func loadWebhookSubscriptions(topic string) []string {
+	if urls, ok := webhookSubscriptions[topic]; ok {
+		return urls
+	}
+	urls := []string{}
+	if content, err := dataStore.GetObject(webhookSubscriptionsKey(topic)); err == nil {
+		json.Unmarshal([]byte(content), &urls)
+	}
+	webhookSubscriptions[topic] = urls
+	return urls
+}
+
+// summaryJobStatus returns the current status of a job, from the queue while it
+// is in flight or from the store once it has left the queue.
+func summaryJobStatus(videoID string, lang string) videostate.VideoStatus {
+	if videoQueue.Exists(videoID, lang) {
+		return videoQueue.GetStatus(videoID, lang)
+	}
+	if cached, err := loadContentWhenItsCached(videoID, lang); err == nil && cached.Vid != "" {
+		return videostate.VideoStatus(cached.Status[lang])
+	}
+	return ""
+}
+
+func sendSummaryWebhook(callbackURL string, videoID string, lang string, status videostate.VideoStatus, metadata *videostate.Metadata) {
+	event := webhookEventCompleted
+	if status != videostate.StatusSummarizeProcessed {
+		event = webhookEventFailed
+	}
+	payload := SummaryWebhookPayload{
+		Event:   event,
+		VideoID: videoID,
+		Lang:    lang,
+		Status:  string(status),
+		Summary: buildSummaryResponse(videoID, lang, metadata, videoQueue.CanBeRetried(videoID, lang)),
+	}
+	if _, err := summaryWebhooks.Send(callbackURL, event, summaryEventsTopic(videoID, lang), payload); err != nil {
+		log.Printf("❌ Failed to send webhook for %s (%s): %v", videoID, lang, err)
+	}
+}
+
+// registerSummaryWebhook asks for callbackURL to be notified once when the job finishes.
+// Nothing is sent for a job that is already final, its summary is in the response of the
+// request that registered the callback, and polling the summary never sends it again.
+func registerSummaryWebhook(videoID string, lang string, callbackURL string) {
+	if !summaryWebhooks.Enabled() {
+		log.Printf("⚠️ Webhook of %s (%s) not registered, WEBHOOK_SIGNING_SECRET is not set", videoID, lang)
+		return
+	}
+	topic := summaryEventsTopic(videoID, lang)
+
+	// The status is read under the lock so a job finishing concurrently either sees
+	// the subscription or is seen as final here
+	webhookSubscriptionsMu.Lock()
+	if isFinalStatus(summaryJobStatus(videoID, lang)) {
+		webhookSubscriptionsMu.Unlock()
+		return
+	}
+	urls := loadWebhookSubscriptions(topic)
+	for _, existing := range urls {
+		if existing == callbackURL {
+			webhookSubscriptionsMu.Unlock()
+			return
+		}
+	}
+	urls = append(urls, callbackURL)
+	webhookSubscriptions[topic] = urls
+	webhookSubscriptionsMu.Unlock()
+
+	if err := saveWebhookSubscriptions(topic, urls); err != nil {
+		log.Printf("❌ Failed to persist webhooks of %s: %v", topic, err)
+	}
+}
+
+// notifySummaryWebhooks is registered on videoQueue and calls back the subscribers of a job
+// once it reaches a final status.
+func notifySummaryWebhooks(videoID string, language string, status videostate.VideoStatus) {
+	if !isFinalStatus(status) {
+		return
+	}
+	topic := summaryEventsTopic(videoID, language)
+
+	webhookSubscriptionsMu.Lock()
+	urls := loadWebhookSubscriptions(topic)
+	webhookSubscriptions[topic] = []string{}
+	webhookSubscriptionsMu.Unlock()
+	if len(urls) == 0 {
+		return
+	}
+
+	if err := saveWebhookSubscriptions(topic, []string{}); err != nil {
+		log.Printf("❌ Failed to clear webhooks of %s: %v", topic, err)
+	}
+	metadata := videoQueue.GetVideoMeta(videoID, language)
+	for _, callbackURL := range urls {
+		sendSummaryWebhook(callbackURL, videoID, language, status, metadata)
+	}
+}
+
+// handleWebhookDeliveries returns the delivery log of the webhooks of a job. It lists the
+// callback URLs of every caller, so it is admin-only.
+// GET /webhooks/deliveries?videoId=...&lang=en
+func handleWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	videoID := r.URL.Query().Get("videoId")
+	lang := r.URL.Query().Get("lang")
+	if videoID == "" || lang == "" {
+		http.Error(w, "Missing 'videoId' or 'lang'", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summaryWebhooks.Deliveries(summaryEventsTopic(videoID, lang)))
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"my_lambda_app/videostate"
+	"my_lambda_app/webhooks"
+)
+
+// webhookReceiver collects the payloads POSTed to it.
+func webhookReceiver(t *testing.T) (*httptest.Server, <-chan SummaryWebhookPayload) {
+	received := make(chan SummaryWebhookPayload, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(webhooks.SignatureHeader) != "sha256="+webhooks.Sign("test-secret", r.Header.Get(webhooks.TimestampHeader), body) {
+			t.Errorf("Invalid webhook signature")
+		}
+		var payload SummaryWebhookPayload
+		json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	return server, received
+}
+
+func useTestWebhookDispatcher() func() {
+	previous := summaryWebhooks
+	summaryWebhooks = webhooks.NewDispatcher("test-secret", nil, 10)
+	summaryWebhooks.AllowPrivateAddresses()
+	return func() { summaryWebhooks = previous }
+}
+
+func expectWebhook(t *testing.T, received <-chan SummaryWebhookPayload) SummaryWebhookPayload {
+	t.Helper()
+	select {
+	case payload := <-received:
+		return payload
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a webhook delivery")
+	}
+	return SummaryWebhookPayload{}
+}
+
+func TestSummaryWebhook_NotifiedOnCompletion(t *testing.T) {
+	defer useTestWebhookDispatcher()()
+	resetVideoQueue()
+	server, received := webhookReceiver(t)
+	defer server.Close()
+
+	videoQueue.Add(videostate.ProcessingVideo{
+		VideoID:  "hookLive001",
+		Language: "en",
+		Metadata: videostate.Metadata{Summary: map[string]string{"en": "Done"}},
+	})
+	videoQueue.SetStatus("hookLive001", "en", videostate.StatusPending)
+	registerSummaryWebhook("hookLive001", "en", server.URL)
+	// Registering twice must not deliver twice
+	registerSummaryWebhook("hookLive001", "en", server.URL)
+
+	videoQueue.SetStatus("hookLive001", "en", videostate.StatusDownloadProcessed)
+	select {
+	case payload := <-received:
+		t.Fatalf("Unexpected webhook before the job finished: %+v", payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	videoQueue.SetStatus("hookLive001", "en", videostate.StatusSummarizeProcessed)
+	payload := expectWebhook(t, received)
+	if payload.Event != webhookEventCompleted || payload.Summary == nil || payload.Summary.Content != "Done" {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+	select {
+	case payload := <-received:
+		t.Errorf("Unexpected second webhook %+v", payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	t.Setenv("API_ADMIN_TOKEN", "admin-token")
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("GET", "/webhooks/deliveries?videoId=hookLive001&lang=en", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected the delivery log to require the admin token, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/webhooks/deliveries?videoId=hookLive001&lang=en", nil)
+	req.Header.Set("Authorization", "Bearer admin-token")
+	newRouter().ServeHTTP(rec, req)
+	var deliveries []webhooks.Delivery
+	json.NewDecoder(rec.Body).Decode(&deliveries)
+	if len(deliveries) != 1 || deliveries[0].URL != server.URL || deliveries[0].Event != webhookEventCompleted {
+		t.Errorf("Unexpected delivery log %+v", deliveries)
+	}
+}
+
+func TestSummaryWebhook_FailedAndAlreadyFinished(t *testing.T) {
+	defer useTestWebhookDispatcher()()
+	resetVideoQueue()
+	server, received := webhookReceiver(t)
+	defer server.Close()
+
+	videoQueue.Add(videostate.ProcessingVideo{VideoID: "hookFail001", Language: "en"})
+	videoQueue.SetStatus("hookFail001", "en", videostate.StatusPending)
+	registerSummaryWebhook("hookFail001", "en", server.URL)
+	videoQueue.SetStatus("hookFail001", "en", videostate.StatusMetadataTTlExceeded)
+	if payload := expectWebhook(t, received); payload.Event != webhookEventFailed || payload.Status != string(videostate.StatusMetadataTTlExceeded) {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+
+	// A job that is already final is not notified again, however often it is polled
+	registerSummaryWebhook("hookFail001", "en", server.URL)
+	select {
+	case payload := <-received:
+		t.Errorf("Unexpected webhook for a final job %+v", payload)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHandleSummaryRequest_InvalidCallbackURL(t *testing.T) {
+	defer useTestWebhookDispatcher()()
+	body := `{"videoId": "dQw4w9WgXcQ", "language": "en", "callback_url": "ftp://example.com"}`
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/summary", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+}
+
+func TestSummaryWebhook_DisabledWithoutSecret(t *testing.T) {
+	previous := summaryWebhooks
+	summaryWebhooks = webhooks.NewDispatcher("", nil, 10)
+	summaryWebhooks.AllowPrivateAddresses()
+	defer func() { summaryWebhooks = previous }()
+	resetVideoQueue()
+
+	body := `{"videoId": "dQw4w9WgXcQ", "language": "en", "callback_url": "https://example.com/hook"}`
+	rec := httptest.NewRecorder()
+	newRouter().ServeHTTP(rec, httptest.NewRequest("POST", "/summary", strings.NewReader(body)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for a callback without a signing secret, got %d", rec.Code)
+	}
+
+	// callbacks from an API key are not registered either
+	registerSummaryWebhook("hookNoKey01", "en", "https://example.com/hook")
+	webhookSubscriptionsMu.Lock()
+	urls := webhookSubscriptions[summaryEventsTopic("hookNoKey01", "en")]
+	webhookSubscriptionsMu.Unlock()
+	if len(urls) != 0 {
+		t.Errorf("Expected no subscription without a signing secret, got %v", urls)
+	}
+}
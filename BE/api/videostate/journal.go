@@ -0,0 +1,56 @@
+package videostate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Journal persists the processing queue so in-flight jobs survive a restart.
+type Journal interface {
+	Load() ([]ProcessingVideo, error)
+	Save(videos []ProcessingVideo) error
+}
+
+// FileJournal keeps the whole queue as a single JSON document on disk.
+type FileJournal struct {
+	path string
+}
+
+func NewFileJournal(path string) (*FileJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal dir: %w", err)
+	}
+	return &FileJournal{path: path}, nil
+}
+
+func (j *FileJournal) Load() ([]ProcessingVideo, error) {
+	data, err := os.ReadFile(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var videos []ProcessingVideo
+	if err := json.Unmarshal(data, &videos); err != nil {
+		return nil, fmt.Errorf("failed to parse journal: %w", err)
+	}
+	return videos, nil
+}
+
+// Save writes to a temp file and renames it, so a crash mid-write keeps the previous snapshot.
+func (j *FileJournal) Save(videos []ProcessingVideo) error {
+	data, err := json.Marshal(videos)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return os.Rename(tmp, j.path)
+}
@@ -0,0 +1,80 @@
+package videostate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDurableProcessor_RestoresQueue(t *testing.T) {
+	journal, err := NewFileJournal(filepath.Join(t.TempDir(), "queue.json"))
+	if err != nil {
+		t.Fatalf("NewFileJournal: %v", err)
+	}
+
+	p, err := NewDurableProcessor(journal, DefaultTTL)
+	if err != nil {
+		t.Fatalf("NewDurableProcessor: %v", err)
+	}
+
+	p.Add(ProcessingVideo{
+		VideoID:  "vid1",
+		Language: "en",
+		Metadata: Metadata{DownSubDownloadCap: "https://example.com/caps.srt"},
+	})
+	p.SetStatus("vid1", "en", StatusMetadataProcessed)
+
+	p.Add(ProcessingVideo{VideoID: "vid2", Language: "pt"})
+	p.SetStatus("vid2", "pt", StatusSummarizeProcessed)
+
+	// Simulate a restart
+	restored, err := NewDurableProcessor(journal, DefaultTTL)
+	if err != nil {
+		t.Fatalf("NewDurableProcessor after restart: %v", err)
+	}
+
+	if status := restored.GetStatus("vid1", "en"); status != StatusMetadataProcessed {
+		t.Errorf("Expected status '%s', got '%s'", StatusMetadataProcessed, status)
+	}
+	meta := restored.GetVideoMeta("vid1", "en")
+	if meta == nil || meta.DownSubDownloadCap != "https://example.com/caps.srt" {
+		t.Errorf("Expected metadata to survive restart, got %v", meta)
+	}
+
+	resumable := restored.Resumable()
+	if len(resumable) != 1 || resumable[0].VideoID != "vid1" {
+		t.Errorf("Expected only vid1 to be resumable, got %v", resumable)
+	}
+}
+
+func TestDurableProcessor_KeepsInterruptedJobs(t *testing.T) {
+	journal, err := NewFileJournal(filepath.Join(t.TempDir(), "queue.json"))
+	if err != nil {
+		t.Fatalf("NewFileJournal: %v", err)
+	}
+
+	stale := time.Now().Add(-time.Hour)
+	err = journal.Save([]ProcessingVideo{
+		{VideoID: "inflight", Language: "en", Status: StatusDownloadProcessed, Expires: stale},
+		{VideoID: "done", Language: "en", Status: StatusSummarizeProcessed, Expires: stale},
+		{VideoID: "translating", Language: "en", Status: StatusTranslating, Expires: stale},
+	})
+	if err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	p, err := NewDurableProcessor(journal, DefaultTTL)
+	if err != nil {
+		t.Fatalf("NewDurableProcessor: %v", err)
+	}
+
+	if !p.Exists("inflight", "en") {
+		t.Errorf("Expected interrupted job to be kept")
+	}
+	if p.Exists("done", "en") {
+		t.Errorf("Expected expired completed job to be dropped")
+	}
+	if p.Exists("translating", "en") {
+		t.Errorf("Expected a translation nothing resumes to be dropped")
+	}
+}
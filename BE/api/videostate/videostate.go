@@ -2,7 +2,9 @@ package videostate
 
 import (
 	"fmt"
+	"log"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 )
@@ -23,6 +25,7 @@ type Metadata struct {
 	ArticleUploadDateTime string `json:"article_update_datetime,omitempty"`
 	Duration              int `json:"duration,omitempty"`
 	LikeCount			  int `json:"like_count,omitempty"`
+	ViewCount			  int `json:"view_count,omitempty"`
 	DownSubDownloadCap	  string `json:"downsub_download_cap,omitempty"`
 }
 
@@ -45,19 +48,91 @@ const (
 	StatusMetadataProcessed    VideoStatus = "metadata-processed"
 	StatusDownloadProcessed    VideoStatus = "download-processed"
 	StatusDownloadAWSProcessed VideoStatus = "download-aws-processed"
+	StatusTranslating          VideoStatus = "processing-translation"
 	StatusSummarizeProcessed   VideoStatus = "completed"
 	StatusMetadataTTlExceeded  VideoStatus = "error-metadata-ttl-exceeded" 
+	StatusProcessingFailed     VideoStatus = "error-processing-failed"
 )
 
 
+// DefaultTTL is how long a completed or failed job stays in the queue. Jobs that are
+// still running are never evicted.
+const DefaultTTL = 40 * time.Second
+
+// StatusListener is notified of every status transition of a job.
+type StatusListener func(videoID string, language string, status VideoStatus)
+
 type Processor struct {
 	mu     	sync.Mutex
 	videos []ProcessingVideo
+	ttl     time.Duration
+	journal Journal
+	listeners []StatusListener
 }
 
 func NewProcessor() *Processor {
 	return &Processor{
 		videos: make([]ProcessingVideo, 0),
+		ttl:    DefaultTTL,
+	}
+}
+
+// NewDurableProcessor restores the queue from the journal and writes every change back to it.
+// Jobs that were interrupted mid-pipeline are kept so they can be resumed, the ones in a
+// stage nothing resumes (e.g. a translation) are dropped and start over on the next request.
+func NewDurableProcessor(journal Journal, ttl time.Duration) (*Processor, error) {
+	videos, err := journal.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Processor{
+		videos:  make([]ProcessingVideo, 0, len(videos)),
+		ttl:     ttl,
+		journal: journal,
+	}
+
+	now := time.Now().UTC()
+	for _, v := range videos {
+		if v.Status.IsResumable() || (v.Status.IsFinal() && v.Expires.After(now)) {
+			p.videos = append(p.videos, v)
+		}
+	}
+	p.persist()
+	return p, nil
+}
+
+// IsResumable reports whether a job in this status can continue from its last completed stage.
+func (s VideoStatus) IsResumable() bool {
+	return s == StatusPending || s == StatusMetadataProcessed || s == StatusDownloadProcessed
+}
+
+// IsFinal reports whether the job is over, completed or failed. Only final jobs expire.
+func (s VideoStatus) IsFinal() bool {
+	return s == StatusSummarizeProcessed || strings.HasPrefix(string(s), "error")
+}
+
+// Resumable returns the jobs that were left mid-pipeline.
+func (p *Processor) Resumable() []ProcessingVideo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	resumable := make([]ProcessingVideo, 0)
+	for _, v := range p.videos {
+		if v.Status.IsResumable() {
+			resumable = append(resumable, v)
+		}
+	}
+	return resumable
+}
+
+// persist must be called with p.mu held.
+func (p *Processor) persist() {
+	if p.journal == nil {
+		return
+	}
+	if err := p.journal.Save(p.videos); err != nil {
+		log.Printf("❌ Failed to persist processing queue: %v", err)
 	}
 }
 
@@ -149,8 +224,7 @@ func (p *Processor) Videos() []ProcessingVideo {
 func (p *Processor) Add(newVideo ProcessingVideo) {
     p.mu.Lock()
     defer p.mu.Unlock()
-
-	var secondsUntilExpires = 40
+    defer p.persist()
 
     timeNow := time.Now().UTC()
 
@@ -177,7 +251,7 @@ func (p *Processor) Add(newVideo ProcessingVideo) {
         }
     }
 
-    newVideo.Expires = timeNow.Add(time.Duration(secondsUntilExpires) * time.Second)
+    newVideo.Expires = timeNow.Add(p.ttl)
     newVideo.Status = StatusPending
     p.videos = append(p.videos, newVideo)
 }
@@ -257,6 +331,7 @@ func (p *Processor) GetSecondsSinceArticleUpload(videoID string, language string
 func (p *Processor) SetRetrySummaryStatus(videoID string, language string, retrySummary bool) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.persist()
 
 	for i, v := range p.videos {
 		if v.VideoID == videoID && v.Language == language {
@@ -284,6 +359,7 @@ func (p *Processor) GetRetrySummaryStatus(videoID string, language string) bool
 func (p *Processor) DecreaseTTLMetadata(videoID string, language string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.persist()
 
 	for i, v := range p.videos {
 		if v.VideoID == videoID && v.Language == language {
@@ -309,6 +385,7 @@ func (p *Processor) GetTTLMetadata(videoID string, language string) int {
 func (p *Processor) SetTTLMetadata(videoID string, language string, ttl int) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.persist()
 
 	for i, v := range p.videos {
 		if v.VideoID == videoID && v.Language == language {
@@ -322,6 +399,7 @@ func (p *Processor) SetTTLMetadata(videoID string, language string, ttl int) {
 func (p *Processor) SetPipeline(videoID string, language string, pipeline string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.persist()
 
 	for i, v := range p.videos {
 		if v.VideoID == videoID && v.Language == language {
@@ -357,24 +435,53 @@ func (p *Processor) GetStatus(videoID string, language string) VideoStatus {
 }
 
 func (p *Processor) SetStatus(videoID string, language string, videoState VideoStatus){
+	if !p.setStatus(videoID, language, videoState) {
+		return
+	}
+
+	p.mu.Lock()
+	listeners := p.listeners
+	p.mu.Unlock()
+
+	// listeners run outside the lock so they can read the queue back
+	for _, listener := range listeners {
+		listener(videoID, language, videoState)
+	}
+}
+
+// OnStatusChange registers a listener called after every status transition.
+func (p *Processor) OnStatusChange(listener StatusListener) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.listeners = append(p.listeners, listener)
+}
+
+// setStatus reports whether the job existed and its status actually changed.
+func (p *Processor) setStatus(videoID string, language string, videoState VideoStatus) bool {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	defer p.persist()
 
 	for i, v := range p.videos {
 		if v.VideoID == videoID && v.Language == language {
+			changed := v.Status != videoState
 			p.videos[i].Status = videoState
+			// the TTL starts when the job is over, a running job is never evicted
+			if videoState.IsFinal() {
+				p.videos[i].Expires = time.Now().UTC().Add(p.ttl)
+			}
 			if p.videos[i].Metadata.Status == nil {
 				p.videos[i].Metadata.Status = make(map[string]string)
 			}
 			p.videos[i].Metadata.Status[language] = string(videoState)
-			return
+			return changed
 		}
 	}
-	return 
+	return false
 }
 
 
-// Remove expired videos from processing queue
+// Cleanup removes the completed and failed jobs whose TTL is over
 func (p *Processor) Cleanup() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -383,12 +490,15 @@ func (p *Processor) Cleanup() {
 	newList := make([]ProcessingVideo, 0)
 
 	for _, v := range p.videos {
-		if v.Expires.After(now) {
+		if !v.Status.IsFinal() || v.Expires.After(now) {
 			newList = append(newList, v)
 		}
 	}
 
-	p.videos = newList
+	if len(newList) != len(p.videos) {
+		p.videos = newList
+		p.persist()
+	}
 }
 
 func (p *Processor) GetVideoMeta(videoID string, language string) (*Metadata ) {
@@ -250,6 +250,7 @@ func TestCleanupRemovesExpired(t *testing.T) {
 	video := ProcessingVideo{
 		VideoID: "old123",
 		Language: "pt",
+		Status: StatusSummarizeProcessed,
 		Expires: time.Now().Add(-30 * time.Second),
 	}
 
@@ -264,6 +265,37 @@ func TestCleanupRemovesExpired(t *testing.T) {
 	}
 }
 
+func TestCleanupKeepsRunningJobs(t *testing.T) {
+	p := NewProcessor()
+
+	stale := time.Now().Add(-time.Hour)
+	p.mu.Lock()
+	p.videos = append(p.videos,
+		ProcessingVideo{VideoID: "chunked", Language: "pt", Status: StatusDownloadProcessed, Expires: stale},
+		ProcessingVideo{VideoID: "translated", Language: "pt", Status: StatusTranslating, Expires: stale},
+		ProcessingVideo{VideoID: "failed", Language: "pt", Status: StatusProcessingFailed, Expires: stale},
+	)
+	p.mu.Unlock()
+
+	if !p.Exists("chunked", "pt") || !p.Exists("translated", "pt") {
+		t.Errorf("Expected running jobs to survive their expiry")
+	}
+	if p.Exists("failed", "pt") {
+		t.Errorf("Expected expired failed job to be cleaned up")
+	}
+}
+
+func TestSetStatusStartsTTLWhenFinal(t *testing.T) {
+	p := NewProcessor()
+	p.Add(ProcessingVideo{VideoID: "vid", Language: "en"})
+
+	p.SetStatus("vid", "en", StatusSummarizeProcessed)
+	expires := p.Videos()[0].Expires
+	if time.Until(expires) <= 0 || time.Until(expires) > DefaultTTL {
+		t.Errorf("Expected the TTL to start on completion, expires in %v", time.Until(expires))
+	}
+}
+
 func TestProcessor_DecreaseTTLMetadata(t *testing.T) {
 	processor := NewProcessor()
 	videoID := "test-video"
@@ -352,3 +384,29 @@ func TestProcessor_DecreaseTTLMetadata_MultipleDecreases(t *testing.T) {
 		t.Errorf("Expected TTL -1, got %d", ttl)
 	}
 }
+
+func TestProcessor_OnStatusChange(t *testing.T) {
+	p := NewProcessor()
+	p.Add(ProcessingVideo{VideoID: "vid", Language: "en"})
+
+	var transitions []VideoStatus
+	p.OnStatusChange(func(videoID string, language string, status VideoStatus) {
+		if videoID != "vid" || language != "en" {
+			t.Errorf("Unexpected listener call for %s/%s", videoID, language)
+		}
+		// Listeners must be able to read the queue without deadlocking
+		if p.GetStatus(videoID, language) != status {
+			t.Errorf("Expected status to be applied before notifying")
+		}
+		transitions = append(transitions, status)
+	})
+
+	p.SetStatus("vid", "en", StatusMetadataProcessed)
+	p.SetStatus("vid", "en", StatusMetadataProcessed) // no transition
+	p.SetStatus("vid", "en", StatusDownloadProcessed)
+	p.SetStatus("missing", "en", StatusDownloadProcessed)
+
+	if len(transitions) != 2 || transitions[0] != StatusMetadataProcessed || transitions[1] != StatusDownloadProcessed {
+		t.Errorf("Unexpected transitions %v", transitions)
+	}
+}
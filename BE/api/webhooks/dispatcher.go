@@ -0,0 +1,310 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Sumtube-Signature"
+	TimestampHeader = "X-Sumtube-Timestamp"
+	EventHeader     = "X-Sumtube-Event"
+	DeliveryHeader  = "X-Sumtube-Delivery"
+)
+
+// ErrNoSecret is returned by Send when the dispatcher has no signing secret: a payload signed
+// with an empty key could be forged by anyone.
+var ErrNoSecret = errors.New("webhook signing secret is not set")
+
+// Attempt is one POST of a delivery to its callback URL.
+type Attempt struct {
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Delivery is the log entry of one event sent to one callback URL.
+type Delivery struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Event     string    `json:"event"`
+	Topic     string    `json:"topic"`
+	Delivered bool      `json:"delivered"`
+	Pending   bool      `json:"pending"`
+	Attempts  []Attempt `json:"attempts"`
+}
+
+// Dispatcher POSTs signed JSON payloads to callback URLs, retrying failed deliveries
+// after each of the configured delays, and keeps a log of the latest deliveries.
+// Callbacks only reach public addresses and redirects are not followed, so a callback URL
+// cannot be used to call the internal services.
+type Dispatcher struct {
+	client       *http.Client
+	secret       string
+	delays       []time.Duration
+	maxLog       int
+	allowPrivate bool
+
+	mu         sync.Mutex
+	deliveries []*Delivery
+}
+
+func NewDispatcher(secret string, delays []time.Duration, maxLog int) *Dispatcher {
+	d := &Dispatcher{
+		secret: secret,
+		delays: delays,
+		maxLog: maxLog,
+	}
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: d.checkAddress}
+	d.client = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return d
+}
+
+// Enabled reports whether the dispatcher has a secret to sign the payloads with.
+func (d *Dispatcher) Enabled() bool {
+	return d.secret != ""
+}
+
+// AllowPrivateAddresses lets the dispatcher call loopback and private addresses,
+// for tests and receivers running next to the API.
+func (d *Dispatcher) AllowPrivateAddresses() {
+	d.allowPrivate = true
+}
+
+// checkAddress runs after DNS resolution, right before each connection, so a host
+// resolving to an internal address is refused even when it changed since ValidateURL.
+func (d *Dispatcher) checkAddress(network string, address string, conn syscall.RawConn) error {
+	if d.allowPrivate {
+		return nil
+	}
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid callback address %q: %w", address, err)
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("callback address %s is not public", addrPort.Addr())
+	}
+	return nil
+}
+
+// carrier-grade NAT, shared with the internal network on some hosts
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// isPublicAddr rejects loopback, private, link-local (e.g. the cloud metadata endpoint),
+// multicast and unspecified addresses.
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// lookupHost resolves callback hosts, replaced in tests.
+var lookupHost = func(ctx context.Context, host string) ([]netip.Addr, error) {
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
+
+var errInternalHost = errors.New("callback URL must point to a public host")
+
+// ValidateURL accepts absolute http(s) URLs whose host resolves to public addresses only.
+// Internal names such as "localhost", "api-server" or "metadata.google.internal" are refused.
+func ValidateURL(callbackURL string) error {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return fmt.Errorf("invalid callback URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("callback URL must be an absolute http(s) URL")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !isPublicAddr(addr) {
+			return errInternalHost
+		}
+		return nil
+	}
+	if !strings.Contains(host, ".") {
+		return errInternalHost
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".localdomain", ".home.arpa"} {
+		if strings.HasSuffix(host, suffix) {
+			return errInternalHost
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := lookupHost(ctx, host)
+	if err != nil {
+		return fmt.Errorf("callback host %s does not resolve: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if !isPublicAddr(addr) {
+			return errInternalHost
+		}
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "{timestamp}.{body}", sent as "sha256={signature}".
+// Receivers recompute it with the shared secret to authenticate the payload.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send delivers payload in the background and returns the log entry of the delivery.
+// Nothing is sent without a signing secret.
+// topic groups deliveries in the log (e.g. "{videoId}#{lang}").
+func (d *Dispatcher) Send(callbackURL string, event string, topic string, payload interface{}) (Delivery, error) {
+	if !d.Enabled() {
+		return Delivery{}, ErrNoSecret
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	id, err := newDeliveryID()
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	delivery := &Delivery{
+		ID:       id,
+		URL:      callbackURL,
+		Event:    event,
+		Topic:    topic,
+		Pending:  true,
+		Attempts: []Attempt{},
+	}
+	d.mu.Lock()
+	d.deliveries = append(d.deliveries, delivery)
+	if d.maxLog > 0 && len(d.deliveries) > d.maxLog {
+		d.deliveries = d.deliveries[len(d.deliveries)-d.maxLog:]
+	}
+	snapshot := copyDelivery(delivery)
+	d.mu.Unlock()
+
+	go d.deliver(delivery, body)
+	return snapshot, nil
+}
+
+func (d *Dispatcher) deliver(delivery *Delivery, body []byte) {
+	for attempt := 0; ; attempt++ {
+		result := d.post(delivery, body)
+
+		d.mu.Lock()
+		delivery.Attempts = append(delivery.Attempts, result)
+		delivered := result.Error == "" && result.StatusCode >= 200 && result.StatusCode < 300
+		if delivered || attempt >= len(d.delays) {
+			delivery.Delivered = delivered
+			delivery.Pending = false
+		}
+		d.mu.Unlock()
+
+		if delivered {
+			log.Printf("📬 Webhook %s delivered to %s", delivery.ID, delivery.URL)
+			return
+		}
+		if attempt >= len(d.delays) {
+			log.Printf("❌ Webhook %s to %s failed after %d attempts", delivery.ID, delivery.URL, attempt+1)
+			return
+		}
+		time.Sleep(d.delays[attempt])
+	}
+}
+
+func (d *Dispatcher) post(delivery *Delivery, body []byte) Attempt {
+	result := Attempt{Time: time.Now().UTC()}
+
+	req, err := http.NewRequest("POST", delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	timestamp := strconv.FormatInt(result.Time.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(d.secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return result
+}
+
+// Deliveries returns the logged deliveries of a topic, oldest first.
+func (d *Dispatcher) Deliveries(topic string) []Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deliveries := []Delivery{}
+	for _, delivery := range d.deliveries {
+		if delivery.Topic == topic {
+			deliveries = append(deliveries, copyDelivery(delivery))
+		}
+	}
+	return deliveries
+}
+
+func copyDelivery(delivery *Delivery) Delivery {
+	c := *delivery
+	c.Attempts = append([]Attempt{}, delivery.Attempts...)
+	return c
+}
+
+func newDeliveryID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,202 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func waitForDelivery(t *testing.T, d *Dispatcher, topic string) Delivery {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if deliveries := d.Deliveries(topic); len(deliveries) == 1 && !deliveries[0].Pending {
+			return deliveries[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Delivery of %s did not finish", topic)
+	return Delivery{}
+}
+
+func TestDispatcher_SignedDelivery(t *testing.T) {
+	var received atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := "sha256=" + Sign("secret", r.Header.Get(TimestampHeader), body)
+		if r.Header.Get(SignatureHeader) != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != "summary.completed" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received.Store(string(body))
+	}))
+	defer server.Close()
+
+	d := NewDispatcher("secret", nil, 10)
+	d.AllowPrivateAddresses()
+	if _, err := d.Send(server.URL, "summary.completed", "vid#en", map[string]string{"videoId": "vid"}); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	delivery := waitForDelivery(t, d, "vid#en")
+	if !delivery.Delivered || len(delivery.Attempts) != 1 || delivery.Attempts[0].StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected delivery %+v", delivery)
+	}
+	var payload map[string]string
+	json.Unmarshal([]byte(received.Load().(string)), &payload)
+	if payload["videoId"] != "vid" {
+		t.Errorf("Unexpected payload %v", payload)
+	}
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	d := NewDispatcher("secret", []time.Duration{time.Millisecond, time.Millisecond}, 10)
+	d.AllowPrivateAddresses()
+	d.Send(server.URL, "summary.completed", "retry#en", nil)
+
+	delivery := waitForDelivery(t, d, "retry#en")
+	if !delivery.Delivered || len(delivery.Attempts) != 3 {
+		t.Fatalf("Expected success on the third attempt, got %+v", delivery)
+	}
+	if delivery.Attempts[0].StatusCode != http.StatusServiceUnavailable || delivery.Attempts[0].Error == "" {
+		t.Errorf("Expected the first attempt to be logged as failed, got %+v", delivery.Attempts[0])
+	}
+}
+
+func TestDispatcher_GivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher("secret", []time.Duration{time.Millisecond}, 10)
+	d.AllowPrivateAddresses()
+	d.Send(server.URL, "summary.failed", "fail#en", nil)
+
+	delivery := waitForDelivery(t, d, "fail#en")
+	if delivery.Delivered || len(delivery.Attempts) != 2 {
+		t.Fatalf("Expected 2 failed attempts, got %+v", delivery)
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	original := lookupHost
+	lookupHost = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.215.14")}, nil
+		case "rebind.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.215.14"), netip.MustParseAddr("10.0.0.5")}, nil
+		case "metadata.example.com":
+			return []netip.Addr{netip.MustParseAddr("169.254.169.254")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	defer func() { lookupHost = original }()
+
+	for url, valid := range map[string]bool{
+		"https://example.com/hook":           true,
+		"https://93.184.215.14/hook":         true,
+		"http://localhost:3000/cb":           false,
+		"http://127.0.0.1:8080/cb":           false,
+		"http://[::1]/cb":                    false,
+		"http://[::ffff:127.0.0.1]/cb":       false,
+		"http://10.1.2.3/cb":                 false,
+		"http://192.168.1.1/cb":              false,
+		"http://169.254.169.254/latest":      false,
+		"http://100.64.0.1/cb":               false,
+		"http://0.0.0.0/cb":                  false,
+		"http://api-server:8080/summary":     false,
+		"http://metadata.google.internal/cb": false,
+		"http://printer.local/cb":            false,
+		"https://rebind.example.com/hook":    false,
+		"https://metadata.example.com/hook":  false,
+		"https://unknown.example.com/hook":   false,
+		"ftp://example.com/hook":             false,
+		"/relative/path":                     false,
+		"not a url":                          false,
+	} {
+		if err := ValidateURL(url); (err == nil) != valid {
+			t.Errorf("ValidateURL(%q) = %v, want valid=%v", url, err, valid)
+		}
+	}
+}
+
+func TestDispatcher_RefusesPrivateAddresses(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher("secret", nil, 10)
+	d.Send(server.URL, "summary.completed", "private#en", nil)
+
+	delivery := waitForDelivery(t, d, "private#en")
+	if delivery.Delivered || calls.Load() != 0 {
+		t.Fatalf("Expected the loopback callback to be refused, got %+v", delivery)
+	}
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	var redirected atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected.Add(1)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher("secret", nil, 10)
+	d.AllowPrivateAddresses()
+	d.Send(server.URL, "summary.completed", "redirect#en", nil)
+
+	delivery := waitForDelivery(t, d, "redirect#en")
+	if delivery.Delivered || redirected.Load() != 0 {
+		t.Fatalf("Expected the redirect not to be followed, got %+v", delivery)
+	}
+	if delivery.Attempts[0].StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("Expected the redirect status to be logged, got %+v", delivery.Attempts[0])
+	}
+}
+
+func TestDispatcher_RefusesWithoutSecret(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher("", nil, 10)
+	d.AllowPrivateAddresses()
+	if d.Enabled() {
+		t.Errorf("Expected a dispatcher without secret to be disabled")
+	}
+	if _, err := d.Send(server.URL, "summary.completed", "vid#en", map[string]string{}); err != ErrNoSecret {
+		t.Errorf("Expected ErrNoSecret, got %v", err)
+	}
+	if len(d.Deliveries("vid#en")) != 0 || calls.Load() != 0 {
+		t.Errorf("Expected nothing to be sent")
+	}
+}
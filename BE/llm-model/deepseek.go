@@ -9,8 +9,9 @@ import (
 	"os"
 )
 
-// CallDeepSeek calls the DeepSeek API with the given user prompt and returns the response content
-func CallDeepSeek(systemPrompt string, userPrompt string) (string, error) {
+// CallDeepSeek calls the DeepSeek API with the given user prompt and returns the response content,
+// jsonMode turns on the DeepSeek JSON output (the prompt must then ask for JSON)
+func CallDeepSeek(systemPrompt string, userPrompt string, jsonMode bool) (string, error) {
 	apiURL := "https://api.deepseek.com/chat/completions"
 	apiKey := os.Getenv("DEEPSEEK_API_KEY")
 	if apiKey == "" {
@@ -28,6 +29,9 @@ func CallDeepSeek(systemPrompt string, userPrompt string) (string, error) {
 		},
 		"stream": false,
 	}
+	if jsonMode {
+		requestBody["response_format"] = map[string]string{"type": "json_object"}
+	}
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
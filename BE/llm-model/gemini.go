@@ -9,8 +9,9 @@ import (
 	"os"
 )
 
-// CallGemini calls the Gemini 2.0 Flash API with system and user prompts
-func CallGemini(systemPrompt string, userPrompt string) (string, error) {
+// CallGemini calls the Gemini 2.0 Flash API with system and user prompts, a non-nil
+// responseSchema constrains the answer to JSON matching it
+func CallGemini(systemPrompt string, userPrompt string, responseSchema map[string]interface{}) (string, error) {
 	apiURL := "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
 	apiKey := os.Getenv("GEMINI_API_KEY")
 	if apiKey == "" {
@@ -29,6 +30,12 @@ func CallGemini(systemPrompt string, userPrompt string) (string, error) {
 			},
 		},
 	}
+	if responseSchema != nil {
+		requestBody["generationConfig"] = map[string]interface{}{
+			"responseMimeType": "application/json",
+			"responseSchema":   responseSchema,
+		}
+	}
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
@@ -16,6 +16,7 @@ type InputPayload struct {
 	Language string `json:"language"`
 	Title    string `json:"title"`
 	Captions string `json:"captions"`
+	Question string `json:"question,omitempty"`
 }
 
 // RequestPayload defines the expected input parameters
@@ -35,6 +36,7 @@ type ResponsePayload struct {
 	Input           InputPayload `json:"input"`
 	Result          interface{}  `json:"result,omitempty"`
 	Error           string       `json:"error,omitempty"`
+	Attempts        int          `json:"attempts,omitempty"`
 	RequestDuration string       `json:"request_duration"`
 }
 
@@ -86,6 +88,7 @@ func summarizeHandler(w http.ResponseWriter, r *http.Request) {
 		"$$language$$": lang,
 		"$$title$$":    title,
 		"$$captions$$": caption,
+		"$$question$$": req.Input.Question,
 	}
 	systemPromptReplaced := replacePlaceholders(systemPrompt, placeHolders)
 	userPromptReplaced := replacePlaceholders(userPrompt, placeHolders)
@@ -107,26 +110,27 @@ func summarizeHandler(w http.ResponseWriter, r *http.Request) {
 		Input:  req.Input,
 	}
 
+	jsonOutput := req.Output == OutputJSON
+
 	switch req.Model {
 	case "deepseekr1":
 		// Format userPrompt with inputs
 
-		summary, err := CallDeepSeek(systemPromptReplaced, userPromptReplaced)
-		if err != nil {
-			resp.Error = err.Error()
-		} else {
-			resp.Result = summary
+		call := func(systemPrompt string, userPrompt string) (string, error) {
+			return CallDeepSeek(systemPrompt, userPrompt, jsonOutput)
 		}
+		setModelResult(&resp, call, systemPromptReplaced, userPromptReplaced, jsonOutput)
 
 	case "gemini-2.0-flash":
 		// Build Gemini prompt
 
-		summary, err := CallGemini(systemPromptReplaced, userPromptReplaced)
-		if err != nil {
-			resp.Error = err.Error()
-		} else {
-			resp.Result = summary
+		call := func(systemPrompt string, userPrompt string) (string, error) {
+			if jsonOutput {
+				return CallGemini(systemPrompt, userPrompt, videoSummarySchema)
+			}
+			return CallGemini(systemPrompt, userPrompt, nil)
 		}
+		setModelResult(&resp, call, systemPromptReplaced, userPromptReplaced, jsonOutput)
 
 	default:
 		resp.Result = map[string]interface{}{
@@ -142,6 +146,32 @@ func summarizeHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// setModelResult calls the model and stores its answer in resp. In JSON output mode the
+// result is the validated VideoSummary object, when it stays invalid the raw text is kept
+// as result so the caller can still use the legacy ╔$field╗ parser.
+func setModelResult(resp *ResponsePayload, call func(string, string) (string, error), systemPrompt string, userPrompt string, jsonOutput bool) {
+	if !jsonOutput {
+		summary, err := call(systemPrompt, userPrompt)
+		if err != nil {
+			resp.Error = err.Error()
+		} else {
+			resp.Result = summary
+		}
+		return
+	}
+
+	summary, raw, attempts, err := callModelJSON(call, systemPrompt, userPrompt)
+	resp.Attempts = attempts
+	if err != nil {
+		resp.Error = err.Error()
+		if raw != "" {
+			resp.Result = raw
+		}
+		return
+	}
+	resp.Result = summary
+}
+
 func replacePlaceholders(template string, placeholders map[string]interface{}) string {
 	result := template
 	for key, value := range placeholders {
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// OutputJSON is the RequestPayload.Output value asking for schema-constrained JSON
+const OutputJSON = "json"
+
+// maxRepairAttempts is how many times an invalid JSON answer is sent back to the model
+const maxRepairAttempts = 2
+
+// VideoSummary is the Go schema of the JSON answer, it mirrors the api VideoGPTSummary
+type VideoSummary struct {
+	Content string `json:"content"`
+	Lang    string `json:"lang"`
+	Answer  string `json:"answer"`
+	Title   string `json:"title"`
+}
+
+// videoSummarySchema is the Gemini responseSchema (OpenAPI subset) of VideoSummary
+var videoSummarySchema = map[string]interface{}{
+	"type": "OBJECT",
+	"properties": map[string]interface{}{
+		"content": map[string]interface{}{"type": "STRING", "description": "Markdown summary following the content field rules"},
+		"lang":    map[string]interface{}{"type": "STRING", "description": "ISO code of the language of the content"},
+		"answer":  map[string]interface{}{"type": "STRING", "description": "Short answer or rephrased question"},
+		"title":   map[string]interface{}{"type": "STRING", "description": "Title, translated when needed"},
+	},
+	"required":         []string{"content", "lang", "answer", "title"},
+	"propertyOrdering": []string{"content", "lang", "answer", "title"},
+}
+
+// jsonOutputInstructions is appended to the system prompt of the templates, which
+// describe the fields with the legacy ╔$field╗ markers
+const jsonOutputInstructions = `
+
+---
+
+### JSON output (overrides the final output format above):
+Do NOT use the ╔ and ╗ markers. Return only one JSON object, without code fences, with the same fields:
+{"content": "[structured summary]", "lang": "[lang here]", "answer": "[short answer or rephrased question here]", "title": "[title here]"}`
+
+var langCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z]{2,4})?$`)
+
+// Validate reports the first field of the summary breaking the schema
+func (s VideoSummary) Validate() error {
+	if strings.TrimSpace(s.Content) == "" {
+		return fmt.Errorf("field 'content' is required")
+	}
+	if strings.TrimSpace(s.Title) == "" {
+		return fmt.Errorf("field 'title' is required")
+	}
+	if strings.TrimSpace(s.Answer) == "" {
+		return fmt.Errorf("field 'answer' is required")
+	}
+	if !langCodePattern.MatchString(s.Lang) {
+		return fmt.Errorf("field 'lang' must be an ISO language code, got %q", s.Lang)
+	}
+	return nil
+}
+
+// parseVideoSummary decodes and validates a JSON answer of the model, tolerating
+// markdown code fences around it
+func parseVideoSummary(raw string) (VideoSummary, error) {
+	text := strings.TrimSpace(raw)
+	text = strings.TrimPrefix(text, "```json")
+	text = strings.TrimPrefix(text, "```")
+	text = strings.TrimSuffix(text, "```")
+	text = strings.TrimSpace(text)
+
+	var summary VideoSummary
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&summary); err != nil {
+		return VideoSummary{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	summary.Lang = strings.TrimSpace(summary.Lang)
+	if err := summary.Validate(); err != nil {
+		return VideoSummary{}, err
+	}
+	return summary, nil
+}
+
+// repairPrompt asks the model to fix its previous answer
+func repairPrompt(userPrompt string, previous string, validationErr error) string {
+	return fmt.Sprintf(`%s
+
+---
+
+Your previous answer was rejected: %v
+Previous answer:
+%s
+
+Return only the corrected JSON object with the fields "content", "lang", "answer" and "title".`, userPrompt, validationErr, previous)
+}
+
+// callModelJSON calls the model in JSON mode and retries with a repair prompt until the
+// answer matches VideoSummary. The last raw answer is returned along with the error so
+// the caller can still fall back to the legacy parser.
+func callModelJSON(call func(systemPrompt string, userPrompt string) (string, error), systemPrompt string, userPrompt string) (VideoSummary, string, int, error) {
+	systemPrompt += jsonOutputInstructions
+	prompt := userPrompt
+	var raw string
+	var lastErr error
+
+	for attempt := 1; attempt <= maxRepairAttempts+1; attempt++ {
+		answer, err := call(systemPrompt, prompt)
+		if err != nil {
+			return VideoSummary{}, raw, attempt, err
+		}
+		raw = answer
+
+		summary, err := parseVideoSummary(answer)
+		if err == nil {
+			return summary, raw, attempt, nil
+		}
+		lastErr = err
+		log.Printf("⚠️ Structured output rejected (attempt %d): %v", attempt, err)
+		prompt = repairPrompt(userPrompt, answer, err)
+	}
+
+	return VideoSummary{}, raw, maxRepairAttempts + 1, fmt.Errorf("structured output still invalid after %d repairs: %w", maxRepairAttempts, lastErr)
+}
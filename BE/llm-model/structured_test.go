@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const validSummaryJSON = `{"content": "## Key points\n- Go is simple", "lang": "en", "answer": "Use Go", "title": "Why Go"}`
+
+func TestParseVideoSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr string
+	}{
+		{name: "valid", raw: validSummaryJSON},
+		{name: "code fences", raw: "```json\n" + validSummaryJSON + "\n```"},
+		{name: "region code", raw: `{"content": "c", "lang": "pt-BR", "answer": "a", "title": "t"}`},
+		{name: "not JSON", raw: "╔$content:Summary.╗", wantErr: "invalid JSON"},
+		{name: "truncated", raw: `{"content": "c", "lang": "en"`, wantErr: "invalid JSON"},
+		{name: "unknown field", raw: `{"content": "c", "lang": "en", "answer": "a", "title": "t", "extra": 1}`, wantErr: "invalid JSON"},
+		{name: "wrong type", raw: `{"content": 42, "lang": "en", "answer": "a", "title": "t"}`, wantErr: "invalid JSON"},
+		{name: "missing content", raw: `{"lang": "en", "answer": "a", "title": "t"}`, wantErr: "'content' is required"},
+		{name: "blank title", raw: `{"content": "c", "lang": "en", "answer": "a", "title": "  "}`, wantErr: "'title' is required"},
+		{name: "missing answer", raw: `{"content": "c", "lang": "en", "title": "t"}`, wantErr: "'answer' is required"},
+		{name: "language name", raw: `{"content": "c", "lang": "English", "answer": "a", "title": "t"}`, wantErr: "'lang' must be an ISO language code"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, err := parseVideoSummary(tt.raw)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected a valid summary, got %v", err)
+				}
+				if summary.Content == "" || summary.Title == "" {
+					t.Errorf("Unexpected summary %+v", summary)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+// scriptedModel answers with the given answers in order and records the prompts it got
+type scriptedModel struct {
+	answers []string
+	prompts []string
+}
+
+func (m *scriptedModel) call(systemPrompt string, userPrompt string) (string, error) {
+	if !strings.Contains(systemPrompt, "JSON output") {
+		return "", errors.New("the JSON instructions are missing from the system prompt")
+	}
+	m.prompts = append(m.prompts, userPrompt)
+	answer := m.answers[0]
+	if len(m.answers) > 1 {
+		m.answers = m.answers[1:]
+	}
+	return answer, nil
+}
+
+func TestCallModelJSON_ValidFirstAnswer(t *testing.T) {
+	model := &scriptedModel{answers: []string{validSummaryJSON}}
+
+	summary, raw, attempts, err := callModelJSON(model.call, "system", "summarize")
+	if err != nil {
+		t.Fatalf("callModelJSON: %v", err)
+	}
+	if attempts != 1 || raw != validSummaryJSON || summary.Title != "Why Go" {
+		t.Errorf("Unexpected result %+v, %q after %d attempts", summary, raw, attempts)
+	}
+}
+
+func TestCallModelJSON_RepairSucceeds(t *testing.T) {
+	invalid := `{"content": "c", "lang": "English", "answer": "a", "title": "t"}`
+	model := &scriptedModel{answers: []string{invalid, validSummaryJSON}}
+
+	summary, _, attempts, err := callModelJSON(model.call, "system", "summarize")
+	if err != nil {
+		t.Fatalf("callModelJSON: %v", err)
+	}
+	if attempts != 2 || summary.Lang != "en" {
+		t.Errorf("Expected the repaired summary on the second attempt, got %+v after %d attempts", summary, attempts)
+	}
+	repair := model.prompts[1]
+	if !strings.HasPrefix(repair, "summarize") || !strings.Contains(repair, invalid) || !strings.Contains(repair, "'lang' must be an ISO language code") {
+		t.Errorf("Expected the repair prompt to quote the answer and the error, got %q", repair)
+	}
+}
+
+func TestCallModelJSON_AttemptsRunOut(t *testing.T) {
+	model := &scriptedModel{answers: []string{"not json"}}
+
+	_, raw, attempts, err := callModelJSON(model.call, "system", "summarize")
+	if err == nil || !strings.Contains(err.Error(), "invalid JSON") {
+		t.Fatalf("Expected the last validation error, got %v", err)
+	}
+	if attempts != maxRepairAttempts+1 || len(model.prompts) != maxRepairAttempts+1 {
+		t.Errorf("Expected %d attempts, got %d (%d calls)", maxRepairAttempts+1, attempts, len(model.prompts))
+	}
+	if raw != "not json" {
+		t.Errorf("Expected the last raw answer for the legacy parser, got %q", raw)
+	}
+}
+
+func TestCallModelJSON_CallError(t *testing.T) {
+	failing := func(systemPrompt string, userPrompt string) (string, error) {
+		return "", errors.New("quota exceeded")
+	}
+
+	_, _, attempts, err := callModelJSON(failing, "system", "summarize")
+	if err == nil || err.Error() != "quota exceeded" || attempts != 1 {
+		t.Errorf("Expected the call error without repairs, got %v after %d attempts", err, attempts)
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// maxExportVideos bounds the category and library exports, each video is one API call
+const maxExportVideos = 50
+
+// exportFormats maps the ?format= values to their content type and file extension
+var exportFormats = map[string]struct {
+	contentType string
+	extension   string
+}{
+	"md":   {"text/markdown; charset=utf-8", "md"},
+	"html": {"text/html; charset=utf-8", "html"},
+	"epub": {"application/epub+zip", "epub"},
+}
+
+var unsafeFileName = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// fetchExportVideo loads one stored summary of an export, tests replace it. Exports only
+// read, they never enqueue the videos that are not summarized yet.
+var fetchExportVideo = GetStoredVideoContent
+
+// Export is the document built from one or more summaries
+type Export struct {
+	Title    string
+	Lang     string
+	Source   string
+	FileName string
+	Videos   []*MetadataSingleLanguage
+}
+
+// exportVideoURL is the summary page of the video on the site
+func exportVideoURL(video *MetadataSingleLanguage) string {
+	return fmt.Sprintf("%s/%s/%s/%s", os.Getenv("BASE_URL"), video.Lang, video.Vid, video.Path)
+}
+
+// exportContent is the markdown summary with its timestamps linked to the video
+func exportContent(video *MetadataSingleLanguage) string {
+	return strings.TrimSpace(ReplaceMarkdownTimestamps(video.Vid, video.Summary))
+}
+
+// yamlString quotes a front matter value, JSON strings are valid YAML
+func yamlString(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+// RenderMarkdown writes the export as Markdown with a YAML front matter, which Obsidian
+// and Notion read as page properties
+func (e *Export) RenderMarkdown() []byte {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	if len(e.Videos) == 1 {
+		video := e.Videos[0]
+		fmt.Fprintf(&b, "title: %s\n", yamlString(video.Title))
+		fmt.Fprintf(&b, "video_id: %s\n", yamlString(video.Vid))
+		fmt.Fprintf(&b, "video_url: %s\n", yamlString("https://youtu.be/"+video.Vid))
+		if video.ChannelName != "" {
+			fmt.Fprintf(&b, "channel: %s\n", yamlString(video.ChannelName))
+		}
+		if video.UploadDate != "" {
+			fmt.Fprintf(&b, "upload_date: %s\n", yamlString(video.UploadDate))
+		}
+		if video.Duration > 0 {
+			fmt.Fprintf(&b, "duration: %d\n", video.Duration)
+		}
+		if video.Category != "" {
+			fmt.Fprintf(&b, "category: %s\n", yamlString(video.Category))
+		}
+	} else {
+		fmt.Fprintf(&b, "title: %s\n", yamlString(e.Title))
+		b.WriteString("videos:\n")
+		for _, video := range e.Videos {
+			fmt.Fprintf(&b, "  - %s\n", yamlString("https://youtu.be/"+video.Vid))
+		}
+	}
+	fmt.Fprintf(&b, "language: %s\n", yamlString(e.Lang))
+	fmt.Fprintf(&b, "source: %s\n", yamlString(e.Source))
+	b.WriteString("---\n")
+
+	for i, video := range e.Videos {
+		if i > 0 {
+			b.WriteString("\n---\n")
+		}
+		fmt.Fprintf(&b, "\n# %s\n\n", video.Title)
+		if len(e.Videos) > 1 {
+			fmt.Fprintf(&b, "[%s](https://youtu.be/%s)", video.Vid, video.Vid)
+			if video.ChannelName != "" {
+				fmt.Fprintf(&b, " • %s", video.ChannelName)
+			}
+			b.WriteString("\n\n")
+		}
+		if video.Answer != "" {
+			fmt.Fprintf(&b, "> %s\n\n", strings.ReplaceAll(strings.TrimSpace(video.Answer), "\n", "\n> "))
+		}
+		b.WriteString(exportContent(video) + "\n")
+	}
+	return b.Bytes()
+}
+
+var exportHTMLTemplate = template.Must(template.New("export").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; line-height: 1.6; color: #1f2937; }
+article + article { border-top: 1px solid #e5e7eb; margin-top: 3rem; padding-top: 2rem; }
+blockquote { color: #6b7280; border-left: 4px solid #dc2626; margin: 0; padding-left: 1rem; font-style: italic; }
+a { color: #dc2626; }
+.meta { color: #6b7280; font-size: 0.875rem; }
+</style>
+</head>
+<body>
+{{range .Videos}}<article>
+<h1>{{.Title}}</h1>
+<p class="meta"><a href="https://youtu.be/{{.Vid}}">youtu.be/{{.Vid}}</a>{{if .ChannelName}} • {{.ChannelName}}{{end}}{{if .UploadDate}} • {{.UploadDate}}{{end}}</p>
+{{if .Answer}}<blockquote>{{.Answer}}</blockquote>{{end}}
+{{.Content}}
+</article>
+{{end}}<p class="meta"><a href="{{.Source}}">{{.Source}}</a></p>
+</body>
+</html>
+`))
+
+type exportHTMLVideo struct {
+	*MetadataSingleLanguage
+	Content template.HTML
+}
+
+// RenderHTML writes the export as a standalone page, styles included
+func (e *Export) RenderHTML() ([]byte, error) {
+	videos := make([]exportHTMLVideo, len(e.Videos))
+	for i, video := range e.Videos {
+		videos[i] = exportHTMLVideo{video, template.HTML(ConvertMarkdownToHTML(exportContent(video)))}
+	}
+
+	var b bytes.Buffer
+	err := exportHTMLTemplate.Execute(&b, struct {
+		*Export
+		Videos []exportHTMLVideo
+	}{e, videos})
+	return b.Bytes(), err
+}
+
+// convertMarkdownToXHTML renders markdown as XML, without the smartypants entities
+// (&rsquo;, &ldquo;) which are not defined in XHTML
+func convertMarkdownToXHTML(md string) string {
+	p := parser.NewWithExtensions(parser.CommonExtensions &^ parser.MathJax)
+	renderer := html.NewRenderer(html.RendererOptions{Flags: html.UseXHTML | html.HrefTargetBlank})
+	return string(markdown.ToHTML([]byte(md), p, renderer))
+}
+
+var epubChapterTemplate = template.Must(template.New("chapter").Parse(`<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Lang}}" xml:lang="{{.Lang}}">
+<head><meta charset="utf-8" /><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<p><a href="https://youtu.be/{{.Vid}}">youtu.be/{{.Vid}}</a>{{if .ChannelName}} • {{.ChannelName}}{{end}}</p>
+{{if .Answer}}<blockquote><p>{{.Answer}}</p></blockquote>{{end}}
+{{.Content}}
+</body>
+</html>
+`))
+
+var epubNavTemplate = template.Must(template.New("nav").Parse(`<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="{{.Lang}}" xml:lang="{{.Lang}}">
+<head><meta charset="utf-8" /><title>{{.Title}}</title></head>
+<body>
+<nav epub:type="toc" id="toc"><h1>{{.Title}}</h1><ol>
+{{range $i, $video := .Videos}}<li><a href="video-{{$i}}.xhtml">{{$video.Title}}</a></li>
+{{end}}</ol></nav>
+</body>
+</html>
+`))
+
+const epubContainer = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+type epubFile struct {
+	name    string
+	content []byte
+}
+
+// RenderEPUB writes the export as an EPUB 3 book, one chapter per video
+func (e *Export) RenderEPUB(modified time.Time) ([]byte, error) {
+	var b bytes.Buffer
+	book := zip.NewWriter(&b)
+
+	// the mimetype must be the first entry, stored without compression
+	mimetype, err := book.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return nil, err
+	}
+	mimetype.Write([]byte("application/epub+zip"))
+
+	var nav bytes.Buffer
+	if err := epubNavTemplate.Execute(&nav, e); err != nil {
+		return nil, err
+	}
+	files := []epubFile{
+		{"META-INF/container.xml", []byte(epubContainer)},
+		{"OEBPS/content.opf", e.epubPackage(modified)},
+		{"OEBPS/nav.xhtml", nav.Bytes()},
+	}
+	for i, video := range e.Videos {
+		var chapter bytes.Buffer
+		if err := epubChapterTemplate.Execute(&chapter, exportHTMLVideo{video, template.HTML(convertMarkdownToXHTML(exportContent(video)))}); err != nil {
+			return nil, err
+		}
+		files = append(files, epubFile{fmt.Sprintf("OEBPS/video-%d.xhtml", i), chapter.Bytes()})
+	}
+
+	for _, file := range files {
+		w, err := book.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(file.content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := book.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// epubPackage is the OPF document listing the chapters of the book
+func (e *Export) epubPackage(modified time.Time) []byte {
+	escape := func(s string) string {
+		var b bytes.Buffer
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+
+	var manifest, spine strings.Builder
+	for i := range e.Videos {
+		fmt.Fprintf(&manifest, "    <item id=\"video-%d\" href=\"video-%d.xhtml\" media-type=\"application/xhtml+xml\"/>\n", i, i)
+		fmt.Fprintf(&spine, "    <itemref idref=\"video-%d\"/>\n", i)
+	}
+
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">%s</dc:identifier>
+    <dc:title>%s</dc:title>
+    <dc:language>%s</dc:language>
+    <dc:publisher>Sumtube</dc:publisher>
+    <meta property="dcterms:modified">%s</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+%s  </manifest>
+  <spine>
+%s  </spine>
+</package>
+`, escape(e.Source), escape(e.Title), escape(e.Lang), modified.UTC().Format("2006-01-02T15:04:05Z"), manifest.String(), spine.String()))
+}
+
+// errNothingToExport is returned when none of the videos has a completed summary
+var errNothingToExport = errors.New("nothing to export")
+
+// buildExport loads the summaries of the videos, skipping the ones not summarized yet
+func buildExport(title, lang, source, fileName string, videoIDs []string) (*Export, error) {
+	export := &Export{Title: title, Lang: lang, Source: source, FileName: fileName}
+	for _, videoID := range videoIDs {
+		video, err := fetchExportVideo(videoID, lang)
+		if err != nil {
+			return nil, err
+		}
+		if video.Summary == "" || !strings.EqualFold(video.Status, "completed") {
+			continue
+		}
+		if video.Lang == "" {
+			video.Lang = lang
+		}
+		export.Videos = append(export.Videos, video)
+	}
+	if len(export.Videos) == 0 {
+		return nil, errNothingToExport
+	}
+	if len(export.Videos) == 1 && export.Title == "" {
+		export.Title = export.Videos[0].Title
+	}
+	return export, nil
+}
+
+// handleExport downloads summaries as Markdown (YAML front matter), standalone HTML or EPUB.
+// Example URLs:
+//
+//	GET /export/{lang}/{videoId}?format=md
+//	GET /export/{lang}/category/{category}?format=html&limit=20
+//	GET /export/{lang}/library?format=epub&tag=go&favorite=true
+func handleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/export/"), "/"), "/")
+	if len(segments) < 2 || !allowedLanguages[segments[0]] {
+		http.NotFound(w, r)
+		return
+	}
+	lang := segments[0]
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "md"
+	}
+	output, ok := exportFormats[format]
+	if !ok {
+		http.Error(w, "Invalid format, use md, html or epub", http.StatusBadRequest)
+		return
+	}
+
+	var export *Export
+	var err error
+	switch {
+	case len(segments) == 2 && segments[1] == "library":
+		var library *LibraryContent
+		library, err = GetLibrary(lang, r.URL.Query().Get("tag"), r.URL.Query().Get("favorite") == "true", r.Header.Get("Cookie"))
+		if err == errNotSignedIn {
+			http.Redirect(w, r, "/login?return_to="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+		if err == nil {
+			var videoIDs []string
+			for _, item := range library.Items {
+				if item.Lang == lang && len(videoIDs) < maxExportVideos {
+					videoIDs = append(videoIDs, item.VideoId)
+				}
+			}
+			export, err = buildExport(t(lang, "title_library"), lang, fmt.Sprintf("%s/%s/library", os.Getenv("BASE_URL"), lang), "sumtube-library-"+lang, videoIDs)
+		}
+
+	case len(segments) == 3 && segments[1] == "category" && len(segments[2]) <= 64:
+		limit := 20
+		if val, convErr := strconv.Atoi(r.URL.Query().Get("limit")); convErr == nil && val > 0 {
+			limit = min(val, maxExportVideos)
+		}
+		var videos []map[string]string
+		videos, _, err = GetVideosFromCategory(lang, segments[2], limit, "")
+		if err == nil {
+			var videoIDs []string
+			for _, video := range videos {
+				videoIDs = append(videoIDs, video["vid"])
+			}
+			export, err = buildExport(segments[2], lang, fmt.Sprintf("%s/%s/category/%s", os.Getenv("BASE_URL"), lang, segments[2]), "sumtube-"+segments[2]+"-"+lang, videoIDs)
+		}
+
+	case len(segments) == 2 && isVideoID(segments[1]):
+		export, err = buildExport("", lang, "", segments[1]+"-"+lang, []string{segments[1]})
+		if err == nil {
+			export.Source = exportVideoURL(export.Videos[0])
+		}
+
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err == errNothingToExport {
+		http.Error(w, "No completed summary to export", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	var body []byte
+	switch format {
+	case "md":
+		body = export.RenderMarkdown()
+	case "html":
+		body, err = export.RenderHTML()
+	case "epub":
+		body, err = export.RenderEPUB(time.Now())
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", output.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, unsafeFileName.ReplaceAllString(export.FileName, "-"), output.extension))
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(body)
+}
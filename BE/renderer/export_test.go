@@ -0,0 +1,158 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportTestVideo(videoID string) *MetadataSingleLanguage {
+	return &MetadataSingleLanguage{
+		Title:       "Go & \"Concurrency\"",
+		Vid:         videoID,
+		Lang:        "en",
+		Summary:     "### Key points\n- [Goroutines are cheap](00:01:05)\n- Channels don't share memory",
+		Answer:      "Use channels.",
+		Path:        "go-concurrency",
+		Status:      "completed",
+		ChannelName: "Gopher TV",
+		UploadDate:  "2025-01-02",
+		Duration:    600,
+	}
+}
+
+func stubExportVideos(t *testing.T, videos map[string]*MetadataSingleLanguage) {
+	t.Helper()
+	original := fetchExportVideo
+	fetchExportVideo = func(videoID, lang string) (*MetadataSingleLanguage, error) {
+		if video, ok := videos[videoID]; ok {
+			return video, nil
+		}
+		return &MetadataSingleLanguage{Vid: videoID, Status: "processing-pending"}, nil
+	}
+	t.Cleanup(func() { fetchExportVideo = original })
+}
+
+func TestExportMarkdown(t *testing.T) {
+	stubExportVideos(t, map[string]*MetadataSingleLanguage{"dQw4w9WgXcQ": exportTestVideo("dQw4w9WgXcQ")})
+
+	rec := httptest.NewRecorder()
+	handleExport(rec, httptest.NewRequest("GET", "/export/en/dQw4w9WgXcQ?format=md", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"---\ntitle: \"Go \\u0026 \\\"Concurrency\\\"\"\nvideo_id: \"dQw4w9WgXcQ\"\n",
+		"channel: \"Gopher TV\"\n",
+		"duration: 600\n",
+		"> Use channels.\n",
+		"[Goroutines are cheap](https://youtu.be/dQw4w9WgXcQ?t=65)",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("markdown export misses %q:\n%s", want, body)
+		}
+	}
+	if got := rec.Header().Get("Content-Disposition"); got != `attachment; filename="dQw4w9WgXcQ-en.md"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+
+	for path, want := range map[string]int{
+		"/export/en/dQw4w9WgXcQ?format=pdf": http.StatusBadRequest,
+		"/export/en/aaaaaaaaaaa":            http.StatusNotFound,
+		"/export/xx/dQw4w9WgXcQ":            http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		handleExport(rec, httptest.NewRequest("GET", path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	export := &Export{Title: "Go", Lang: "en", Videos: []*MetadataSingleLanguage{exportTestVideo("dQw4w9WgXcQ")}}
+	body, err := export.RenderHTML()
+	if err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	for _, want := range []string{"<!DOCTYPE html>", "<h1>Go &amp; &#34;Concurrency&#34;</h1>", `href="https://youtu.be/dQw4w9WgXcQ?t=65"`, "<style>"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("html export misses %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestExportEPUB(t *testing.T) {
+	export := &Export{Title: "Library", Lang: "en", Source: "https://sumtube.io/en/library", Videos: []*MetadataSingleLanguage{
+		exportTestVideo("dQw4w9WgXcQ"), exportTestVideo("9bZkp7q19f0"),
+	}}
+	book, err := export.RenderEPUB(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("RenderEPUB() error = %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(book), int64(len(book)))
+	if err != nil {
+		t.Fatalf("zip.NewReader() error = %v", err)
+	}
+	if first := archive.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Fatalf("first entry = %s (method %d), want the stored mimetype", first.Name, first.Method)
+	}
+
+	var names []string
+	for _, file := range archive.File[1:] {
+		names = append(names, file.Name)
+		reader, _ := file.Open()
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+
+		// every document of the book must be well-formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		decoder.Entity = map[string]string{}
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well-formed: %v\n%s", file.Name, err, content)
+				break
+			}
+		}
+	}
+	want := "[META-INF/container.xml OEBPS/content.opf OEBPS/nav.xhtml OEBPS/video-0.xhtml OEBPS/video-1.xhtml]"
+	if got := fmt.Sprint(names); got != want {
+		t.Errorf("entries = %s, want %s", got, want)
+	}
+}
+
+func TestGetStoredVideoContentOnlyReads(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Expected a read-only GET, got %s %s", r.Method, r.URL.Path)
+		}
+		switch r.URL.Path {
+		case "/summary/dQw4w9WgXcQ/en":
+			w.Write([]byte(`{"videoId": "dQw4w9WgXcQ", "lang": "en", "content": "Done", "status": "completed"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+	t.Setenv("SUMTUBE_API", api.URL+"/summary")
+
+	video, err := GetStoredVideoContent("dQw4w9WgXcQ", "en")
+	if err != nil || video.Summary != "Done" || video.Status != "completed" {
+		t.Fatalf("Unexpected stored video %+v, %v", video, err)
+	}
+	video, err = GetStoredVideoContent("aaaaaaaaaaa", "en")
+	if err != nil || video.Status != "" {
+		t.Errorf("Expected a video never summarized to come back without status, got %+v, %v", video, err)
+	}
+}
@@ -1,4 +1,4 @@
-import { extractVideoId } from "./utils/youtube"
+import { extractPlaylistId, extractVideoId } from "./utils/youtube"
 
 describe("extractVideoId", () => {
   test("extracts video ID from standard YouTube watch URL", () => {
@@ -65,3 +65,28 @@ describe("extractVideoId", () => {
     })
   })
 })
+
+describe("extractPlaylistId", () => {
+  test("extracts playlist ID from playlist URL", () => {
+    const url = "https://www.youtube.com/playlist?list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf"
+    expect(extractPlaylistId(url)).toBe("PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf")
+  })
+
+  test("treats watch URLs inside a playlist as single videos", () => {
+    const url =
+      "https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf"
+    expect(extractPlaylistId(url)).toBeNull()
+  })
+
+  test("returns null for URLs without a playlist", () => {
+    const urls = [
+      "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+      "https://example.com/playlist?list=PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf",
+      "not a url",
+    ]
+
+    urls.forEach((url) => {
+      expect(extractPlaylistId(url)).toBeNull()
+    })
+  })
+})
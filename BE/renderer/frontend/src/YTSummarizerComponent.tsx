@@ -1,7 +1,7 @@
-import { useState, useEffect } from "react"
+import { useState, useEffect, useRef } from "react"
 
 import "./App.css"
-import { extractVideoId } from "./utils/youtube"
+import { extractPlaylistId, extractVideoId } from "./utils/youtube"
 
 function YTSummarizerComponent() {
   const [videoUrl, setVideoUrl] = useState("")
@@ -16,6 +16,10 @@ function YTSummarizerComponent() {
   const [videoError, setVideoError] = useState<null | {
     errorMessage: string
   }>(null)
+  const eventSourceRef = useRef<EventSource | null>(null)
+
+  // Close the progress stream when the component unmounts
+  useEffect(() => () => eventSourceRef.current?.close(), [])
   // Use useEffect to handle automatic submission
   useEffect(() => {
     const root = document.getElementById("react-root")
@@ -53,7 +57,58 @@ function YTSummarizerComponent() {
       })
 
       const data = await response.json()
+      handleSummaryResponse(apiUrl, videoId, language, data)
+    } catch (error) {
+      console.error("Error fetching summary:", error)
+      setIsLoading(false)
+    }
+  }
+
+  // followSummaryEvents listens to the API progress stream instead of polling.
+  // If the stream can't be opened it falls back to polling POST /summary.
+  const followSummaryEvents = (
+    apiUrl: string,
+    videoId: string,
+    language: string
+  ) => {
+    if (typeof EventSource === "undefined") {
+      setTimeout(() => fetchSummary(apiUrl, videoId, language), 3000)
+      return
+    }
+    if (eventSourceRef.current) return
+
+    const source = new EventSource(
+      `${apiUrl}/summary/${videoId}/${language}/events`
+    )
+    eventSourceRef.current = source
+
+    source.addEventListener("status", (event) => {
+      const { status } = JSON.parse((event as MessageEvent).data)
+      setVideoInfo((info) => (info ? { ...info, status } : info))
+    })
+    source.addEventListener("result", (event) => {
+      source.close()
+      eventSourceRef.current = null
+      handleSummaryResponse(
+        apiUrl,
+        videoId,
+        language,
+        JSON.parse((event as MessageEvent).data)
+      )
+    })
+    source.onerror = () => {
+      source.close()
+      eventSourceRef.current = null
+      setTimeout(() => fetchSummary(apiUrl, videoId, language), 3000)
+    }
+  }
 
+  const handleSummaryResponse = (
+    apiUrl: string,
+    videoId: string,
+    language: string,
+    data: any
+  ) => {
       switch (data.status) {
         case "processing":
         case "processing-pending":
@@ -69,7 +124,7 @@ function YTSummarizerComponent() {
             duration: data.duration,
             status: data.status,
           })
-          setTimeout(() => fetchSummary(apiUrl, videoId, language), 3000)
+          followSummaryEvents(apiUrl, videoId, language)
           break
         case "completed":
           window.location.href = `${window.location.origin}/${language}/${data.videoId}/${data.path}`
@@ -92,25 +147,24 @@ function YTSummarizerComponent() {
           console.error("Unexpected status:", data.status)
           setIsLoading(false)
       }
-      // if (data.status === "processing") {
-      //   setVideoInfo({
-      //     videoId: data.videoId,
-      //     uploader_id: data.uploader_id,
-      //     title: data.title,
-      //     duration: data.duration,
-      //   })
-      //   setTimeout(() => fetchSummary(apiUrl, videoId, language), 3000)
-      // } else if (data.status === "completed") {
-      //   window.location.href = `${window.location.origin}/${data.lang}/${data.videoId}/${data.path}`
-      // }
-    } catch (error) {
-      console.error("Error fetching summary:", error)
-      setIsLoading(false)
-    }
   }
 
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault()
+    const root = document.getElementById("react-root")
+    const language = root?.dataset.lang || "en"
+
+    // Playlists get their own overview page, posting to it enqueues every video
+    const playlistId = extractPlaylistId(videoUrl)
+    if (playlistId) {
+      const form = document.createElement("form")
+      form.method = "POST"
+      form.action = `${window.location.origin}/${language}/playlist/${playlistId}`
+      document.body.appendChild(form)
+      form.submit()
+      return
+    }
+
     const videoId = extractVideoId(videoUrl)
     if (!videoId) return alert("Invalid YouTube URL")
 
@@ -120,8 +174,6 @@ function YTSummarizerComponent() {
 
     setIsLoading(true)
     setVideoInfo(null)
-    const root = document.getElementById("react-root")
-    const language = root?.dataset.lang || "en"
     const apiUrl = root?.dataset.apiurl || "https://api.sumtube.io"
     fetchSummary(apiUrl, videoId, language)
   }
@@ -19,3 +19,18 @@ export const extractVideoId = (url: string) => {
     return null
   }
 }
+
+// extractPlaylistId returns the list= parameter of a playlist page URL.
+// Watch URLs that also carry a list= keep being treated as a single video.
+export const extractPlaylistId = (url: string) => {
+  try {
+    const urlObj = new URL(url.trim())
+    if (!/(^|\.)youtube\.com$/.test(urlObj.hostname)) return null
+    if (urlObj.searchParams.get("v")) return null
+
+    const list = urlObj.searchParams.get("list")
+    return list && /^[a-zA-Z0-9_-]{10,}$/.test(list) ? list : null
+  } catch {
+    return null
+  }
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"io"
@@ -79,6 +80,7 @@ type MetadataSingleLanguage struct {
     Paths                 map[string]string `json:"paths,omitempty"`              
     Status             	  string `json:"status,omitempty"`            
 	ChannelId            string `json:"channel_id,omitempty"`
+	ChannelName          string `json:"channel_name,omitempty"`
 	UploadDate            string `json:"video_upload_date,omitempty"`
 	ArticleUploadDateTime string `json:"article_update_datetime,omitempty"`
 	Duration              int `json:"duration,omitempty"`
@@ -232,42 +234,77 @@ func ReplaceMarkdownTimestamps(videoID string, content string) string {
 	})
 }
 
-func GetVideosFromCategory(lang string, categoryName string, limit int) ([]map[string]string, error) {
+// apiGet and apiPost call the sumtube API with the renderer's key (SUMTUBE_API_KEY),
+// so its requests are rate limited apart from the anonymous clients sharing its IP
+func apiGet(url string) (*http.Response, error) {
+    req, err := http.NewRequest("GET", url, nil)
+    if err != nil {
+        return nil, err
+    }
+    return apiDo(req)
+}
+
+func apiPost(url, contentType string, body io.Reader) (*http.Response, error) {
+    req, err := http.NewRequest("POST", url, body)
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Content-Type", contentType)
+    return apiDo(req)
+}
+
+func apiDo(req *http.Request) (*http.Response, error) {
+    if apiKey := os.Getenv("SUMTUBE_API_KEY"); apiKey != "" {
+        req.Header.Set("X-API-Key", apiKey)
+    }
+    return http.DefaultClient.Do(req)
+}
+
+// GetVideosFromCategory returns a page of the latest videos of a category and the cursor of the next page ("" on the last one)
+func GetVideosFromCategory(lang string, categoryName string, limit int, cursor string) ([]map[string]string, string, error) {
     // Monta a URL com parâmetros
     baseURL := os.Getenv("SUMTUBE_VIDEOS_RELATED_API")
     if baseURL == "" {
-        return nil, fmt.Errorf("SUMTUBE_VIDEOS_RELATED_API is not set")
+        return nil, "", fmt.Errorf("SUMTUBE_VIDEOS_RELATED_API is not set")
     }
 
-  
-    escapedCategory := url.QueryEscape(categoryName)
-    fullURL := fmt.Sprintf("%s?category=%s&lang=%s&limit=%d", baseURL, escapedCategory, lang, limit)
+    query := url.Values{}
+    query.Set("category", categoryName)
+    query.Set("lang", lang)
+    query.Set("limit", strconv.Itoa(limit))
+    if cursor != "" {
+        query.Set("cursor", cursor)
+    }
+    fullURL := fmt.Sprintf("%s?%s", baseURL, query.Encode())
 
-    resp, err := http.Get(fullURL)
+    resp, err := apiGet(fullURL)
     if err != nil {
-        return nil, fmt.Errorf("failed to call API: %v", err)
+        return nil, "", fmt.Errorf("failed to call API: %v", err)
     }
     defer resp.Body.Close()
 
     if resp.StatusCode != http.StatusOK {
         body, _ := io.ReadAll(resp.Body)
-        return nil, fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+        return nil, "", fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
     }
 
     // Lê e decodifica o corpo da resposta
     body, err := io.ReadAll(resp.Body)
     if err != nil {
-        return nil, fmt.Errorf("failed to read response: %v", err)
+        return nil, "", fmt.Errorf("failed to read response: %v", err)
     }
 
-    var rawItems []map[string]interface{}
-    if err := json.Unmarshal(body, &rawItems); err != nil {
-        return nil, fmt.Errorf("failed to parse response JSON: %v", err)
+    var page struct {
+        Videos     []map[string]interface{} `json:"videos"`
+        NextCursor string                   `json:"next_cursor"`
+    }
+    if err := json.Unmarshal(body, &page); err != nil {
+        return nil, "", fmt.Errorf("failed to parse response JSON: %v", err)
     }
 
     // Extrai somente vid e title
     var videos []map[string]string
-    for _, item := range rawItems {
+    for _, item := range page.Videos {
         vid, _ := item["videoId"].(string)
         title, _ := item["title"].(string)
         path, _ := item["path"].(string)
@@ -282,12 +319,18 @@ func GetVideosFromCategory(lang string, categoryName string, limit int) ([]map[s
         }
     }
 
-    return videos, nil
+    return videos, page.NextCursor, nil
 }
 
     
 // GetVideoContent fetches content from the API for a given video ID and language
 func GetVideoContent(videoID, lang string) (*MetadataSingleLanguage, error) {
+    return GetVideoContentForVisitor(videoID, lang, "")
+}
+
+// GetVideoContentForVisitor forwards the visitor cookies to the API, so the summary
+// lands in the library of signed in visitors
+func GetVideoContentForVisitor(videoID, lang, cookie string) (*MetadataSingleLanguage, error) {
     // Prepare the payload
     payload := map[string]string{
         "videoId":  videoID,
@@ -300,7 +343,15 @@ func GetVideoContent(videoID, lang string) (*MetadataSingleLanguage, error) {
     }
 
     // Call the API
-    resp, err := http.Post(os.Getenv("SUMTUBE_API"), "application/json", bytes.NewBuffer(payloadBytes))
+    req, err := http.NewRequest("POST", os.Getenv("SUMTUBE_API"), bytes.NewBuffer(payloadBytes))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create request: %v", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    if cookie != "" {
+        req.Header.Set("Cookie", cookie)
+    }
+    resp, err := apiDo(req)
     if err != nil {
         return nil, fmt.Errorf("failed to call API: %v", err)
     }
@@ -320,6 +371,281 @@ func GetVideoContent(videoID, lang string) (*MetadataSingleLanguage, error) {
 }
 
 
+// GetStoredVideoContent reads the summary of a video as the API has it, without enqueueing it.
+// A video never summarized comes back without status.
+func GetStoredVideoContent(videoID, lang string) (*MetadataSingleLanguage, error) {
+    apiURL := strings.TrimSuffix(os.Getenv("SUMTUBE_API"), "/")
+    resp, err := apiGet(fmt.Sprintf("%s/%s/%s", apiURL, url.PathEscape(videoID), url.PathEscape(lang)))
+    if err != nil {
+        return nil, fmt.Errorf("failed to call API: %v", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode == http.StatusNotFound {
+        return &MetadataSingleLanguage{Vid: videoID, Lang: lang}, nil
+    }
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read API response: %v", err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+    }
+
+    var result MetadataSingleLanguage
+    if err := json.Unmarshal(body, &result); err != nil {
+        return nil, fmt.Errorf("failed to parse API response: %v", err)
+    }
+    return &result, nil
+}
+
+
+type PlaylistItem struct {
+    VideoID  string                  `json:"videoId"`
+    Language string                  `json:"language"`
+    State    string                  `json:"state"`
+    Result   *MetadataSingleLanguage `json:"result,omitempty"`
+}
+
+type PlaylistSingleLanguage struct {
+    PlaylistID string         `json:"playlistId"`
+    Title      string         `json:"title"`
+    Total      int            `json:"total"`
+    Completed  int            `json:"completed"`
+    Failed     int            `json:"failed"`
+    Processing int            `json:"processing"`
+    Done       bool           `json:"done"`
+    Items      []PlaylistItem `json:"items"`
+}
+
+var errPlaylistNotFound = errors.New("playlist not submitted yet")
+
+// GetPlaylistContent returns the state of each video of a playlist that was already
+// submitted, errPlaylistNotFound otherwise. Reading a playlist never enqueues anything.
+func GetPlaylistContent(playlistID, lang string) (*PlaylistSingleLanguage, error) {
+    apiURL := os.Getenv("SUMTUBE_PLAYLIST_API")
+    if apiURL == "" {
+        return nil, fmt.Errorf("SUMTUBE_PLAYLIST_API is not set")
+    }
+
+    query := url.Values{}
+    query.Set("lang", lang)
+    resp, err := apiGet(fmt.Sprintf("%s/%s?%s", strings.TrimSuffix(apiURL, "/"), url.PathEscape(playlistID), query.Encode()))
+    if err != nil {
+        return nil, fmt.Errorf("failed to call API: %v", err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode == http.StatusNotFound {
+        return nil, errPlaylistNotFound
+    }
+    return decodePlaylistResponse(resp)
+}
+
+// SubmitPlaylist submits a playlist to the API, which enqueues its videos the first time
+func SubmitPlaylist(playlistID, lang string) (*PlaylistSingleLanguage, error) {
+    apiURL := os.Getenv("SUMTUBE_PLAYLIST_API")
+    if apiURL == "" {
+        return nil, fmt.Errorf("SUMTUBE_PLAYLIST_API is not set")
+    }
+
+    payloadBytes, err := json.Marshal(map[string]string{
+        "playlist": playlistID,
+        "language": lang,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to encode payload: %v", err)
+    }
+
+    resp, err := apiPost(apiURL, "application/json", bytes.NewBuffer(payloadBytes))
+    if err != nil {
+        return nil, fmt.Errorf("failed to call API: %v", err)
+    }
+    defer resp.Body.Close()
+
+    return decodePlaylistResponse(resp)
+}
+
+func decodePlaylistResponse(resp *http.Response) (*PlaylistSingleLanguage, error) {
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read API response: %v", err)
+    }
+
+    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+        return nil, fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+    }
+
+    var result PlaylistSingleLanguage
+    if err := json.Unmarshal(body, &result); err != nil {
+        return nil, fmt.Errorf("failed to parse API response: %v", err)
+    }
+
+    return &result, nil
+}
+
+
+type ChannelSingleLanguage struct {
+    ChannelID   string                   `json:"channel_id"`
+    ChannelName string                   `json:"channel_name"`
+    VideoCount  int                      `json:"video_count"`
+    Videos      []MetadataSingleLanguage `json:"videos"`
+    NextCursor  string                   `json:"next_cursor"`
+}
+
+// GetChannelContent fetches one page of the summarized videos of a channel
+func GetChannelContent(channelID, lang, cursor string) (*ChannelSingleLanguage, error) {
+    baseURL := os.Getenv("SUMTUBE_CHANNEL_API")
+    if baseURL == "" {
+        return nil, fmt.Errorf("SUMTUBE_CHANNEL_API is not set")
+    }
+
+    query := url.Values{}
+    query.Set("lang", lang)
+    if cursor != "" {
+        query.Set("cursor", cursor)
+    }
+    fullURL := fmt.Sprintf("%s/%s?%s", strings.TrimSuffix(baseURL, "/"), url.PathEscape(channelID), query.Encode())
+
+    resp, err := apiGet(fullURL)
+    if err != nil {
+        return nil, fmt.Errorf("failed to call API: %v", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read API response: %v", err)
+    }
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+    }
+
+    var result ChannelSingleLanguage
+    if err := json.Unmarshal(body, &result); err != nil {
+        return nil, fmt.Errorf("failed to parse API response: %v", err)
+    }
+
+    return &result, nil
+}
+
+type SearchResultsSingleLanguage struct {
+    Query   string `json:"query"`
+    Results []struct {
+        VideoID string `json:"videoId"`
+        Lang    string `json:"lang"`
+        Title   string `json:"title"`
+        Path    string `json:"path"`
+        Answer  string `json:"answer"`
+    } `json:"results"`
+}
+
+// GetSearchResults runs a full-text search over the published summaries of a language
+func GetSearchResults(lang, query string) (*SearchResultsSingleLanguage, error) {
+    baseURL := os.Getenv("SUMTUBE_SEARCH_API")
+    if baseURL == "" {
+        return nil, fmt.Errorf("SUMTUBE_SEARCH_API is not set")
+    }
+
+    params := url.Values{}
+    params.Set("lang", lang)
+    params.Set("q", query)
+    fullURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+
+    resp, err := apiGet(fullURL)
+    if err != nil {
+        return nil, fmt.Errorf("failed to call API: %v", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read API response: %v", err)
+    }
+
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+    }
+
+    var result SearchResultsSingleLanguage
+    if err := json.Unmarshal(body, &result); err != nil {
+        return nil, fmt.Errorf("failed to parse API response: %v", err)
+    }
+
+    return &result, nil
+}
+
+type LibraryItem struct {
+    VideoId     string   `json:"videoId"`
+    Lang        string   `json:"lang"`
+    Saved       bool     `json:"saved"`
+    Favorite    bool     `json:"favorite"`
+    Notes       string   `json:"notes"`
+    Tags        []string `json:"tags"`
+    Title       string   `json:"title"`
+    Path        string   `json:"path"`
+    Answer      string   `json:"answer"`
+    ChannelName string   `json:"channel_name"`
+}
+
+type LibraryContent struct {
+    Items []LibraryItem `json:"items"`
+    Total int           `json:"total"`
+    Tags  []string      `json:"tags"`
+}
+
+// errNotSignedIn is returned by GetLibrary when the visitor has no session
+var errNotSignedIn = fmt.Errorf("not signed in")
+
+// GetLibrary fetches the library of the visitor owning the session cookie
+func GetLibrary(lang, tag string, favorite bool, cookie string) (*LibraryContent, error) {
+    baseURL := os.Getenv("SUMTUBE_ACCOUNT_API")
+    if baseURL == "" {
+        return nil, fmt.Errorf("SUMTUBE_ACCOUNT_API is not set")
+    }
+
+    query := url.Values{}
+    query.Set("lang", lang)
+    if tag != "" {
+        query.Set("tag", tag)
+    }
+    if favorite {
+        query.Set("favorite", "true")
+    }
+    req, err := http.NewRequest("GET", fmt.Sprintf("%s/library?%s", strings.TrimSuffix(baseURL, "/"), query.Encode()), nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create request: %v", err)
+    }
+    if cookie != "" {
+        req.Header.Set("Cookie", cookie)
+    }
+
+    resp, err := apiDo(req)
+    if err != nil {
+        return nil, fmt.Errorf("failed to call API: %v", err)
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read API response: %v", err)
+    }
+
+    if resp.StatusCode == http.StatusUnauthorized {
+        return nil, errNotSignedIn
+    }
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("API returned non-200 status: %d - %s", resp.StatusCode, string(body))
+    }
+
+    var result LibraryContent
+    if err := json.Unmarshal(body, &result); err != nil {
+        return nil, fmt.Errorf("failed to parse API response: %v", err)
+    }
+
+    return &result, nil
+}
 
 
 // ConvertMarkdownToHTML converts a markdown string to HTML
@@ -450,10 +776,25 @@ const (
 	REDIRECT_HOME
 	REDIRECT_BLOG_RETURN_HOME
 	HOME
+	PLAYLIST_TEMPLATE
+	CHANNEL_TEMPLATE
+	CATEGORY_TEMPLATE
+	SEARCH_TEMPLATE
+	LIBRARY_TEMPLATE
 )
 
 func (rt RouteType) String() string {
-	return [...]string{"UNKNOWN", "BLOG_TEMPLATE", "REDIRECT_HOME", "REDIRECT_BLOG_HOME", "HOME"}[rt]
+	return [...]string{"UNKNOWN", "BLOG_TEMPLATE", "REDIRECT_HOME", "REDIRECT_BLOG_HOME", "HOME", "PLAYLIST_TEMPLATE", "CHANNEL_TEMPLATE", "CATEGORY_TEMPLATE", "SEARCH_TEMPLATE", "LIBRARY_TEMPLATE"}[rt]
+}
+
+func isChannelID(s string) bool {
+    var channelIDPattern = regexp.MustCompile(`^UC[a-zA-Z0-9_-]{22}$`)
+	return channelIDPattern.MatchString(s)
+}
+
+func isPlaylistID(s string) bool {
+    var playlistIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{10,64}$`)
+	return playlistIDPattern.MatchString(s)
 }
 
 func isVideoID(s string) bool {
@@ -516,6 +857,15 @@ func GetRouteType(segments []string) RouteType {
     
     if n == 3 {
         first, second, third := segments[0], segments[1], segments[2]
+		if allowedLanguages[first] && second == "playlist" && isPlaylistID(third) {
+			return PLAYLIST_TEMPLATE
+		}
+		if allowedLanguages[first] && second == "channel" && isChannelID(third) {
+			return CHANNEL_TEMPLATE
+		}
+		if allowedLanguages[first] && second == "category" && len(third) <= 64 {
+			return CATEGORY_TEMPLATE
+		}
 		if allowedLanguages[first] {
 			if isVideoID(second) && isLikelyTitle(third) {
                 // println("4 isVideoID(second) && isLikelyTitle(third)", isVideoID(second), isLikelyTitle(third))
@@ -527,6 +877,12 @@ func GetRouteType(segments []string) RouteType {
 
     if n == 2 {
         first, second := segments[0], segments[1]
+		if allowedLanguages[first] && second == "search" {
+			return SEARCH_TEMPLATE
+		}
+		if allowedLanguages[first] && second == "library" {
+			return LIBRARY_TEMPLATE
+		}
 		if allowedLanguages[first] && isVideoID(second) {
            // println("3 allowedLanguages[first] && isVideoID(second)", allowedLanguages[first], isVideoID(second))
            // println("return REDIRECT_BLOG_RETURN_HOME")
@@ -601,6 +957,21 @@ func router(w http.ResponseWriter, r *http.Request) {
             //setLanguageCookie(w, lang)
             loadBlog(w, r, lang, title, videoId)
         
+        case PLAYLIST_TEMPLATE:
+            loadPlaylist(w, r, lang, pathSegments[2])
+
+        case CHANNEL_TEMPLATE:
+            loadChannel(w, r, lang, pathSegments[2])
+
+        case CATEGORY_TEMPLATE:
+            loadCategory(w, r, lang, pathSegments[2])
+
+        case SEARCH_TEMPLATE:
+            loadSearch(w, r, lang)
+
+        case LIBRARY_TEMPLATE:
+            loadLibrary(w, r, lang)
+
         case REDIRECT_BLOG_RETURN_HOME:
             println("case REDIRECT_BLOG_RETURN_HOME")
             result, _ := GetVideoContent(videoId, lang)
@@ -781,6 +1152,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "Video Summary",
                 "you_saved":"You saved",
                 "reading": "reading",
+                "title_playlist": "Playlist Summary",
+                "playlist_progress": "summaries ready",
+                "playlist_pending": "Summary in progress...",
+                "read_full_summary": "Read full summary",
+                "title_channel": "Channel Summaries",
+                "channel_videos": "summarized videos",
+                "next_page": "Next page",
+                "title_category": "Category",
+                "see_all": "See all",
+                "title_search": "Search",
+                "search_placeholder": "Search summaries...",
+                "search_no_results": "No summaries found",
+                "title_library": "My library",
+                "library_empty": "Your library is empty, the videos you summarize will show up here",
+                "library_favorites": "Favorites",
+                "library_all": "All",
+                "library_remove": "Remove",
+                "library_notes": "Notes",
+                "library_tags": "Tags (comma separated)",
+                "library_save": "Save",
+                "ask_title": "Ask this video",
+                "ask_placeholder": "Ask a question about the video...",
+                "ask_button": "Ask",
+                "ask_error": "The answer could not be loaded, try again later",
+                "tab_summary": "Summary",
+                "tab_transcript": "Transcript",
+                "transcript_download": "Download",
+                "transcript_unavailable": "The transcript of this video is not available",
+                "export": "Export",
+                "playlist_not_submitted": "This playlist has not been summarized yet.",
+                "playlist_submit": "Summarize playlist",
             },
             "pt": {
                 "title": "Resumir Vídeos do YouTube Grátis com IA | Sumtube.io",
@@ -794,6 +1196,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "Resumo do vídeo",
                 "you_saved":"Você economizou",
                 "reading": "leitura",
+                "title_playlist": "Resumo da playlist",
+                "playlist_progress": "resumos prontos",
+                "playlist_pending": "Resumo em andamento...",
+                "read_full_summary": "Ler resumo completo",
+                "title_channel": "Resumos do canal",
+                "channel_videos": "vídeos resumidos",
+                "next_page": "Próxima página",
+                "title_category": "Categoria",
+                "see_all": "Ver todos",
+                "title_search": "Buscar",
+                "search_placeholder": "Buscar resumos...",
+                "search_no_results": "Nenhum resumo encontrado",
+                "title_library": "Minha biblioteca",
+                "library_empty": "Sua biblioteca está vazia, os vídeos que você resumir aparecerão aqui",
+                "library_favorites": "Favoritos",
+                "library_all": "Todos",
+                "library_remove": "Remover",
+                "library_notes": "Notas",
+                "library_tags": "Tags (separadas por vírgula)",
+                "library_save": "Salvar",
+                "ask_title": "Pergunte ao vídeo",
+                "ask_placeholder": "Faça uma pergunta sobre o vídeo...",
+                "ask_button": "Perguntar",
+                "ask_error": "Não foi possível carregar a resposta, tente novamente mais tarde",
+                "tab_summary": "Resumo",
+                "tab_transcript": "Transcrição",
+                "transcript_download": "Baixar",
+                "transcript_unavailable": "A transcrição deste vídeo não está disponível",
+                "export": "Exportar",
+                "playlist_not_submitted": "Esta playlist ainda não foi resumida.",
+                "playlist_submit": "Resumir playlist",
             },
             "es": {
                 "title": "Resumidor de videos de YouTube",
@@ -807,6 +1240,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "Resumen del vídeo",
                 "you_saved":"Ahorraste",
                 "reading": "lectura",
+                "title_playlist": "Resumen de la lista",
+                "playlist_progress": "resúmenes listos",
+                "playlist_pending": "Resumen en curso...",
+                "read_full_summary": "Leer resumen completo",
+                "title_channel": "Resúmenes del canal",
+                "channel_videos": "videos resumidos",
+                "next_page": "Página siguiente",
+                "title_category": "Categoría",
+                "see_all": "Ver todos",
+                "title_search": "Buscar",
+                "search_placeholder": "Buscar resúmenes...",
+                "search_no_results": "No se encontraron resúmenes",
+                "title_library": "Mi biblioteca",
+                "library_empty": "Tu biblioteca está vacía, los videos que resumas aparecerán aquí",
+                "library_favorites": "Favoritos",
+                "library_all": "Todos",
+                "library_remove": "Eliminar",
+                "library_notes": "Notas",
+                "library_tags": "Etiquetas (separadas por comas)",
+                "library_save": "Guardar",
+                "ask_title": "Pregúntale al video",
+                "ask_placeholder": "Haz una pregunta sobre el video...",
+                "ask_button": "Preguntar",
+                "ask_error": "No se pudo cargar la respuesta, inténtalo más tarde",
+                "tab_summary": "Resumen",
+                "tab_transcript": "Transcripción",
+                "transcript_download": "Descargar",
+                "transcript_unavailable": "La transcripción de este video no está disponible",
+                "export": "Exportar",
+                "playlist_not_submitted": "Esta lista de reproducción aún no se ha resumido.",
+                "playlist_submit": "Resumir lista",
             },
             "it": {
                 "title": "Riassumere Video YouTube Gratis con IA | Sumtube.io",
@@ -820,6 +1284,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "Riassunto del video",
                 "you_saved":"Hai risparmiato",
                 "reading": "lettura",
+                "title_playlist": "Riassunto della playlist",
+                "playlist_progress": "riassunti pronti",
+                "playlist_pending": "Riassunto in corso...",
+                "read_full_summary": "Leggi il riassunto completo",
+                "title_channel": "Riassunti del canale",
+                "channel_videos": "video riassunti",
+                "next_page": "Pagina successiva",
+                "title_category": "Categoria",
+                "see_all": "Vedi tutti",
+                "title_search": "Cerca",
+                "search_placeholder": "Cerca riassunti...",
+                "search_no_results": "Nessun riassunto trovato",
+                "title_library": "La mia libreria",
+                "library_empty": "La tua libreria è vuota, i video che riassumi appariranno qui",
+                "library_favorites": "Preferiti",
+                "library_all": "Tutti",
+                "library_remove": "Rimuovi",
+                "library_notes": "Note",
+                "library_tags": "Tag (separati da virgola)",
+                "library_save": "Salva",
+                "ask_title": "Chiedi al video",
+                "ask_placeholder": "Fai una domanda sul video...",
+                "ask_button": "Chiedi",
+                "ask_error": "Impossibile caricare la risposta, riprova più tardi",
+                "tab_summary": "Riassunto",
+                "tab_transcript": "Trascrizione",
+                "transcript_download": "Scarica",
+                "transcript_unavailable": "La trascrizione di questo video non è disponibile",
+                "export": "Esporta",
+                "playlist_not_submitted": "Questa playlist non è ancora stata riassunta.",
+                "playlist_submit": "Riassumi playlist",
             },
             
             "fr": {
@@ -834,6 +1329,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "Résumé de la vidéo",
                 "you_saved":"Vous avez économisé",
                 "reading": "lecture",
+                "title_playlist": "Résumé de la playlist",
+                "playlist_progress": "résumés prêts",
+                "playlist_pending": "Résumé en cours...",
+                "read_full_summary": "Lire le résumé complet",
+                "title_channel": "Résumés de la chaîne",
+                "channel_videos": "vidéos résumées",
+                "next_page": "Page suivante",
+                "title_category": "Catégorie",
+                "see_all": "Tout voir",
+                "title_search": "Rechercher",
+                "search_placeholder": "Rechercher des résumés...",
+                "search_no_results": "Aucun résumé trouvé",
+                "title_library": "Ma bibliothèque",
+                "library_empty": "Votre bibliothèque est vide, les vidéos que vous résumez apparaîtront ici",
+                "library_favorites": "Favoris",
+                "library_all": "Tous",
+                "library_remove": "Retirer",
+                "library_notes": "Notes",
+                "library_tags": "Tags (séparés par des virgules)",
+                "library_save": "Enregistrer",
+                "ask_title": "Interrogez la vidéo",
+                "ask_placeholder": "Posez une question sur la vidéo...",
+                "ask_button": "Demander",
+                "ask_error": "La réponse n'a pas pu être chargée, réessayez plus tard",
+                "tab_summary": "Résumé",
+                "tab_transcript": "Transcription",
+                "transcript_download": "Télécharger",
+                "transcript_unavailable": "La transcription de cette vidéo n'est pas disponible",
+                "export": "Exporter",
+                "playlist_not_submitted": "Cette playlist n'a pas encore été résumée.",
+                "playlist_submit": "Résumer la playlist",
 
             },
             "ar": {
@@ -848,6 +1374,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "ملخص الفيديو",
                 "you_saved": "لقد وفرت",
                 "reading": "قراءة",
+                "title_playlist": "ملخص قائمة التشغيل",
+                "playlist_progress": "ملخصات جاهزة",
+                "playlist_pending": "جارٍ إعداد الملخص...",
+                "read_full_summary": "اقرأ الملخص الكامل",
+                "title_channel": "ملخصات القناة",
+                "channel_videos": "مقاطع ملخصة",
+                "next_page": "الصفحة التالية",
+                "title_category": "الفئة",
+                "see_all": "عرض الكل",
+                "title_search": "بحث",
+                "search_placeholder": "ابحث في الملخصات...",
+                "search_no_results": "لم يتم العثور على ملخصات",
+                "title_library": "مكتبتي",
+                "library_empty": "مكتبتك فارغة، ستظهر هنا الفيديوهات التي تلخصها",
+                "library_favorites": "المفضلة",
+                "library_all": "الكل",
+                "library_remove": "إزالة",
+                "library_notes": "ملاحظات",
+                "library_tags": "وسوم (مفصولة بفواصل)",
+                "library_save": "حفظ",
+                "ask_title": "اسأل الفيديو",
+                "ask_placeholder": "اطرح سؤالاً عن الفيديو...",
+                "ask_button": "اسأل",
+                "ask_error": "تعذر تحميل الإجابة، حاول مرة أخرى لاحقًا",
+                "tab_summary": "الملخص",
+                "tab_transcript": "النص",
+                "transcript_download": "تنزيل",
+                "transcript_unavailable": "نص هذا الفيديو غير متاح",
+                "export": "تصدير",
+                "playlist_not_submitted": "لم يتم تلخيص قائمة التشغيل هذه بعد.",
+                "playlist_submit": "تلخيص قائمة التشغيل",
             },
             "ru": {
                 "title": "Краткие резюме видео на YouTube бесплатно с ИИ | Sumtube.io",
@@ -861,6 +1418,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "Резюме видео",
                 "you_saved": "Вы сэкономили",
                 "reading": "чтение",
+                "title_playlist": "Резюме плейлиста",
+                "playlist_progress": "резюме готово",
+                "playlist_pending": "Резюме готовится...",
+                "read_full_summary": "Читать полное резюме",
+                "title_channel": "Резюме канала",
+                "channel_videos": "видео с резюме",
+                "next_page": "Следующая страница",
+                "title_category": "Категория",
+                "see_all": "Смотреть все",
+                "title_search": "Поиск",
+                "search_placeholder": "Искать краткие обзоры...",
+                "search_no_results": "Ничего не найдено",
+                "title_library": "Моя библиотека",
+                "library_empty": "Ваша библиотека пуста, здесь появятся видео, которые вы кратко изложите",
+                "library_favorites": "Избранное",
+                "library_all": "Все",
+                "library_remove": "Удалить",
+                "library_notes": "Заметки",
+                "library_tags": "Теги (через запятую)",
+                "library_save": "Сохранить",
+                "ask_title": "Спросите видео",
+                "ask_placeholder": "Задайте вопрос о видео...",
+                "ask_button": "Спросить",
+                "ask_error": "Не удалось загрузить ответ, попробуйте позже",
+                "tab_summary": "Краткое содержание",
+                "tab_transcript": "Расшифровка",
+                "transcript_download": "Скачать",
+                "transcript_unavailable": "Расшифровка этого видео недоступна",
+                "export": "Экспорт",
+                "playlist_not_submitted": "Этот плейлист ещё не был обработан.",
+                "playlist_submit": "Сделать краткое содержание",
             },
             "ja": {
                 "title": "YouTube動画をAIで無料要約 | Sumtube.io",
@@ -874,6 +1462,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "動画要約",
                 "you_saved": "節約できた時間",
                 "reading": "読書",
+                "title_playlist": "プレイリスト要約",
+                "playlist_progress": "件の要約が完了",
+                "playlist_pending": "要約を作成中...",
+                "read_full_summary": "要約全文を読む",
+                "title_channel": "チャンネルの要約",
+                "channel_videos": "本の要約済み動画",
+                "next_page": "次のページ",
+                "title_category": "カテゴリー",
+                "see_all": "すべて見る",
+                "title_search": "検索",
+                "search_placeholder": "要約を検索...",
+                "search_no_results": "要約が見つかりません",
+                "title_library": "マイライブラリ",
+                "library_empty": "ライブラリは空です。要約した動画がここに表示されます",
+                "library_favorites": "お気に入り",
+                "library_all": "すべて",
+                "library_remove": "削除",
+                "library_notes": "メモ",
+                "library_tags": "タグ（カンマ区切り）",
+                "library_save": "保存",
+                "ask_title": "動画に質問する",
+                "ask_placeholder": "動画について質問してください...",
+                "ask_button": "質問する",
+                "ask_error": "回答を読み込めませんでした。しばらくしてから再度お試しください",
+                "tab_summary": "要約",
+                "tab_transcript": "文字起こし",
+                "transcript_download": "ダウンロード",
+                "transcript_unavailable": "この動画の文字起こしは利用できません",
+                "export": "エクスポート",
+                "playlist_not_submitted": "このプレイリストはまだ要約されていません。",
+                "playlist_submit": "プレイリストを要約",
             },
             "de": {
                 "title": "YouTube-Videos kostenlos mit KI zusammenfassen | Sumtube.io",
@@ -887,6 +1506,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "Video-Zusammenfassung",
                 "you_saved": "Sie haben gespart",
                 "reading": "Lesen",
+                "title_playlist": "Playlist-Zusammenfassung",
+                "playlist_progress": "Zusammenfassungen fertig",
+                "playlist_pending": "Zusammenfassung wird erstellt...",
+                "read_full_summary": "Ganze Zusammenfassung lesen",
+                "title_channel": "Kanal-Zusammenfassungen",
+                "channel_videos": "zusammengefasste Videos",
+                "next_page": "Nächste Seite",
+                "title_category": "Kategorie",
+                "see_all": "Alle ansehen",
+                "title_search": "Suche",
+                "search_placeholder": "Zusammenfassungen durchsuchen...",
+                "search_no_results": "Keine Zusammenfassungen gefunden",
+                "title_library": "Meine Bibliothek",
+                "library_empty": "Deine Bibliothek ist leer, die Videos, die du zusammenfasst, erscheinen hier",
+                "library_favorites": "Favoriten",
+                "library_all": "Alle",
+                "library_remove": "Entfernen",
+                "library_notes": "Notizen",
+                "library_tags": "Tags (durch Kommas getrennt)",
+                "library_save": "Speichern",
+                "ask_title": "Frag das Video",
+                "ask_placeholder": "Stelle eine Frage zum Video...",
+                "ask_button": "Fragen",
+                "ask_error": "Die Antwort konnte nicht geladen werden, versuche es später erneut",
+                "tab_summary": "Zusammenfassung",
+                "tab_transcript": "Transkript",
+                "transcript_download": "Herunterladen",
+                "transcript_unavailable": "Das Transkript dieses Videos ist nicht verfügbar",
+                "export": "Exportieren",
+                "playlist_not_submitted": "Diese Playlist wurde noch nicht zusammengefasst.",
+                "playlist_submit": "Playlist zusammenfassen",
             },
             "zh": {
                 "title": "使用 AI 免费总结 YouTube 视频 | Sumtube.io",
@@ -900,6 +1550,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "视频摘要",
                 "you_saved": "您节省了",
                 "reading": "阅读",
+                "title_playlist": "播放列表摘要",
+                "playlist_progress": "个摘要已完成",
+                "playlist_pending": "正在生成摘要...",
+                "read_full_summary": "阅读完整摘要",
+                "title_channel": "频道摘要",
+                "channel_videos": "个已总结的视频",
+                "next_page": "下一页",
+                "title_category": "分类",
+                "see_all": "查看全部",
+                "title_search": "搜索",
+                "search_placeholder": "搜索摘要...",
+                "search_no_results": "未找到摘要",
+                "title_library": "我的资料库",
+                "library_empty": "你的资料库是空的，你总结的视频会显示在这里",
+                "library_favorites": "收藏",
+                "library_all": "全部",
+                "library_remove": "移除",
+                "library_notes": "笔记",
+                "library_tags": "标签（用逗号分隔）",
+                "library_save": "保存",
+                "ask_title": "向视频提问",
+                "ask_placeholder": "就视频提出一个问题...",
+                "ask_button": "提问",
+                "ask_error": "无法加载回答，请稍后再试",
+                "tab_summary": "摘要",
+                "tab_transcript": "字幕文本",
+                "transcript_download": "下载",
+                "transcript_unavailable": "该视频的字幕文本不可用",
+                "export": "导出",
+                "playlist_not_submitted": "此播放列表尚未生成摘要。",
+                "playlist_submit": "生成播放列表摘要",
             },
             
             "ko": {
@@ -914,6 +1595,37 @@ func loadIndex(w http.ResponseWriter, r *http.Request, lang string, video ...str
                 "title_blog": "동영상 요약",
                 "you_saved": "절약한 시간",
                 "reading": "읽기",
+                "title_playlist": "재생목록 요약",
+                "playlist_progress": "개 요약 완료",
+                "playlist_pending": "요약 생성 중...",
+                "read_full_summary": "전체 요약 읽기",
+                "title_channel": "채널 요약",
+                "channel_videos": "개의 요약된 동영상",
+                "next_page": "다음 페이지",
+                "title_category": "카테고리",
+                "see_all": "모두 보기",
+                "title_search": "검색",
+                "search_placeholder": "요약 검색...",
+                "search_no_results": "요약을 찾을 수 없습니다",
+                "title_library": "내 라이브러리",
+                "library_empty": "라이브러리가 비어 있습니다. 요약한 동영상이 여기에 표시됩니다",
+                "library_favorites": "즐겨찾기",
+                "library_all": "전체",
+                "library_remove": "삭제",
+                "library_notes": "메모",
+                "library_tags": "태그 (쉼표로 구분)",
+                "library_save": "저장",
+                "ask_title": "동영상에 질문하기",
+                "ask_placeholder": "동영상에 대해 질문해 보세요...",
+                "ask_button": "질문하기",
+                "ask_error": "답변을 불러올 수 없습니다. 나중에 다시 시도하세요",
+                "tab_summary": "요약",
+                "tab_transcript": "스크립트",
+                "transcript_download": "다운로드",
+                "transcript_unavailable": "이 동영상의 스크립트를 사용할 수 없습니다",
+                "export": "내보내기",
+                "playlist_not_submitted": "이 재생목록은 아직 요약되지 않았습니다.",
+                "playlist_submit": "재생목록 요약하기",
             },                  
             
         }
@@ -944,13 +1656,13 @@ func loadBlog(w http.ResponseWriter, r *http.Request, lang, title, videoId strin
         return
     }
 
-    result, err := GetVideoContent(videoId, lang)
+    result, err := GetVideoContentForVisitor(videoId, lang, r.Header.Get("Cookie"))
     if err != nil {
         http.Error(w, err.Error(), http.StatusInternalServerError)
         return
     }
 
-    relatedVideos, err := GetVideosFromCategory(lang, result.Category, 10)
+    relatedVideos, _, err := GetVideosFromCategory(lang, result.Category, 10, "")
     log.Println("GetVideoFromCategory len", len(relatedVideos))
 
     if err != nil {
@@ -959,12 +1671,7 @@ func loadBlog(w http.ResponseWriter, r *http.Request, lang, title, videoId strin
     }
 
     // Extração de dados da resposta
-    content := result.Summary
-    content = strings.ReplaceAll(content, "\\n", "\n")  // fix line breaker
-    content = strings.ReplaceAll(content, "\\(", "(")
-    content = strings.ReplaceAll(content, "\\)", ")")
-    content = strings.ReplaceAll(content, "\\[", "[")
-    content = strings.ReplaceAll(content, "\\]", "]")
+    content := cleanSummaryMarkdown(result.Summary)
 
     println("content : ",content)
 
@@ -1013,6 +1720,8 @@ func loadBlog(w http.ResponseWriter, r *http.Request, lang, title, videoId strin
         VideoId              string
         Title                string
         UploadId             string
+        ChannelName          string
+        Category             string
         UploadDate           string
         Duration             string
         RelatedVideosArr     []map[string]string
@@ -1032,6 +1741,8 @@ func loadBlog(w http.ResponseWriter, r *http.Request, lang, title, videoId strin
         VideoId:              videoId,
         Title:                contentTitle,
         UploadId:             uploaderId,
+        ChannelName:          result.ChannelName,
+        Category:             result.Category,
         UploadDate:           uploadDate,
         Duration:             durationStr,
         VideoDurationMinutes: durationInt,
@@ -1053,6 +1764,341 @@ func loadBlog(w http.ResponseWriter, r *http.Request, lang, title, videoId strin
     }
 }
 
+// cleanSummaryMarkdown undoes the escaping the LLM adds to the markdown summary
+func cleanSummaryMarkdown(content string) string {
+    content = strings.ReplaceAll(content, "\\n", "\n")  // fix line breaker
+    content = strings.ReplaceAll(content, "\\(", "(")
+    content = strings.ReplaceAll(content, "\\)", ")")
+    content = strings.ReplaceAll(content, "\\[", "[")
+    content = strings.ReplaceAll(content, "\\]", "]")
+    return content
+}
+
+// loadPlaylist handles the playlist overview page, stitching the summary of every video together.
+// Only a POST (the submit button of the page) enqueues the videos of a new playlist, a GET
+// renders the playlist as it is so crawlers and shared links never start summaries.
+// Example URL: /en/playlist/PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf
+func loadPlaylist(w http.ResponseWriter, r *http.Request, lang, playlistId string) {
+    if r.Method == http.MethodPost {
+        if _, err := SubmitPlaylist(playlistId, lang); err != nil {
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+        http.Redirect(w, r, r.URL.Path, http.StatusSeeOther)
+        return
+    }
+
+    tmpl, err := template.ParseFS(templateFS, filepath.Join("templates", "playlist.html"))
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error loading template: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    result, err := GetPlaylistContent(playlistId, lang)
+    notSubmitted := errors.Is(err, errPlaylistNotFound)
+    if err != nil && !notSubmitted {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    if notSubmitted {
+        result = &PlaylistSingleLanguage{PlaylistID: playlistId}
+    }
+
+    type playlistVideo struct {
+        VideoId string
+        Title   string
+        Path    string
+        Ready   bool
+        Failed  bool
+        Answer  template.HTML
+        Content template.HTML
+    }
+
+    videos := make([]playlistVideo, 0, len(result.Items))
+    totalMinutes := 0
+    for _, item := range result.Items {
+        video := playlistVideo{
+            VideoId: item.VideoID,
+            Ready:   item.State == "completed",
+            Failed:  item.State == "failed",
+        }
+        if item.Result != nil {
+            video.Title = item.Result.Title
+            video.Path = item.Result.Path
+            totalMinutes += item.Result.Duration / 60
+            if video.Ready {
+                content := cleanSummaryMarkdown(item.Result.Summary)
+                video.Answer = template.HTML(ConvertMarkdownToHTML(item.Result.Answer))
+                video.Content = template.HTML(ConvertMarkdownToHTML(ReplaceMarkdownTimestamps(item.VideoID, content)))
+            }
+        }
+        videos = append(videos, video)
+    }
+
+    title := result.Title
+    if title == "" {
+        title = playlistId
+    }
+
+    data := struct {
+        Language             string
+        Path                 string
+        BaseUrl              string
+        PlaylistId           string
+        Title                string
+        Total                int
+        Completed            int
+        Done                 bool
+        NotSubmitted         bool
+        VideoDurationMinutes int
+        Videos               []playlistVideo
+        T        func(string) string // Translation function
+    }{
+        Language:             lang,
+        Path:                 r.URL.Path,
+        BaseUrl:              os.Getenv("BASE_URL"),
+        PlaylistId:           playlistId,
+        Title:                title,
+        Total:                result.Total,
+        Completed:            result.Completed,
+        Done:                 result.Done,
+        NotSubmitted:         notSubmitted,
+        VideoDurationMinutes: totalMinutes,
+        Videos:               videos,
+        T: func(key string) string {
+            return t(lang, key)
+        },
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    err = tmpl.Execute(w, data)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
+    }
+}
+
+// loadChannel handles the channel page, listing the summaries of a channel newest upload first
+// Example URL: /en/channel/UC_x5XG1OV2P6uZZ5FSM9Ttw?cursor=...
+func loadChannel(w http.ResponseWriter, r *http.Request, lang, channelId string) {
+    tmpl, err := template.ParseFS(templateFS, filepath.Join("templates", "channel.html"))
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error loading template: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    result, err := GetChannelContent(channelId, lang, r.URL.Query().Get("cursor"))
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    type channelVideo struct {
+        VideoId    string
+        Title      string
+        Path       string
+        UploadDate string
+        Duration   int
+        Answer     string
+    }
+
+    videos := make([]channelVideo, 0, len(result.Videos))
+    for _, video := range result.Videos {
+        uploadDate := ""
+        if video.UploadDate != "" {
+            uploadDate = formatDate(lang, video.UploadDate)
+        }
+        videos = append(videos, channelVideo{
+            VideoId:    video.Vid,
+            Title:      video.Title,
+            Path:       video.Path,
+            UploadDate: uploadDate,
+            Duration:   video.Duration / 60,
+            Answer:     video.Answer,
+        })
+    }
+
+    channelName := result.ChannelName
+    if channelName == "" {
+        channelName = channelId
+    }
+
+    nextPage := ""
+    if result.NextCursor != "" {
+        nextPage = fmt.Sprintf("/%s/channel/%s?cursor=%s", lang, channelId, url.QueryEscape(result.NextCursor))
+    }
+
+    data := struct {
+        Language    string
+        Path        string
+        BaseUrl     string
+        ChannelId   string
+        ChannelName string
+        VideoCount  int
+        Videos      []channelVideo
+        NextPage    string
+        T        func(string) string // Translation function
+    }{
+        Language:    lang,
+        Path:        r.URL.Path,
+        BaseUrl:     os.Getenv("BASE_URL"),
+        ChannelId:   channelId,
+        ChannelName: channelName,
+        VideoCount:  result.VideoCount,
+        Videos:      videos,
+        NextPage:    nextPage,
+        T: func(key string) string {
+            return t(lang, key)
+        },
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    err = tmpl.Execute(w, data)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
+    }
+}
+
+// loadCategory handles the category listing page, newest summaries first
+// Example URL: /en/category/Music?cursor=...
+func loadCategory(w http.ResponseWriter, r *http.Request, lang, category string) {
+    tmpl, err := template.ParseFS(templateFS, filepath.Join("templates", "category.html"))
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error loading template: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    videos, nextCursor, err := GetVideosFromCategory(lang, category, 20, r.URL.Query().Get("cursor"))
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    nextPage := ""
+    if nextCursor != "" {
+        nextPage = fmt.Sprintf("/%s/category/%s?cursor=%s", lang, url.PathEscape(category), url.QueryEscape(nextCursor))
+    }
+
+    data := struct {
+        Language string
+        Path     string
+        BaseUrl  string
+        Category string
+        Videos   []map[string]string
+        NextPage string
+        T        func(string) string // Translation function
+    }{
+        Language: lang,
+        Path:     r.URL.Path,
+        BaseUrl:  os.Getenv("BASE_URL"),
+        Category: category,
+        Videos:   videos,
+        NextPage: nextPage,
+        T: func(key string) string {
+            return t(lang, key)
+        },
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    err = tmpl.Execute(w, data)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
+    }
+}
+
+// loadSearch handles the search page, the query comes from ?q=
+// Example URL: /en/search?q=golang
+func loadSearch(w http.ResponseWriter, r *http.Request, lang string) {
+    tmpl, err := template.ParseFS(templateFS, filepath.Join("templates", "search.html"))
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error loading template: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    query := strings.TrimSpace(r.URL.Query().Get("q"))
+    result := &SearchResultsSingleLanguage{Query: query}
+    if query != "" {
+        result, err = GetSearchResults(lang, query)
+        if err != nil {
+            http.Error(w, err.Error(), http.StatusInternalServerError)
+            return
+        }
+    }
+
+    data := struct {
+        Language string
+        Path     string
+        BaseUrl  string
+        Query    string
+        Results  *SearchResultsSingleLanguage
+        T        func(string) string // Translation function
+    }{
+        Language: lang,
+        Path:     r.URL.Path,
+        BaseUrl:  os.Getenv("BASE_URL"),
+        Query:    query,
+        Results:  result,
+        T: func(key string) string {
+            return t(lang, key)
+        },
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    err = tmpl.Execute(w, data)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
+    }
+}
+
+// loadLibrary handles the library page of the signed in visitor, visitors without a
+// session are sent to the login first
+// Example URL: /en/library?tag=go&favorite=true
+func loadLibrary(w http.ResponseWriter, r *http.Request, lang string) {
+    tmpl, err := template.ParseFS(templateFS, filepath.Join("templates", "library.html"))
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error loading template: %v", err), http.StatusInternalServerError)
+        return
+    }
+
+    tag := r.URL.Query().Get("tag")
+    favorite := r.URL.Query().Get("favorite") == "true"
+    result, err := GetLibrary(lang, tag, favorite, r.Header.Get("Cookie"))
+    if err == errNotSignedIn {
+        http.Redirect(w, r, "/login?return_to="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+        return
+    }
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+
+    data := struct {
+        Language string
+        Path     string
+        BaseUrl  string
+        Tag      string
+        Favorite bool
+        Library  *LibraryContent
+        T        func(string) string // Translation function
+    }{
+        Language: lang,
+        Path:     r.URL.Path,
+        BaseUrl:  os.Getenv("BASE_URL"),
+        Tag:      tag,
+        Favorite: favorite,
+        Library:  result,
+        T: func(key string) string {
+            return t(lang, key)
+        },
+    }
+
+    w.Header().Set("Content-Type", "text/html")
+    w.Header().Set("Cache-Control", "no-store")
+    err = tmpl.Execute(w, data)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("Error rendering template: %v", err), http.StatusInternalServerError)
+    }
+}
+
 // formatDate formats a date string based on language
 func formatDate(lang, dateStr string) string {
 
@@ -1199,6 +2245,166 @@ func loadSummary(w http.ResponseWriter, r *http.Request, videoId string, lang st
 }
 
 
+// localReturnTo keeps return_to on this site, defaulting to the home page
+func localReturnTo(r *http.Request) string {
+    returnTo := r.URL.Query().Get("return_to")
+    if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+        return "/"
+    }
+    return returnTo
+}
+
+// handleLogin and handleLogout send the visitor to the API sign-in/out, which redirects
+// back to the page they came from.
+// Example URL: /login?return_to=/en/search
+func handleLogin(w http.ResponseWriter, r *http.Request) {
+    query := url.Values{}
+    query.Set("return_to", os.Getenv("BASE_URL")+localReturnTo(r))
+    http.Redirect(w, r, fmt.Sprintf("%s/login?%s", os.Getenv("SUMTUBE_API_PUBLIC"), query.Encode()), http.StatusFound)
+}
+
+func handleLogout(w http.ResponseWriter, r *http.Request) {
+    query := url.Values{}
+    query.Set("return_to", os.Getenv("BASE_URL")+localReturnTo(r))
+    http.Redirect(w, r, fmt.Sprintf("%s/logout?%s", os.Getenv("SUMTUBE_API_PUBLIC"), query.Encode()), http.StatusFound)
+}
+
+// proxyAccountAPI forwards a request of the visitor, with its session cookie, to
+// SUMTUBE_ACCOUNT_API so static/account.js and static/library.js do not need CORS credentials
+func proxyAccountAPI(w http.ResponseWriter, r *http.Request, path string) {
+    baseURL := os.Getenv("SUMTUBE_ACCOUNT_API")
+    if baseURL == "" {
+        http.Error(w, "SUMTUBE_ACCOUNT_API is not set", http.StatusNotFound)
+        return
+    }
+
+    req, err := http.NewRequest(r.Method, strings.TrimSuffix(baseURL, "/")+path, r.Body)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    for _, header := range []string{"Cookie", "Content-Type"} {
+        if value := r.Header.Get(header); value != "" {
+            req.Header.Set(header, value)
+        }
+    }
+    resp, err := apiDo(req)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("failed to call API: %v", err), http.StatusBadGateway)
+        return
+    }
+    defer resp.Body.Close()
+
+    w.Header().Set("Content-Type", resp.Header.Get("Content-Type"))
+    w.Header().Set("Cache-Control", "no-store")
+    w.WriteHeader(resp.StatusCode)
+    io.Copy(w, resp.Body)
+}
+
+// handleMe returns the signed in visitor (401 when there is none)
+func handleMe(w http.ResponseWriter, r *http.Request) {
+    proxyAccountAPI(w, r, "/me")
+}
+
+// handleLibraryItem saves, tags or removes a video of the visitor's library
+// Example URL: PUT /library/{videoId}/{lang}
+func handleLibraryItem(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+        http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+        return
+    }
+    segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/library/"), "/"), "/")
+    if len(segments) != 2 || !isVideoID(segments[0]) || !allowedLanguages[segments[1]] {
+        http.NotFound(w, r)
+        return
+    }
+    proxyAccountAPI(w, r, "/library/"+segments[0]+"/"+segments[1])
+}
+
+// handleAsk forwards a question of the blog page to SUMTUBE_ASK_API. The renderer key is
+// not sent, the API rate limits the questions by the visitor IP instead.
+// Example URL: POST /ask/{videoId}
+func handleAsk(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodPost {
+        http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+        return
+    }
+    videoID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/ask/"), "/")
+    if !isVideoID(videoID) {
+        http.NotFound(w, r)
+        return
+    }
+    baseURL := os.Getenv("SUMTUBE_ASK_API")
+    if baseURL == "" {
+        http.Error(w, "SUMTUBE_ASK_API is not set", http.StatusNotFound)
+        return
+    }
+
+    proxyVisitorAPI(w, r, "POST", fmt.Sprintf("%s/%s/ask", strings.TrimSuffix(baseURL, "/"), videoID), http.MaxBytesReader(w, r.Body, 8<<10))
+}
+
+// handleTranscript forwards the transcript tab and downloads of the blog page to
+// SUMTUBE_TRANSCRIPT_API, keeping the query (lang, format, timestamps).
+// Example URL: GET /transcript/{videoId}?format=srt
+func handleTranscript(w http.ResponseWriter, r *http.Request) {
+    if r.Method != http.MethodGet {
+        http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+        return
+    }
+    videoID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/transcript/"), "/")
+    if !isVideoID(videoID) {
+        http.NotFound(w, r)
+        return
+    }
+    baseURL := os.Getenv("SUMTUBE_TRANSCRIPT_API")
+    if baseURL == "" {
+        http.Error(w, "SUMTUBE_TRANSCRIPT_API is not set", http.StatusNotFound)
+        return
+    }
+
+    query := url.Values{}
+    for _, key := range []string{"lang", "format", "timestamps"} {
+        if value := r.URL.Query().Get(key); value != "" {
+            query.Set(key, value)
+        }
+    }
+    proxyVisitorAPI(w, r, "GET", fmt.Sprintf("%s/%s?%s", strings.TrimSuffix(baseURL, "/"), videoID, query.Encode()), nil)
+}
+
+// proxyVisitorAPI calls the API on behalf of the visitor, without the renderer key so the
+// API rate limits by the forwarded visitor IP, and copies the response back.
+func proxyVisitorAPI(w http.ResponseWriter, r *http.Request, method string, target string, body io.Reader) {
+    req, err := http.NewRequest(method, target, body)
+    if err != nil {
+        http.Error(w, err.Error(), http.StatusInternalServerError)
+        return
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    for _, header := range []string{"X-Real-IP", "X-Forwarded-For"} {
+        if value := r.Header.Get(header); value != "" {
+            req.Header.Set(header, value)
+        }
+    }
+
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        http.Error(w, fmt.Sprintf("failed to call API: %v", err), http.StatusBadGateway)
+        return
+    }
+    defer resp.Body.Close()
+
+    for _, header := range []string{"Content-Type", "Content-Disposition", "Content-Language", "Retry-After"} {
+        if value := resp.Header.Get(header); value != "" {
+            w.Header().Set(header, value)
+        }
+    }
+    w.Header().Set("Cache-Control", "no-store")
+    w.WriteHeader(resp.StatusCode)
+    io.Copy(w, resp.Body)
+}
+
 func main() {
 	// // Serve the /ID route
 	// //http.HandleFunc("/", handleBlog)
@@ -1215,6 +2421,13 @@ func main() {
 
 	
 	http.HandleFunc("/", router)
+	http.HandleFunc("/login", handleLogin)
+	http.HandleFunc("/logout", handleLogout)
+	http.HandleFunc("/me", handleMe)
+	http.HandleFunc("/library/", handleLibraryItem)
+	http.HandleFunc("/ask/", handleAsk)
+	http.HandleFunc("/transcript/", handleTranscript)
+	http.HandleFunc("/export/", handleExport)
 
 	// Start the server
 	println("Server is running on http://localhost:8081 renderer server")
@@ -1,6 +1,10 @@
 package main
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 )
 
@@ -53,6 +57,41 @@ func TestGetRouteType(t *testing.T) {
 			segments: []string{"en", "abcdefghijk", "my-title"},
 			want:     BLOG_TEMPLATE,
 		},
+		{
+			name:     "Lang + playlist + PlaylistID → Playlist template",
+			segments: []string{"en", "playlist", "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf"},
+			want:     PLAYLIST_TEMPLATE,
+		},
+		{
+			name:     "Lang + channel + ChannelID → Channel template",
+			segments: []string{"pt", "channel", "UC84asuWqcrFqEtWqSCtS85Q"},
+			want:     CHANNEL_TEMPLATE,
+		},
+		{
+			name:     "Lang + channel + invalid ChannelID",
+			segments: []string{"pt", "channel", "not-a-channel"},
+			want:     REDIRECT_HOME,
+		},
+		{
+			name:     "Lang + search → Search template",
+			segments: []string{"pt", "search"},
+			want:     SEARCH_TEMPLATE,
+		},
+		{
+			name:     "Lang + library → Library template",
+			segments: []string{"en", "library"},
+			want:     LIBRARY_TEMPLATE,
+		},
+		{
+			name:     "Lang + category + name → Category template",
+			segments: []string{"en", "category", "News & Politics"},
+			want:     CATEGORY_TEMPLATE,
+		},
+		{
+			name:     "Playlist without language",
+			segments: []string{"playlist", "PLrAXtmErZgOeiKm4sgNOknGvNjby9efdf"},
+			want:     REDIRECT_HOME,
+		},
 		{
 			name:     "VideoID only → Home",
 			segments: []string{"abcdefghijk"},
@@ -211,3 +250,53 @@ func TestExtractYouTubeIFromYoutubeUrl(t *testing.T) {
 	}
 }
 
+
+func TestLocalReturnTo(t *testing.T) {
+	for input, want := range map[string]string{
+		"/pt/search?q=go":        "/pt/search?q=go",
+		"":                       "/",
+		"//evil.com":             "/",
+		"https://evil.com/login": "/",
+	} {
+		r := httptest.NewRequest("GET", "/login?return_to="+url.QueryEscape(input), nil)
+		if got := localReturnTo(r); got != want {
+			t.Errorf("localReturnTo(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestLoadPlaylistOnlyEnqueuesOnPost(t *testing.T) {
+	var posts int
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts++
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"playlistId":"PLtest","videos":[]}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer api.Close()
+	t.Setenv("SUMTUBE_PLAYLIST_API", api.URL+"/summary/playlist")
+
+	rec := httptest.NewRecorder()
+	loadPlaylist(rec, httptest.NewRequest(http.MethodGet, "/en/playlist/PLtest", nil), "en", "PLtest")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if posts != 0 {
+		t.Fatalf("GET submitted the playlist %d times", posts)
+	}
+	if !strings.Contains(rec.Body.String(), `method="POST"`) {
+		t.Errorf("Expected the submit form for a playlist never submitted")
+	}
+
+	rec = httptest.NewRecorder()
+	loadPlaylist(rec, httptest.NewRequest(http.MethodPost, "/en/playlist/PLtest", nil), "en", "PLtest")
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/en/playlist/PLtest" {
+		t.Fatalf("Expected a redirect to the playlist page, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+	if posts != 1 {
+		t.Fatalf("Expected one submission, got %d", posts)
+	}
+}
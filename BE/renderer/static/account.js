@@ -0,0 +1,35 @@
+;(() => {
+  // Turns the "Login" link of the nav into the account of the signed in visitor.
+  // /me, /login and /logout are forwarded by the renderer to the API.
+  const link = document.getElementById("account-link")
+  if (!link) return
+
+  const here = window.location.pathname + window.location.search
+  link.href = `/login?return_to=${encodeURIComponent(here)}`
+
+  fetch("/me", { credentials: "same-origin" })
+    .then((response) => (response.ok ? response.json() : null))
+    .then((user) => {
+      if (!user) return
+      link.textContent = ""
+      if (user.picture) {
+        const avatar = document.createElement("img")
+        avatar.src = user.picture
+        avatar.alt = ""
+        avatar.referrerPolicy = "no-referrer"
+        avatar.className = "w-6 h-6 rounded-full"
+        link.appendChild(avatar)
+      }
+      link.appendChild(document.createTextNode(user.name || user.email))
+      link.title = "Logout"
+      link.href = `/logout?return_to=${encodeURIComponent(here)}`
+
+      const lang = window.location.pathname.split("/")[1]
+      const library = document.createElement("a")
+      library.href = `/${lang || "en"}/library`
+      library.textContent = "📚"
+      library.className = "ml-auto mr-4 text-2xl"
+      link.before(library)
+    })
+    .catch(() => {})
+})()
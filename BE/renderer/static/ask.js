@@ -0,0 +1,60 @@
+;(() => {
+  // "Ask this video" form of the blog page. POST /ask/{videoId} is forwarded by the
+  // renderer to the API. The (HH:MM:SS) timestamps of the answer the API cited from the
+  // transcript become YouTube links, the others stay plain text.
+  const section = document.getElementById("ask-video")
+  if (!section) return
+
+  const form = section.querySelector("form")
+  const button = form.querySelector("button")
+  const output = section.querySelector("[data-answer]")
+  const timestamp = /\(?(\d{1,2}):(\d{2}):(\d{2})\)?/g
+
+  const render = (answer, citations = []) => {
+    const cited = new Map(citations.map((citation) => [citation.seconds, citation]))
+    output.textContent = ""
+    let last = 0
+    for (const match of answer.matchAll(timestamp)) {
+      const seconds = Number(match[1]) * 3600 + Number(match[2]) * 60 + Number(match[3])
+      const citation = cited.get(seconds)
+      if (!citation) continue
+      output.appendChild(document.createTextNode(answer.slice(last, match.index)))
+      const link = document.createElement("a")
+      link.href = citation.url
+      link.target = "_blank"
+      link.rel = "noopener"
+      link.className = "text-red-600 hover:underline"
+      link.title = citation.text
+      link.textContent = match[0]
+      output.appendChild(link)
+      last = match.index + match[0].length
+    }
+    output.appendChild(document.createTextNode(answer.slice(last)))
+    output.classList.remove("hidden")
+  }
+
+  form.addEventListener("submit", (event) => {
+    event.preventDefault()
+    button.disabled = true
+    button.classList.add("opacity-70", "cursor-not-allowed")
+
+    fetch(`/ask/${section.dataset.vid}`, {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ question: form.question.value, lang: section.dataset.lang }),
+    })
+      .then((response) => {
+        if (!response.ok) throw new Error(`ask failed: ${response.status}`)
+        return response.json()
+      })
+      .then((result) => render(result.answer, result.citations || []))
+      .catch((err) => {
+        console.error(err)
+        render(section.dataset.error)
+      })
+      .finally(() => {
+        button.disabled = false
+        button.classList.remove("opacity-70", "cursor-not-allowed")
+      })
+  })
+})()
@@ -0,0 +1,32 @@
+;(() => {
+  // Favorite, notes, tags and removal of the items of the library page.
+  // /library/{videoId}/{lang} is forwarded by the renderer to the API.
+  const update = (item, method, body) =>
+    fetch(`/library/${item.dataset.videoId}/${item.dataset.lang}`, {
+      method,
+      credentials: "same-origin",
+      headers: body ? { "Content-Type": "application/json" } : {},
+      body: body ? JSON.stringify(body) : undefined,
+    }).then((response) => {
+      if (!response.ok) throw new Error(`library update failed: ${response.status}`)
+      window.location.reload()
+    })
+
+  document.querySelectorAll("[data-library-item]").forEach((item) => {
+    item.querySelector('[data-action="favorite"]').addEventListener("click", (event) => {
+      update(item, "PUT", { favorite: event.currentTarget.dataset.favorite !== "true" }).catch(console.error)
+    })
+    item.querySelector('[data-action="remove"]').addEventListener("click", () => {
+      update(item, "DELETE").catch(console.error)
+    })
+    item.querySelector('[data-action="save"]').addEventListener("submit", (event) => {
+      event.preventDefault()
+      const form = event.currentTarget
+      const tags = form.tags.value
+        .split(",")
+        .map((tag) => tag.trim())
+        .filter(Boolean)
+      update(item, "PUT", { notes: form.notes.value, tags }).catch(console.error)
+    })
+  })
+})()
@@ -0,0 +1,65 @@
+;(() => {
+  // Summary / Transcript tabs of the blog page. The transcript is fetched once, as JSON
+  // cues from GET /transcript/{videoId}, when its tab is first opened.
+  const tabs = document.getElementById("content-tabs")
+  const section = document.getElementById("transcript")
+  if (!tabs || !section) return
+
+  const list = section.querySelector("[data-cues]")
+  let loaded = false
+
+  const showError = () => {
+    list.textContent = ""
+    const item = document.createElement("li")
+    item.className = "text-gray-500"
+    item.textContent = section.dataset.error
+    list.appendChild(item)
+  }
+
+  const render = (cues) => {
+    list.textContent = ""
+    for (const cue of cues) {
+      const item = document.createElement("li")
+      const link = document.createElement("a")
+      link.href = `https://www.youtube.com/watch?v=${section.dataset.vid}&t=${Math.floor(cue.startMs / 1000)}s`
+      link.target = "_blank"
+      link.rel = "noopener"
+      link.className = "text-red-600 hover:underline font-mono text-sm mr-3"
+      link.textContent = cue.start.split(".")[0]
+      item.appendChild(link)
+      item.appendChild(document.createTextNode(cue.text))
+      list.appendChild(item)
+    }
+  }
+
+  const load = () => {
+    if (loaded) return
+    loaded = true
+    fetch(`/transcript/${section.dataset.vid}?format=json`)
+      .then((response) => {
+        if (!response.ok) throw new Error(`transcript failed: ${response.status}`)
+        return response.json()
+      })
+      .then((result) => (result.cues.length ? render(result.cues) : showError()))
+      .catch((err) => {
+        console.error(err)
+        showError()
+      })
+  }
+
+  tabs.addEventListener("click", (event) => {
+    const button = event.target.closest("[data-tab]")
+    if (!button) return
+    for (const tab of tabs.querySelectorAll("[data-tab]")) {
+      const active = tab === button
+      tab.classList.toggle("border-red-600", active)
+      tab.classList.toggle("font-semibold", active)
+      tab.classList.toggle("border-transparent", !active)
+      tab.classList.toggle("text-gray-500", !active)
+    }
+    for (const panel of document.querySelectorAll("[data-panel]")) {
+      panel.classList.toggle("hidden", panel.dataset.panel !== button.dataset.tab)
+    }
+    if (button.dataset.tab === "transcript") load()
+  })
+})()
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// extractJSONObject finds the object a page script assigns to name, in any of the forms
+// YouTube serves: `var ytInitialData = {...};`, `ytInitialPlayerResponse = {...}` or
+// `window["ytInitialData"] = {...}`. The object ends at its matching brace outside of
+// strings, so a "};" or "</script>" inside a description does not cut it short, and
+// mentions of the name that are not assignments are skipped.
+func extractJSONObject(content string, name string) ([]byte, error) {
+	for offset := 0; ; {
+		i := strings.Index(content[offset:], name)
+		if i < 0 {
+			return nil, fmt.Errorf("%s not found", name)
+		}
+		offset += i + len(name)
+
+		rest := strings.TrimLeft(content[offset:], `"]' `+"\t\n")
+		if !strings.HasPrefix(rest, "=") {
+			continue
+		}
+		rest = strings.TrimLeft(rest[1:], " \t\n")
+		if !strings.HasPrefix(rest, "{") {
+			continue
+		}
+
+		if object := matchingObject(rest); object != "" && json.Valid([]byte(object)) {
+			return []byte(object), nil
+		}
+	}
+}
+
+// matchingObject is the prefix of s, which starts with "{", up to its matching "}",
+// empty when the object is not closed
+func matchingObject(s string) string {
+	depth := 0
+	inString := false
+	escaped := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{':
+			depth++
+		case c == '}':
+			depth--
+			if depth == 0 {
+				return s[:i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// findValues collects every value stored under key anywhere in a decoded JSON document,
+// in no particular order. The renderers of ytInitialData move around between page
+// versions, their names much less.
+func findValues(node any, key string) []any {
+	var values []any
+	switch v := node.(type) {
+	case map[string]any:
+		if value, ok := v[key]; ok {
+			values = append(values, value)
+		}
+		for k, child := range v {
+			if k != key {
+				values = append(values, findValues(child, key)...)
+			}
+		}
+	case []any:
+		for _, child := range v {
+			values = append(values, findValues(child, key)...)
+		}
+	}
+	return values
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractJSONObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		variable string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:    "var assignment",
+			content: `<script>var ytInitialData = {"a":1};</script>`,
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "window index form",
+			content: `<script>window["ytInitialData"] = {"a":{"b":[1,2]}};</script>`,
+			want:    `{"a":{"b":[1,2]}}`,
+		},
+		{
+			name:     "assignment without var nor spaces",
+			content:  `<script>ytInitialPlayerResponse={"a":1};var meta = {};</script>`,
+			variable: "ytInitialPlayerResponse",
+			want:     `{"a":1}`,
+		},
+		{
+			name:    "end of statement and script inside a string",
+			content: `<script>var ytInitialData = {"description":"x = {}; };</script><script>","n":2};</script>`,
+			want:    `{"description":"x = {}; };</script><script>","n":2}`,
+		},
+		{
+			name:    "escaped quotes and backslashes inside a string",
+			content: `<script>var ytInitialData = {"title":"say \"}\" \\","n":{"m":3}};</script>`,
+			want:    `{"title":"say \"}\" \\","n":{"m":3}}`,
+		},
+		{
+			name:    "mentions that are not assignments are skipped",
+			content: `<script>if (window.ytInitialData) {} log("ytInitialData", x); var ytInitialData = {"a":1};</script>`,
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "assignment of something other than an object",
+			content: `<script>var ytInitialData = null; window["ytInitialData"] = {"a":1};</script>`,
+			want:    `{"a":1}`,
+		},
+		{
+			name:    "object never closed",
+			content: `<script>var ytInitialData = {"a":{"b":1};</script>`,
+			wantErr: true,
+		},
+		{
+			name:    "name missing",
+			content: `<script>var somethingElse = {"a":1};</script>`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variable := tt.variable
+			if variable == "" {
+				variable = "ytInitialData"
+			}
+			got, err := extractJSONObject(tt.content, variable)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extractJSONObject: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchingObject(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`{}`, `{}`},
+		{`{"a":{"b":{}}} trailing`, `{"a":{"b":{}}}`},
+		{`{"a":"}"} }`, `{"a":"}"}`},
+		{`{"a":"\"}"}`, `{"a":"\"}"}`},
+		{`{"a":"\\"}x`, `{"a":"\\"}`},
+		{`{"a":"{"}`, `{"a":"{"}`},
+		{`{"a":1`, ``},
+		{`{"a":"}`, ``},
+	}
+
+	for _, tt := range tests {
+		if got := matchingObject(tt.in); got != tt.want {
+			t.Errorf("matchingObject(%s) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExtractInitialData(t *testing.T) {
+	content := `<html><script>var ytInitialData = {"contents":{"likeButton":{"likeCountEntity":{"likeCountIfIndifferentNumber":"1234"}},` +
+		`"markers":[` +
+		`{"chapterRenderer":{"title":{"simpleText":"Outro }; </script>"},"timeRangeStartMillis":90000}},` +
+		`{"chapterRenderer":{"title":{"simpleText":"Intro"},"timeRangeStartMillis":0}},` +
+		`{"chapterRenderer":{"title":{"simpleText":"Intro again"},"timeRangeStartMillis":0}}` +
+		`]}};</script></html>`
+
+	data, err := extractJSONObject(content, "ytInitialData")
+	if err != nil {
+		t.Fatalf("extractJSONObject: %v", err)
+	}
+	info := &YoutubeMetadataResponse{}
+	if err := extractInitialData(data, info); err != nil {
+		t.Fatalf("extractInitialData: %v", err)
+	}
+
+	if info.LikeCount != "1234" {
+		t.Errorf("LikeCount = %q, want 1234", info.LikeCount)
+	}
+	want := []Chapter{{Title: "Intro", StartSeconds: 0}, {Title: "Outro }; </script>", StartSeconds: 90}}
+	if !slices.Equal(info.Chapters, want) {
+		t.Errorf("Chapters = %+v, want %+v", info.Chapters, want)
+	}
+}
+
+func TestExtractInitialData_WithoutLikesNorChapters(t *testing.T) {
+	info := &YoutubeMetadataResponse{}
+	if err := extractInitialData([]byte(`{"contents":{}}`), info); err != nil {
+		t.Fatalf("extractInitialData: %v", err)
+	}
+	if info.LikeCount != "" || len(info.Chapters) != 0 {
+		t.Errorf("Expected nothing to be read, got %+v", info)
+	}
+	if err := extractInitialData([]byte(`{"contents":`), info); err == nil {
+		t.Errorf("Expected an error for a truncated document")
+	}
+}
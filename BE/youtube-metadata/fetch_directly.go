@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strings"
 )
 
@@ -18,6 +20,7 @@ type rawInfo struct {
 	Microformat struct {
 		PlayerMicroformatRenderer struct {
 			Title             struct{ SimpleText string } `json:"title"`
+			Description       struct{ SimpleText string } `json:"description"`
 			ViewCount         string                     `json:"viewCount"`
 			LengthSeconds     string                     `json:"lengthSeconds"`
 			ExternalChannelID string                     `json:"externalChannelId"`
@@ -25,26 +28,48 @@ type rawInfo struct {
 			OwnerProfileURL   string                     `json:"ownerProfileUrl"`
 			PublishDate       string                     `json:"publishDate"`
 			Category          string                     `json:"category"`
+			LiveBroadcastDetails struct {
+				IsLiveNow bool `json:"isLiveNow"`
+			} `json:"liveBroadcastDetails"`
 		} `json:"playerMicroformatRenderer"`
 	} `json:"microformat"`
-	Captions struct {
-		PlayerCaptionsTracklistRenderer struct {
-			CaptionTracks []struct {
-				BaseURL string `json:"baseUrl"`
-			} `json:"captionTracks"`
-		} `json:"playerCaptionsTracklistRenderer"`
-	} `json:"captions"`
-}
-
-type ytInitialPlayerResponse struct {
+	VideoDetails struct {
+		ShortDescription string   `json:"shortDescription"`
+		Keywords         []string `json:"keywords"`
+		ViewCount        string   `json:"viewCount"`
+		LengthSeconds    string   `json:"lengthSeconds"`
+		IsLive           bool     `json:"isLive"`
+		IsUpcoming       bool     `json:"isUpcoming"`
+		Thumbnail        struct {
+			Thumbnails []Thumbnail `json:"thumbnails"`
+		} `json:"thumbnail"`
+	} `json:"videoDetails"`
 	Captions struct {
 		PlayerCaptionsTracklistRenderer struct {
 			CaptionTracks []struct {
 				BaseUrl      string `json:"baseUrl"`
 				LanguageCode string `json:"languageCode"`
+				Kind         string `json:"kind"`
 			} `json:"captionTracks"`
 		} `json:"playerCaptionsTracklistRenderer"`
 	} `json:"captions"`
+	StreamingData struct {
+		AdaptiveFormats []struct {
+			AudioTrack *struct {
+				ID             string `json:"id"`
+				AudioIsDefault bool   `json:"audioIsDefault"`
+			} `json:"audioTrack"`
+		} `json:"adaptiveFormats"`
+	} `json:"streamingData"`
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 func extractInfo(data []byte) (*YoutubeMetadataResponse, error) {
@@ -52,22 +77,87 @@ func extractInfo(data []byte) (*YoutubeMetadataResponse, error) {
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
+	microformat := raw.Microformat.PlayerMicroformatRenderer
 
 	info := YoutubeMetadataResponse{
-		Title:             raw.Microformat.PlayerMicroformatRenderer.Title.SimpleText,
-		ViewCount:         raw.Microformat.PlayerMicroformatRenderer.ViewCount,
-		LengthSeconds:     raw.Microformat.PlayerMicroformatRenderer.LengthSeconds,
-		ExternalChannelID: raw.Microformat.PlayerMicroformatRenderer.ExternalChannelID,
-		OwnerChannelName:  raw.Microformat.PlayerMicroformatRenderer.OwnerChannelName,
-		ChannelUrl:   	   raw.Microformat.PlayerMicroformatRenderer.OwnerProfileURL,
-		PublishDate:       raw.Microformat.PlayerMicroformatRenderer.PublishDate,
-		Category:          raw.Microformat.PlayerMicroformatRenderer.Category,
+		Title:             microformat.Title.SimpleText,
+		ViewCount:         firstNonEmpty(microformat.ViewCount, raw.VideoDetails.ViewCount),
+		LengthSeconds:     firstNonEmpty(microformat.LengthSeconds, raw.VideoDetails.LengthSeconds),
+		ExternalChannelID: microformat.ExternalChannelID,
+		OwnerChannelName:  microformat.OwnerChannelName,
+		ChannelUrl:        microformat.OwnerProfileURL,
+		PublishDate:       microformat.PublishDate,
+		Category:          microformat.Category,
 		Captions:          []Caption{},
+		Description:       firstNonEmpty(raw.VideoDetails.ShortDescription, microformat.Description.SimpleText),
+		Keywords:          raw.VideoDetails.Keywords,
+		Thumbnails:        raw.VideoDetails.Thumbnail.Thumbnails,
+		IsLive:            raw.VideoDetails.IsLive || microformat.LiveBroadcastDetails.IsLiveNow,
+		IsUpcoming:        raw.VideoDetails.IsUpcoming,
+	}
+
+	for _, c := range raw.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks {
+		info.Captions = append(info.Captions, Caption{
+			BaseUrl:      c.BaseUrl,
+			LanguageCode: c.LanguageCode,
+		})
+	}
+
+	// the default audio track is named after its language ("en.4"), videos with a single
+	// track have none and their automatic captions are in the spoken language
+	for _, format := range raw.StreamingData.AdaptiveFormats {
+		if format.AudioTrack != nil && format.AudioTrack.AudioIsDefault {
+			info.DefaultAudioLanguage, _, _ = strings.Cut(format.AudioTrack.ID, ".")
+			break
+		}
+	}
+	if info.DefaultAudioLanguage == "" {
+		for _, c := range raw.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks {
+			if c.Kind == "asr" {
+				info.DefaultAudioLanguage = c.LanguageCode
+				break
+			}
+		}
 	}
 
 	return &info, nil
 }
 
+// extractInitialData adds the like count and the chapters found in ytInitialData
+func extractInitialData(data []byte, info *YoutubeMetadataResponse) error {
+	var initialData any
+	if err := json.Unmarshal(data, &initialData); err != nil {
+		return err
+	}
+
+	// the like button reads its count from a likeCountEntity, the exact number as a string
+	for _, value := range findValues(initialData, "likeCountIfIndifferentNumber") {
+		if count, ok := value.(string); ok && count != "" {
+			info.LikeCount = count
+			break
+		}
+	}
+
+	seen := make(map[int]bool)
+	for _, value := range findValues(initialData, "chapterRenderer") {
+		renderer, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		start, ok := renderer["timeRangeStartMillis"].(float64)
+		if !ok || seen[int(start)] {
+			continue
+		}
+		seen[int(start)] = true
+		title, _ := renderer["title"].(map[string]any)
+		text, _ := title["simpleText"].(string)
+		info.Chapters = append(info.Chapters, Chapter{Title: text, StartSeconds: int(start) / 1000})
+	}
+	sort.Slice(info.Chapters, func(i, j int) bool { return info.Chapters[i].StartSeconds < info.Chapters[j].StartSeconds })
+
+	return nil
+}
+
 func FetchDirectly(videoID string) (*YoutubeMetadataResponse, error) {
 	client := http.DefaultClient
 
@@ -93,32 +183,23 @@ func FetchDirectly(videoID string) (*YoutubeMetadataResponse, error) {
 
 	content := string(body)
 
-	split := strings.Split(content, "ytInitialPlayerResponse = ")
-	if len(split) < 2 {
-		return nil, fmt.Errorf("ytInitialPlayerResponse not found")
-	}
-	split = strings.Split(split[1], ";</script>")
-	if len(split) < 1 {
-		return nil, fmt.Errorf("ytInitialPlayerResponse end not found")
+	playerResponse, err := extractJSONObject(content, "ytInitialPlayerResponse")
+	if err != nil {
+		return nil, err
 	}
 
-	jsonData := split[0]
-
-	info, err := extractInfo([]byte(jsonData))
+	info, err := extractInfo(playerResponse)
 	if err != nil {
 		return nil, fmt.Errorf("error extracting info: %w", err)
 	}
 
-	var captions ytInitialPlayerResponse
-	if err := json.Unmarshal([]byte(jsonData), &captions); err != nil {
-		return nil, fmt.Errorf("error parsing captions: %w", err)
+	// the metadata is still usable without the like count and the chapters
+	initialData, err := extractJSONObject(content, "ytInitialData")
+	if err == nil {
+		err = extractInitialData(initialData, info)
 	}
-
-	for _, c := range captions.Captions.PlayerCaptionsTracklistRenderer.CaptionTracks {
-		info.Captions = append(info.Captions, Caption{
-			BaseUrl:      c.BaseUrl,
-			LanguageCode: c.LanguageCode,
-		})
+	if err != nil {
+		log.Printf("⚠️ ytInitialData of %s not read: %v", videoID, err)
 	}
 
 	return info, nil
@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -49,16 +50,23 @@ var (
 
 var httpClient = &http.Client{} 
 
-func init() {
+// loadDownsubConfig reads the DownSub settings, it is called from main so the tests
+// run without them
+func loadDownsubConfig() error {
 	downsubAPIKey = os.Getenv("DOWNSUB_API_KEY")
 	downsubBaseURL = os.Getenv("DOWNSUB_BASE_URL")
 
+	// the fixtures answer for DownSub when replaying
+	if fixturesMode == fixturesReplay {
+		return nil
+	}
 	if downsubAPIKey == "" {
-		panic("DOWNSUB_API_KEY environment variable is not set")
+		return errors.New("DOWNSUB_API_KEY environment variable is not set")
 	}
 	if downsubBaseURL == "" {
-		panic("DOWNSUB_BASE_URL environment variable is not set")
+		return errors.New("DOWNSUB_BASE_URL environment variable is not set")
 	}
+	return nil
 }
 
 
@@ -152,6 +160,7 @@ func convertDownSubResponseToFlatResponse(downsubInfo *DownsubResponse) *Youtube
 		PublishDate:       downsubInfo.Data.Metadata.PublishDate,
 		Category:          downsubInfo.Data.Metadata.Category,
 		ViewCount:         vcStr,
+		Description:       downsubInfo.Data.Metadata.Description,
 		Captions:          captions,
 	}
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const playlistURL = "https://www.youtube.com/playlist?list="
+
+type YoutubePlaylistResponse struct {
+	PlaylistID string   `json:"playlist_id"`
+	Title      string   `json:"title"`
+	VideoIDs   []string `json:"video_ids"`
+}
+
+var playlistVideoIDPattern = regexp.MustCompile(`"playlistVideoRenderer":\{"videoId":"([0-9A-Za-z_-]{11})"`)
+var playlistTitlePattern = regexp.MustCompile(`<meta property="og:title" content="([^"]*)">`)
+
+// extractPlaylist reads the playlist title and video IDs (in playlist order, without duplicates)
+// from the HTML of the playlist page. Only the videos rendered in the first page are listed,
+// YouTube loads the rest with continuation requests (around 100 videos).
+func extractPlaylist(playlistID string, content string) (*YoutubePlaylistResponse, error) {
+	playlist := YoutubePlaylistResponse{
+		PlaylistID: playlistID,
+		VideoIDs:   []string{},
+	}
+
+	if match := playlistTitlePattern.FindStringSubmatch(content); match != nil {
+		playlist.Title = html.UnescapeString(match[1])
+	}
+
+	seen := make(map[string]bool)
+	for _, match := range playlistVideoIDPattern.FindAllStringSubmatch(content, -1) {
+		if seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		playlist.VideoIDs = append(playlist.VideoIDs, match[1])
+	}
+
+	if len(playlist.VideoIDs) == 0 {
+		return nil, fmt.Errorf("no videos found in playlist %s", playlistID)
+	}
+	return &playlist, nil
+}
+
+func FetchPlaylist(playlistID string) (*YoutubePlaylistResponse, error) {
+	client := http.DefaultClient
+
+	proxyStr := os.Getenv("PROXY_SERVER")
+	if proxyStr != "" {
+		proxyURL, err := url.Parse(proxyStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %v", err)
+		}
+		client = &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	}
+
+	resp, err := client.Get(playlistURL + url.QueryEscape(playlistID))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch playlist page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("playlist page returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response: %w", err)
+	}
+
+	content := string(body)
+	if !strings.Contains(content, "ytInitialData") {
+		return nil, fmt.Errorf("ytInitialData not found")
+	}
+
+	return extractPlaylist(playlistID, content)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// METADATA_FIXTURES modes
+const (
+	fixturesRecord = "record" // the answers of the methods are saved to the fixtures directory
+	fixturesReplay = "replay" // the methods answer only from the fixtures directory, offline
+)
+
+const defaultFixturesDir = "fixtures"
+
+var (
+	fixturesMode = os.Getenv("METADATA_FIXTURES")
+	// a video ID never escapes the fixtures directory
+	fixtureVideoIDPattern = regexp.MustCompile(`^[0-9A-Za-z_-]{11}$`)
+)
+
+// Fixture is the answer of one method for one video, {vid}.{method}.json.
+// Failures are recorded too, so the fallback between methods replays the same way.
+type Fixture struct {
+	Metadata *YoutubeMetadataResponse `json:"metadata,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+func fixturesDir() string {
+	if dir := os.Getenv("METADATA_FIXTURES_DIR"); dir != "" {
+		return dir
+	}
+	return defaultFixturesDir
+}
+
+func fixturePath(videoID string, method string) (string, error) {
+	if !fixtureVideoIDPattern.MatchString(videoID) {
+		return "", fmt.Errorf("invalid video ID %q", videoID)
+	}
+	return filepath.Join(fixturesDir(), videoID+"."+method+".json"), nil
+}
+
+func recordFixture(videoID string, method string, info *YoutubeMetadataResponse, fetchErr error) error {
+	path, err := fixturePath(videoID, method)
+	if err != nil {
+		return err
+	}
+	fixture := Fixture{Metadata: info}
+	if fetchErr != nil {
+		fixture = Fixture{Error: fetchErr.Error()}
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func replayFixture(videoID string, method string) (*YoutubeMetadataResponse, error) {
+	path, err := fixturePath(videoID, method)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no fixture %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	if fixture.Error != "" {
+		return nil, errors.New(fixture.Error)
+	}
+	if fixture.Metadata == nil {
+		return nil, fmt.Errorf("empty fixture %s", path)
+	}
+	if fixture.Metadata.Captions == nil {
+		fixture.Metadata.Captions = []Caption{}
+	}
+	return fixture.Metadata, nil
+}
+
+// withFixtures records or replays the methods following METADATA_FIXTURES,
+// they are left as they are when it is unset
+func withFixtures(methods []MetadataMethod) []MetadataMethod {
+	wrapped := make([]MetadataMethod, 0, len(methods))
+	for _, method := range methods {
+		switch fixturesMode {
+		case fixturesRecord:
+			fetch := method.Fetch
+			name := method.Name
+			method.Fetch = func(videoID string) (*YoutubeMetadataResponse, error) {
+				info, err := fetch(videoID)
+				if recordErr := recordFixture(videoID, name, info, err); recordErr != nil {
+					log.Printf("⚠️ Failed to record the %s fixture of %s: %v", name, videoID, recordErr)
+				}
+				return info, err
+			}
+		case fixturesReplay:
+			name := method.Name
+			method.Fetch = func(videoID string) (*YoutubeMetadataResponse, error) {
+				return replayFixture(videoID, name)
+			}
+		}
+		wrapped = append(wrapped, method)
+	}
+	return wrapped
+}
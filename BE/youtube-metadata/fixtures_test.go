@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// useFixtures sets the fixtures mode and directory and starts from an empty cache
+// whose clock is at now
+func useFixtures(t *testing.T, mode string, dir string, now time.Time) {
+	t.Helper()
+	previousMode, previousCache := fixturesMode, metadataCache
+	t.Cleanup(func() { fixturesMode, metadataCache = previousMode, previousCache })
+
+	t.Setenv("METADATA_FIXTURES_DIR", dir)
+	t.Setenv("METADATA_METHOD", "")
+	fixturesMode = mode
+	metadataCache = NewMetadataCache(defaultMetadataCacheTTL, defaultCaptionURLCacheTTL)
+	metadataCache.now = func() time.Time { return now }
+}
+
+// the signed caption URL of gSrcc0oA6Q4 expires on 2025-06-29 at 14:49:50 UTC
+var beforeFixtureCaptionsExpire = time.Date(2025, 6, 29, 12, 0, 0, 0, time.UTC)
+
+func TestFetchMetadata_ReplayWithCaptions(t *testing.T) {
+	useFixtures(t, fixturesReplay, "fixtures", beforeFixtureCaptionsExpire)
+
+	info, source, err := FetchMetadata("gSrcc0oA6Q4")
+	if err != nil {
+		t.Fatalf("FetchMetadata: %v", err)
+	}
+	if source != "direct" {
+		t.Errorf("source = %q, want direct", source)
+	}
+	if info.Category != "News & Politics" || info.LengthSeconds != "581" {
+		t.Errorf("Unexpected metadata %+v", info)
+	}
+	if len(info.Captions) != 1 || info.Captions[0].LanguageCode != "pt" {
+		t.Errorf("Expected the Portuguese captions, got %+v", info.Captions)
+	}
+
+	if _, source, err := FetchMetadata("gSrcc0oA6Q4"); err != nil || source != "cache" {
+		t.Errorf("Expected the second fetch from the cache, got %q %v", source, err)
+	}
+}
+
+func TestFetchMetadata_ReplayWithoutCaptions(t *testing.T) {
+	useFixtures(t, fixturesReplay, "fixtures", beforeFixtureCaptionsExpire)
+
+	// DownSub has no captions either, the partial result of the direct method is kept
+	info, source, err := FetchMetadata("noCaptions1")
+	if err != nil {
+		t.Fatalf("FetchMetadata: %v", err)
+	}
+	if source != "direct" {
+		t.Errorf("source = %q, want direct", source)
+	}
+	if info.Category != "Music" || info.Captions == nil || len(info.Captions) != 0 {
+		t.Errorf("Unexpected metadata %+v", info)
+	}
+}
+
+func TestFetchMetadata_ReplayMissingFixture(t *testing.T) {
+	useFixtures(t, fixturesReplay, t.TempDir(), beforeFixtureCaptionsExpire)
+
+	if _, _, err := FetchMetadata("gSrcc0oA6Q4"); err == nil {
+		t.Errorf("Expected an error without fixtures")
+	}
+}
+
+func TestFixtures_RecordThenReplay(t *testing.T) {
+	dir := t.TempDir()
+	useFixtures(t, fixturesRecord, dir, beforeFixtureCaptionsExpire)
+
+	recorded := &YoutubeMetadataResponse{Title: "Recorded", Category: "Music", Captions: []Caption{}}
+	methods := []MetadataMethod{
+		{Name: "direct", Fetch: func(string) (*YoutubeMetadataResponse, error) { return recorded, nil }},
+		{Name: "downsub", Fetch: func(string) (*YoutubeMetadataResponse, error) { return nil, errors.New("no subtitles") }},
+	}
+	for _, method := range withFixtures(methods) {
+		method.Fetch("abcdefghijk")
+	}
+	for _, name := range []string{"abcdefghijk.direct.json", "abcdefghijk.downsub.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("Expected %s to be recorded: %v", name, err)
+		}
+	}
+
+	fixturesMode = fixturesReplay
+	info, used, err := fetchWithFallback(withFixtures(methods), "abcdefghijk")
+	if err != nil {
+		t.Fatalf("fetchWithFallback: %v", err)
+	}
+	if info.Title != "Recorded" || !slices.Equal(used, []string{"direct"}) {
+		t.Errorf("Unexpected replay %+v from %v", info, used)
+	}
+	if _, err := replayFixture("abcdefghijk", "downsub"); err == nil || err.Error() != "no subtitles" {
+		t.Errorf("Expected the recorded failure, got %v", err)
+	}
+	if _, err := replayFixture("../escape", "direct"); err == nil {
+		t.Errorf("Expected invalid video IDs to be refused")
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMetadataCacheTTL   = 6 * time.Hour
+	defaultCaptionURLCacheTTL = time.Hour
+	// signed caption URLs are dropped from the cache this long before YouTube expires them
+	captionURLExpiryMargin = 10 * time.Minute
+)
+
+var allowedLangs = []string{"pt", "en", "es", "it", "fr", "de", "ru", "ar", "ja", "zh", "ko"}
+
+// MetadataMethod is one way of getting the metadata of a video
+type MetadataMethod struct {
+	Name  string
+	Fetch func(videoID string) (*YoutubeMetadataResponse, error)
+}
+
+func fetchFromDownsub(videoID string) (*YoutubeMetadataResponse, error) {
+	downSubReturn, err := FetchMetadataFromDownsub(videoID)
+	if err != nil {
+		return nil, err
+	}
+	return convertDownSubResponseToFlatResponse(downSubReturn), nil
+}
+
+// metadataMethodsFromEnv puts the METADATA_METHOD ("direct" or "downsub") first,
+// the other one is the fallback
+func metadataMethodsFromEnv() []MetadataMethod {
+	direct := MetadataMethod{Name: "direct", Fetch: FetchDirectly}
+	downsub := MetadataMethod{Name: "downsub", Fetch: fetchFromDownsub}
+	if os.Getenv("METADATA_METHOD") == "downsub" {
+		return []MetadataMethod{downsub, direct}
+	}
+	return []MetadataMethod{direct, downsub}
+}
+
+// filterCaptions keeps the captions in the languages of the site
+func filterCaptions(captions []Caption) []Caption {
+	filtered := []Caption{}
+	for _, c := range captions {
+		if slices.Contains(allowedLangs, c.LanguageCode) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// missingFields lists what the API needs and the method did not return
+func missingFields(info *YoutubeMetadataResponse) []string {
+	var missing []string
+	if info.Category == "" {
+		missing = append(missing, "category")
+	}
+	if len(info.Captions) == 0 {
+		missing = append(missing, "captions")
+	}
+	return missing
+}
+
+// mergeMetadata fills the empty fields of info with the ones of fallback
+func mergeMetadata(info *YoutubeMetadataResponse, fallback *YoutubeMetadataResponse) {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&info.Title, fallback.Title)
+	fill(&info.ViewCount, fallback.ViewCount)
+	if info.LengthSeconds == "" || info.LengthSeconds == "0" {
+		info.LengthSeconds = fallback.LengthSeconds
+	}
+	fill(&info.ExternalChannelID, fallback.ExternalChannelID)
+	fill(&info.OwnerChannelName, fallback.OwnerChannelName)
+	fill(&info.ChannelUrl, fallback.ChannelUrl)
+	fill(&info.PublishDate, fallback.PublishDate)
+	fill(&info.Category, fallback.Category)
+	if len(info.Captions) == 0 {
+		info.Captions = fallback.Captions
+	}
+	fill(&info.LikeCount, fallback.LikeCount)
+	fill(&info.Description, fallback.Description)
+	if len(info.Keywords) == 0 {
+		info.Keywords = fallback.Keywords
+	}
+	if len(info.Thumbnails) == 0 {
+		info.Thumbnails = fallback.Thumbnails
+	}
+	if len(info.Chapters) == 0 {
+		info.Chapters = fallback.Chapters
+	}
+	fill(&info.DefaultAudioLanguage, fallback.DefaultAudioLanguage)
+}
+
+// fetchWithFallback tries the methods in order until one returns the category and
+// captions. Fields missing from a method are taken from the next ones, so a partial
+// result is still returned when no method is complete. The names of the methods used
+// are returned along with the metadata.
+func fetchWithFallback(methods []MetadataMethod, videoID string) (*YoutubeMetadataResponse, []string, error) {
+	var info *YoutubeMetadataResponse
+	var used []string
+	var errs []error
+
+	for _, method := range methods {
+		result, err := method.Fetch(videoID)
+		if err != nil {
+			log.Printf("⚠️ Metadata method %s failed for %s: %v", method.Name, videoID, err)
+			errs = append(errs, fmt.Errorf("%s: %w", method.Name, err))
+			continue
+		}
+		result.Captions = filterCaptions(result.Captions)
+
+		if info == nil {
+			info = result
+			used = append(used, method.Name)
+		} else if missing := missingFields(info); len(missing) > 0 {
+			mergeMetadata(info, result)
+			used = append(used, method.Name)
+		}
+
+		missing := missingFields(info)
+		if len(missing) == 0 {
+			return info, used, nil
+		}
+		log.Printf("⚠️ Metadata of %s is missing %v after %s", videoID, missing, method.Name)
+	}
+
+	if info == nil {
+		return nil, nil, errors.Join(errs...)
+	}
+	return info, used, nil
+}
+
+// cachedMetadata keeps the captions apart since their signed URLs expire long
+// before the rest of the metadata changes
+type cachedMetadata struct {
+	info            YoutubeMetadataResponse
+	expires         time.Time
+	captionsExpires time.Time
+}
+
+// MetadataCache remembers the metadata of the videos fetched recently
+type MetadataCache struct {
+	mu         sync.Mutex
+	entries    map[string]*cachedMetadata
+	ttl        time.Duration
+	captionTTL time.Duration
+	now        func() time.Time
+}
+
+func NewMetadataCache(ttl time.Duration, captionTTL time.Duration) *MetadataCache {
+	return &MetadataCache{entries: map[string]*cachedMetadata{}, ttl: ttl, captionTTL: captionTTL, now: time.Now}
+}
+
+// durationFromEnv reads a duration such as "6h" or "30m", "0" disables the cache
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Printf("⚠️ Invalid %s %q, using %s", name, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+var metadataCache = NewMetadataCache(
+	durationFromEnv("METADATA_CACHE_TTL", defaultMetadataCacheTTL),
+	durationFromEnv("CAPTION_URL_CACHE_TTL", defaultCaptionURLCacheTTL),
+)
+
+// captionURLExpiry is the earliest "expire" of the signed timedtext URLs, zero when
+// none of them is signed (e.g. the DownSub links)
+func captionURLExpiry(captions []Caption) time.Time {
+	var earliest time.Time
+	for _, c := range captions {
+		u, err := url.Parse(c.BaseUrl)
+		if err != nil {
+			continue
+		}
+		seconds, err := strconv.ParseInt(u.Query().Get("expire"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if expire := time.Unix(seconds, 0); earliest.IsZero() || expire.Before(earliest) {
+			earliest = expire
+		}
+	}
+	return earliest
+}
+
+// Get returns a copy of the metadata of the video, with its captions when their URLs
+// are still valid. complete is false when the captions expired and must be fetched again.
+func (c *MetadataCache) Get(videoID string) (info *YoutubeMetadataResponse, complete bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[videoID]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false, false
+	}
+	copied := entry.info
+	copied.Captions = []Caption{}
+	if c.now().Before(entry.captionsExpires) {
+		copied.Captions = slices.Clone(entry.info.Captions)
+		return &copied, true, true
+	}
+	return &copied, false, true
+}
+
+// Put stores the metadata of the video. Results missing fields only live as long as
+// the captions, so the methods are tried again soon.
+func (c *MetadataCache) Put(videoID string, info *YoutubeMetadataResponse) {
+	if c.ttl == 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	captionsExpires := now.Add(min(c.captionTTL, c.ttl))
+	if expire := captionURLExpiry(info.Captions); !expire.IsZero() && expire.Add(-captionURLExpiryMargin).Before(captionsExpires) {
+		captionsExpires = expire.Add(-captionURLExpiryMargin)
+	}
+	expires := now.Add(c.ttl)
+	if len(missingFields(info)) > 0 {
+		expires = captionsExpires
+	}
+
+	for id, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, id)
+		}
+	}
+	c.entries[videoID] = &cachedMetadata{
+		info:            *info,
+		expires:         expires,
+		captionsExpires: captionsExpires,
+	}
+	c.entries[videoID].info.Captions = slices.Clone(info.Captions)
+}
+
+// FetchMetadata answers from the cache, or fetches the video with the methods in order.
+// When the captions expired and no method answers, the cached metadata is returned
+// without captions. The source is "cache" or the methods used, e.g. "direct+downsub".
+func FetchMetadata(videoID string) (*YoutubeMetadataResponse, string, error) {
+	cached, complete, ok := metadataCache.Get(videoID)
+	if ok && complete {
+		return cached, "cache", nil
+	}
+
+	info, methods, err := fetchWithFallback(withFixtures(metadataMethodsFromEnv()), videoID)
+	if err != nil {
+		if ok {
+			log.Printf("⚠️ Serving the cached metadata of %s without captions: %v", videoID, err)
+			return cached, "cache", nil
+		}
+		return nil, "", err
+	}
+	if ok {
+		mergeMetadata(info, cached)
+	}
+	metadataCache.Put(videoID, info)
+	return info, strings.Join(methods, "+"), nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeMethod answers with info or err and counts its calls
+func fakeMethod(name string, info *YoutubeMetadataResponse, err error, calls *[]string) MetadataMethod {
+	return MetadataMethod{Name: name, Fetch: func(videoID string) (*YoutubeMetadataResponse, error) {
+		*calls = append(*calls, name)
+		if err != nil {
+			return nil, err
+		}
+		copied := *info
+		return &copied, nil
+	}}
+}
+
+var enCaption = Caption{BaseUrl: "https://downsub.example/en.srt", LanguageCode: "en"}
+
+func TestFetchWithFallback(t *testing.T) {
+	complete := &YoutubeMetadataResponse{Title: "Complete", Category: "Education", Captions: []Caption{enCaption}}
+	noCaptions := &YoutubeMetadataResponse{Title: "Direct title", ViewCount: "10", Captions: []Caption{}}
+	captionsOnly := &YoutubeMetadataResponse{Title: "DownSub title", Category: "Music", LikeCount: "7", Captions: []Caption{enCaption}}
+
+	tests := []struct {
+		name      string
+		methods   func(calls *[]string) []MetadataMethod
+		wantUsed  []string
+		wantCalls []string
+		wantTitle string
+		wantErr   bool
+	}{
+		{
+			name: "first complete method wins",
+			methods: func(calls *[]string) []MetadataMethod {
+				return []MetadataMethod{fakeMethod("direct", complete, nil, calls), fakeMethod("downsub", captionsOnly, nil, calls)}
+			},
+			wantUsed:  []string{"direct"},
+			wantCalls: []string{"direct"},
+			wantTitle: "Complete",
+		},
+		{
+			name: "failed method falls back to the next",
+			methods: func(calls *[]string) []MetadataMethod {
+				return []MetadataMethod{fakeMethod("direct", nil, errors.New("blocked"), calls), fakeMethod("downsub", captionsOnly, nil, calls)}
+			},
+			wantUsed:  []string{"downsub"},
+			wantCalls: []string{"direct", "downsub"},
+			wantTitle: "DownSub title",
+		},
+		{
+			name: "missing fields are merged from the next method",
+			methods: func(calls *[]string) []MetadataMethod {
+				return []MetadataMethod{fakeMethod("direct", noCaptions, nil, calls), fakeMethod("downsub", captionsOnly, nil, calls), fakeMethod("third", complete, nil, calls)}
+			},
+			wantUsed:  []string{"direct", "downsub"},
+			wantCalls: []string{"direct", "downsub"},
+			wantTitle: "Direct title",
+		},
+		{
+			name: "partial result when no method is complete",
+			methods: func(calls *[]string) []MetadataMethod {
+				return []MetadataMethod{fakeMethod("direct", noCaptions, nil, calls), fakeMethod("downsub", nil, errors.New("404"), calls)}
+			},
+			wantUsed:  []string{"direct"},
+			wantCalls: []string{"direct", "downsub"},
+			wantTitle: "Direct title",
+		},
+		{
+			name: "every method failed",
+			methods: func(calls *[]string) []MetadataMethod {
+				return []MetadataMethod{fakeMethod("direct", nil, errors.New("blocked"), calls), fakeMethod("downsub", nil, errors.New("404"), calls)}
+			},
+			wantCalls: []string{"direct", "downsub"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			info, used, err := fetchWithFallback(tt.methods(&calls), "vid")
+			if !slices.Equal(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "direct: blocked") || !strings.Contains(err.Error(), "downsub: 404") {
+					t.Errorf("Expected the errors of every method, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchWithFallback: %v", err)
+			}
+			if !slices.Equal(used, tt.wantUsed) || info.Title != tt.wantTitle {
+				t.Errorf("got %q from %v, want %q from %v", info.Title, used, tt.wantTitle, tt.wantUsed)
+			}
+		})
+	}
+}
+
+func TestFetchWithFallback_MergeKeepsTheFirstValues(t *testing.T) {
+	var calls []string
+	direct := &YoutubeMetadataResponse{Title: "Direct title", ViewCount: "10", Captions: []Caption{{BaseUrl: "https://x/ja", LanguageCode: "xx"}}}
+	downsub := &YoutubeMetadataResponse{Title: "DownSub title", ViewCount: "99", Category: "Music", LikeCount: "7", Captions: []Caption{enCaption}}
+
+	info, _, err := fetchWithFallback([]MetadataMethod{fakeMethod("direct", direct, nil, &calls), fakeMethod("downsub", downsub, nil, &calls)}, "vid")
+	if err != nil {
+		t.Fatalf("fetchWithFallback: %v", err)
+	}
+	// captions in languages the site does not serve are dropped before merging
+	if info.Title != "Direct title" || info.ViewCount != "10" || info.Category != "Music" || info.LikeCount != "7" {
+		t.Errorf("Unexpected merge %+v", info)
+	}
+	if len(info.Captions) != 1 || info.Captions[0] != enCaption {
+		t.Errorf("Expected the captions of the fallback, got %+v", info.Captions)
+	}
+}
+
+// fakeClock is the now of a MetadataCache, moved forward by the tests
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestCache(clock *fakeClock) *MetadataCache {
+	cache := NewMetadataCache(6*time.Hour, time.Hour)
+	cache.now = clock.now
+	return cache
+}
+
+func TestMetadataCache_CompleteEntry(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	cache := newTestCache(clock)
+	cache.Put("vid", &YoutubeMetadataResponse{Title: "Title", Category: "Music", Captions: []Caption{enCaption}})
+
+	clock.t = clock.t.Add(59 * time.Minute)
+	if info, complete, ok := cache.Get("vid"); !ok || !complete || len(info.Captions) != 1 {
+		t.Fatalf("Expected the complete entry before the caption TTL, got %+v %v %v", info, complete, ok)
+	}
+
+	// the captions expire with their TTL, the rest of the metadata stays
+	clock.t = clock.t.Add(2 * time.Minute)
+	info, complete, ok := cache.Get("vid")
+	if !ok || complete || len(info.Captions) != 0 || info.Title != "Title" {
+		t.Fatalf("Expected the metadata without captions, got %+v %v %v", info, complete, ok)
+	}
+
+	clock.t = clock.t.Add(5 * time.Hour)
+	if _, _, ok := cache.Get("vid"); ok {
+		t.Errorf("Expected the entry to expire after the metadata TTL")
+	}
+}
+
+func TestMetadataCache_MissingFieldsShortTTL(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	cache := newTestCache(clock)
+	// no category, so the methods are tried again once the captions expire
+	cache.Put("vid", &YoutubeMetadataResponse{Title: "Title", Captions: []Caption{enCaption}})
+
+	clock.t = clock.t.Add(59 * time.Minute)
+	if _, _, ok := cache.Get("vid"); !ok {
+		t.Fatalf("Expected the partial entry before the caption TTL")
+	}
+	clock.t = clock.t.Add(2 * time.Minute)
+	if _, _, ok := cache.Get("vid"); ok {
+		t.Errorf("Expected the partial entry to expire with its captions")
+	}
+}
+
+func TestMetadataCache_SignedURLExpireMargin(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}
+	cache := newTestCache(clock)
+	expire := clock.t.Add(30 * time.Minute)
+	signed := Caption{
+		BaseUrl:      fmt.Sprintf("https://www.youtube.com/api/timedtext?v=vid&expire=%d&lang=en", expire.Unix()),
+		LanguageCode: "en",
+	}
+	cache.Put("vid", &YoutubeMetadataResponse{Title: "Title", Category: "Music", Captions: []Caption{signed, enCaption}})
+
+	clock.t = expire.Add(-captionURLExpiryMargin - time.Second)
+	if _, complete, ok := cache.Get("vid"); !ok || !complete {
+		t.Fatalf("Expected the signed captions until the margin, got complete=%v ok=%v", complete, ok)
+	}
+	clock.t = expire.Add(-captionURLExpiryMargin)
+	if info, complete, ok := cache.Get("vid"); !ok || complete || len(info.Captions) != 0 {
+		t.Errorf("Expected the captions to be dropped %s before YouTube expires them, got %+v %v %v", captionURLExpiryMargin, info, complete, ok)
+	}
+}
+
+func TestMetadataCache_Disabled(t *testing.T) {
+	cache := NewMetadataCache(0, time.Hour)
+	cache.Put("vid", &YoutubeMetadataResponse{Title: "Title", Category: "Music", Captions: []Caption{enCaption}})
+	if _, _, ok := cache.Get("vid"); ok {
+		t.Errorf("Expected a zero TTL to disable the cache")
+	}
+}
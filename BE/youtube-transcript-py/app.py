@@ -1,7 +1,6 @@
 from flask import Flask, request, jsonify, Response
 from youtube_transcript_api import YouTubeTranscriptApi, TranscriptsDisabled, NoTranscriptFound
 from youtube_transcript_api.proxies import GenericProxyConfig
-import datetime
 import os
 
 app = Flask(__name__)
@@ -23,13 +22,20 @@ def create_ytt_instance():
 
 ytt_api = create_ytt_instance()
 
+def format_srt_timestamp(seconds):
+    millis = int(round(seconds * 1000))
+    hours, millis = divmod(millis, 3600000)
+    minutes, millis = divmod(millis, 60000)
+    secs, millis = divmod(millis, 1000)
+    return f"{hours:02}:{minutes:02}:{secs:02},{millis:03}"
+
 def convert_to_srt(transcript):
     srt = ""
     for i, entry in enumerate(transcript):
-        start = str(datetime.timedelta(seconds=int(entry['start'])))
-        end = str(datetime.timedelta(seconds=int(entry['start'] + entry['duration'])))
+        start = format_srt_timestamp(entry['start'])
+        end = format_srt_timestamp(entry['start'] + entry['duration'])
         text = entry['text']
-        srt += f"{i+1}\n{start},000 --> {end},000\n{text}\n\n"
+        srt += f"{i+1}\n{start} --> {end}\n{text}\n\n"
     return srt
 
 @app.route('/transcript', methods=['POST'])